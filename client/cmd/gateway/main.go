@@ -0,0 +1,204 @@
+// Command gateway
+// serves the registers of the replicas in -config to clients that don't speak gRPC, see
+// gateway.HTTPHandler for the HTTP/JSON API, gateway.RESPServer for the Redis protocol and
+// gateway.EtcdKVServer for the etcd v3 KV service. It runs up to -clients operations concurrently,
+// each client with a client ID of its own.
+//
+//	gateway -config config.txt -listen :8080 [-redis-listen :6379] [-etcd-listen :2379] [-clients 8] [-serve-tls-cert CERT -serve-tls-key KEY]
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"flag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"shared-registers/client/gateway"
+	"shared-registers/client/protocol"
+	"shared-registers/common"
+	"shared-registers/common/etcdpb"
+	"strings"
+	"time"
+)
+
+var (
+	configFile  = flag.String("config", "./config.txt", "file with one replica address per line")
+	listen      = flag.String("listen", ":8080", "address to serve the HTTP/JSON API on, empty to disable it")
+	redisListen = flag.String("redis-listen", "", "address to serve the Redis protocol on, e.g. :6379, empty to disable it")
+	etcdListen  = flag.String("etcd-listen", "", "address to serve the etcd v3 KV gRPC service on, e.g. :2379, empty to disable it")
+	numClients  = flag.Int("clients", 8, "number of operations to run concurrently, each on a client of its own")
+	maxBody     = flag.Int64("max-body", gateway.DefaultMaxBodySize, "largest request body or Redis argument accepted, in bytes")
+	serveCert   = flag.String("serve-tls-cert", "", "PEM certificate to serve every front-end over TLS with, reloaded when it changes")
+	serveKey    = flag.String("serve-tls-key", "", "PEM private key of -serve-tls-cert")
+	serveCA     = flag.String("serve-tls-client-ca", "", "CA bundle to require and verify the client certificates of the front-ends with")
+
+	useHLC    = flag.Bool("hlc", false, "timestamp writes with a hybrid logical clock")
+	maxOffset = flag.Duration("hlc-max-offset", 0, "reject timestamps this far ahead of the local clock, 0 to disable")
+	idLease   = flag.Duration("client-lease", 10*time.Second, "lease of the client ID registrations on the replicas, 0 to skip registering")
+	wireGzip  = flag.Bool("wire-compression", false, "gzip the requests to the replicas and their responses")
+	gzipAbove = flag.Int("compress-threshold", 0, "store values of at least this many bytes gzip-compressed, 0 to disable")
+	useTLS    = flag.Bool("tls", false, "connect to the replicas over TLS, implied by -tls-ca and -tls-cert")
+	tlsCA     = flag.String("tls-ca", "", "CA bundle to verify the replicas with instead of the system roots")
+	tlsCert   = flag.String("tls-cert", "", "PEM client certificate for replicas requiring mutual TLS, reloaded when it changes")
+	tlsKey    = flag.String("tls-key", "", "PEM private key of -tls-cert")
+	tokenFile = flag.String("token-file", "", "file with the bearer token to authenticate to replicas with an ACL, needs TLS")
+	valueKeys = flag.String("value-key-file", "", "key file to encrypt values end to end with, see common.ParseKeyFile")
+	idPrefix  = flag.String("client-id-prefix", "", "start the client IDs with this instead of the hostname, e.g. to match the client IDs an ACL allows")
+)
+
+// readAddrs reads the replica addresses of the config file, one per line
+func readAddrs(name string) ([]string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	addrs := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if addr := strings.TrimSpace(scanner.Text()); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs, scanner.Err()
+}
+
+// newClient connects a client with the flags' settings
+func newClient(addrs []string, options protocol.ConnOptions, valueKeyFile []byte) (*protocol.SharedRegisterClient, error) {
+	hostname, _ := os.Hostname()
+	clientID := "gateway-" + hostname + "-" + protocol.NewClientID()
+	if *idPrefix != "" {
+		clientID = *idPrefix + protocol.NewClientID()
+	}
+	client, err := protocol.CreateSharedRegisterClientWithOptions(clientID, addrs, options)
+	if err != nil {
+		return nil, err
+	}
+	if *useHLC {
+		client.EnableHybridClock(*maxOffset)
+	}
+	if *wireGzip {
+		client.EnableWireCompression()
+	}
+	client.CompressionThreshold = *gzipAbove
+	if valueKeyFile != nil {
+		primary, keys, err := common.ParseKeyFile(valueKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := client.EnableEncryption(primary, keys); err != nil {
+			return nil, err
+		}
+	}
+	if *idLease > 0 {
+		if err := client.RegisterClientID(*idLease); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+func main() {
+	flag.Parse()
+	addrs, err := readAddrs(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var options protocol.ConnOptions
+	if *useTLS || *tlsCA != "" || *tlsCert != "" {
+		options.TLS, err = common.ClientTLSConfig(common.TLSFiles{CertFile: *tlsCert, KeyFile: *tlsKey, CAFile: *tlsCA})
+		if err != nil {
+			log.Fatal("ClientTLSConfig: ", err)
+		}
+	}
+	if *tokenFile != "" {
+		token, err := os.ReadFile(*tokenFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options.Token = strings.TrimSpace(string(token))
+	}
+	var valueKeyFile []byte
+	if *valueKeys != "" {
+		if valueKeyFile, err = os.ReadFile(*valueKeys); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	clients := make([]gateway.Registers, 0, *numClients)
+	for i := 0; i < *numClients; i++ {
+		client, err := newClient(addrs, options, valueKeyFile)
+		if err != nil {
+			log.Fatal("CreateSharedRegisterClient: ", err)
+		}
+		clients = append(clients, client)
+	}
+	pool, err := gateway.NewPool(clients...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *listen == "" && *redisListen == "" && *etcdListen == "" {
+		log.Fatal("-listen, -redis-listen or -etcd-listen is required")
+	}
+	errs := make(chan error)
+	if *listen != "" {
+		handler := gateway.NewHTTPHandler(pool)
+		handler.MaxBodySize = *maxBody
+		lis := listenOn(*listen, "HTTP", len(addrs))
+		go func() {
+			server := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+			errs <- server.Serve(lis)
+		}()
+	}
+	if *redisListen != "" {
+		server := gateway.NewRESPServer(pool)
+		server.MaxBulkSize = *maxBody
+		lis := listenOn(*redisListen, "Redis", len(addrs))
+		go func() { errs <- server.Serve(lis) }()
+	}
+	if *etcdListen != "" {
+		lis, err := net.Listen("tcp", *etcdListen)
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+		var opts []grpc.ServerOption
+		if config := serveTLSConfig(); config != nil {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(config)))
+		}
+		server := grpc.NewServer(opts...)
+		etcdpb.RegisterKVServer(server, gateway.NewEtcdKVServer(pool))
+		log.Printf("serving the registers of %d replicas over etcd v3 on %v", len(addrs), lis.Addr())
+		go func() { errs <- server.Serve(lis) }()
+	}
+	log.Fatal(<-errs)
+}
+
+// serveTLSConfig returns the TLS configuration of the front-ends, nil unless -serve-tls-cert is set
+func serveTLSConfig() *tls.Config {
+	if *serveCert == "" {
+		return nil
+	}
+	config, err := common.ServerTLSConfig(common.TLSFiles{CertFile: *serveCert, KeyFile: *serveKey, CAFile: *serveCA})
+	if err != nil {
+		log.Fatal("ServerTLSConfig: ", err)
+	}
+	return config
+}
+
+// listenOn listens on addr, over TLS if -serve-tls-cert is set
+func listenOn(addr, frontEnd string, replicas int) net.Listener {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+	if config := serveTLSConfig(); config != nil {
+		lis = tls.NewListener(lis, config)
+	}
+	log.Printf("serving the registers of %d replicas over %s on %v", replicas, frontEnd, lis.Addr())
+	return lis
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc/status"
+	"os"
+	"shared-registers/client/protocol"
+	"shared-registers/client/util"
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// maxValueWidth is how many characters of a value inspect prints
+const maxValueWidth = 48
+
+// replicaValue is the <value, ts> a replica stores for a key, err if the replica didn't tell
+type replicaValue struct {
+	value *proto.StoredValue
+	err   error
+}
+
+// dumpKey asks every replica for the <value, ts> it stores for the key
+func dumpKey(rs replicaSet, key string) []replicaValue {
+	values := make([]replicaValue, len(rs))
+	rs.each(func(ctx context.Context, i int, r *replica) {
+		rsp, err := r.admin.DumpValue(ctx, &proto.DumpValueReq{Key: key})
+		values[i] = replicaValue{value: rsp.GetValue(), err: err}
+	})
+	return values
+}
+
+// latestValue returns the value with the largest timestamp, nil if no replica stores the key
+func latestValue(values []replicaValue) *proto.StoredValue {
+	var latest *proto.StoredValue
+	for _, v := range values {
+		if v.value != nil && (latest == nil || common.CompareTimeStamps(v.value.GetTs(), latest.GetTs()) > 0) {
+			latest = v.value
+		}
+	}
+	return latest
+}
+
+// compare
+// return the state of the value of a replica next to the latest value: latest, behind, missing,
+// conflict if it differs from the latest value with the same timestamp, absent if no replica stores
+// the key, or why the replica didn't tell
+func compare(v replicaValue, latest *proto.StoredValue) string {
+	switch {
+	case v.err != nil:
+		return describeError(v.err)
+	case v.value == nil && latest == nil:
+		return "absent"
+	case v.value == nil:
+		return "missing"
+	case common.CompareTimeStamps(v.value.GetTs(), latest.GetTs()) < 0:
+		return "behind"
+	case !common.SameWrite(v.value, latest):
+		return "conflict"
+	}
+	return "latest"
+}
+
+// describeValue prints the value of a replica, or what it is if it can't be printed
+func describeValue(v *proto.StoredValue, now time.Time) string {
+	if v == nil {
+		return "-"
+	}
+	var desc string
+	switch {
+	case v.GetDeleted():
+		desc = "(deleted)"
+	case v.GetManifest() != nil:
+		desc = fmt.Sprintf("(%d bytes in %d chunks)", v.GetManifest().GetSize(), len(v.GetManifest().GetChunks()))
+	case v.GetKeyID() != "":
+		desc = fmt.Sprintf("(%d bytes encrypted with key %s)", len(v.GetVal()), v.GetKeyID())
+	case v.GetCompression() != proto.Compression_NONE:
+		desc = fmt.Sprintf("(%d bytes compressed with %s)", len(v.GetVal()), strings.ToLower(v.GetCompression().String()))
+	default:
+		desc = util.FormatValue(v.GetVal())
+		if runes := []rune(desc); len(runes) > maxValueWidth {
+			desc = string(runes[:maxValueWidth]) + "..."
+		}
+	}
+	if v.GetContentType() != "" {
+		desc += " " + v.GetContentType()
+	}
+	if v.GetExpiresAt() != 0 {
+		if common.Expired(v, now) {
+			desc += " expired"
+		} else {
+			desc += " expires " + time.Unix(0, int64(v.GetExpiresAt())).Format(time.RFC3339)
+		}
+	}
+	return desc
+}
+
+// inspect
+// print the <value, ts> every replica stores for the key side by side, marking the replicas that
+// diverge from the latest value with *
+func inspect(rs replicaSet, key string) error {
+	values := dumpKey(rs, key)
+	latest := latestValue(values)
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tREPLICA\tSTATE\tTIMESTAMP\tWRITTEN\tVALUE")
+	diverged := 0
+	for i, v := range values {
+		state, mark := compare(v, latest), ""
+		if state != "latest" && state != "absent" {
+			mark = "*"
+			diverged++
+		}
+		detail := describeValue(v.value, now)
+		if v.err != nil {
+			detail = status.Convert(v.err).Message()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", mark, rs[i].addr, state, formatTimeStamp(v.value.GetTs()),
+			formatWallTime(v.value.GetTs()), detail)
+	}
+	w.Flush()
+	switch {
+	case diverged > 0:
+		fmt.Printf("%d of %d replicas diverge from the latest value of %s\n", diverged, len(rs), key)
+		return errDiverged
+	case latest == nil:
+		fmt.Printf("no replica stores %s\n", key)
+	default:
+		fmt.Printf("all %d replicas store the latest value of %s\n", len(rs), key)
+	}
+	return nil
+}
+
+// repairKey
+// write the latest value back to the replicas whose value is behind or missing with the admin
+// RepairValue call, like a read writes it back to a quorum but without needing to write the key
+// under the client ID of the value. Returns what became of every replica, how many were repaired and
+// how many still diverge.
+func repairKey(rs replicaSet, key string, values []replicaValue, latest *proto.StoredValue) ([]string, int, int) {
+	outcomes := make([]string, len(rs))
+	var mu sync.Mutex
+	repaired, failed := 0, 0
+	rs.each(func(ctx context.Context, i int, r *replica) {
+		state := compare(values[i], latest)
+		outcome, ok := state, true
+		switch state {
+		case "latest", "absent":
+		case "behind", "missing":
+			rsp, err := r.admin.RepairValue(ctx, &proto.RepairValueReq{Key: key, Value: latest})
+			switch {
+			case err != nil:
+				outcome, ok = state+", repair failed: "+status.Convert(err).Message(), false
+			case rsp.GetStored():
+				outcome = state + ", repaired"
+			default:
+				outcome = state + ", caught up meanwhile"
+			}
+		default:
+			outcome, ok = state+", left alone", false
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		outcomes[i] = outcome
+		if !ok {
+			failed++
+		} else if strings.HasSuffix(outcome, ", repaired") {
+			repaired++
+		}
+	})
+	return outcomes, repaired, failed
+}
+
+// repair
+// copy the latest value of the key to every replica that is behind or misses it. The chunks of a
+// large value are repaired before its manifest, the order a writer writes them in. Conflicting and
+// unreachable replicas are left alone.
+func repair(rs replicaSet, key string) error {
+	values := dumpKey(rs, key)
+	latest := latestValue(values)
+	if latest == nil {
+		for _, v := range values {
+			if v.err != nil {
+				fmt.Printf("no reachable replica stores %s\n", key)
+				return errDiverged
+			}
+		}
+		fmt.Printf("no replica stores %s\n", key)
+		return nil
+	}
+
+	failed := 0
+	if manifest := latest.GetManifest(); manifest != nil {
+		chunks, repaired := protocol.ChunkKeys(key, manifest), 0
+		for _, chunk := range chunks {
+			chunkValues := dumpKey(rs, chunk)
+			chunkLatest := latestValue(chunkValues)
+			if chunkLatest == nil {
+				fmt.Printf("chunk %s is missing on every replica\n", util.FormatValue([]byte(chunk)))
+				failed++
+				continue
+			}
+			outcomes, chunkRepaired, chunkFailed := repairKey(rs, chunk, chunkValues, chunkLatest)
+			for i, outcome := range outcomes {
+				if strings.Contains(outcome, ", ") && !strings.HasSuffix(outcome, ", repaired") {
+					fmt.Printf("chunk %s on %s: %s\n", util.FormatValue([]byte(chunk)), rs[i].addr, outcome)
+				}
+			}
+			repaired += chunkRepaired
+			failed += chunkFailed
+		}
+		fmt.Printf("repaired %d copies of the %d chunks of %s\n", repaired, len(chunks), key)
+	}
+
+	outcomes, _, keyFailed := repairKey(rs, key, values, latest)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPLICA\tOUTCOME")
+	for i, outcome := range outcomes {
+		fmt.Fprintf(w, "%s\t%s\n", rs[i].addr, outcome)
+	}
+	w.Flush()
+	if failed += keyFailed; failed > 0 {
+		return errDiverged
+	}
+	fmt.Printf("every replica stores the latest value of %s, %s\n", key, formatTimeStamp(latest.GetTs()))
+	return nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"shared-registers/client/protocol"
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"sync"
+	"testing"
+)
+
+// fakeAdmin is the Admin service of a replica storing values, calls the tests don't use panic
+type fakeAdmin struct {
+	proto.AdminClient
+	lock     sync.Mutex
+	values   map[string]*proto.StoredValue
+	err      error // returned by every call if set
+	repaired int
+}
+
+func (f *fakeAdmin) DumpValue(ctx context.Context, in *proto.DumpValueReq, opts ...grpc.CallOption) (*proto.DumpValueRsp, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &proto.DumpValueRsp{Value: f.values[in.GetKey()]}, nil
+}
+
+func (f *fakeAdmin) RepairValue(ctx context.Context, in *proto.RepairValueReq, opts ...grpc.CallOption) (*proto.RepairValueRsp, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	if current := f.values[in.GetKey()]; current != nil && common.CompareTimeStamps(current.GetTs(), in.GetValue().GetTs()) >= 0 {
+		return &proto.RepairValueRsp{}, nil
+	}
+	f.values[in.GetKey()] = in.GetValue()
+	f.repaired++
+	return &proto.RepairValueRsp{Stored: true}, nil
+}
+
+func fakeReplicas(admins ...*fakeAdmin) replicaSet {
+	rs := make(replicaSet, len(admins))
+	for i, a := range admins {
+		if a.values == nil {
+			a.values = map[string]*proto.StoredValue{}
+		}
+		rs[i] = &replica{addr: "replica-" + string(rune('a'+i)), admin: a}
+	}
+	return rs
+}
+
+func value(val string, n uint64) *proto.StoredValue {
+	return &proto.StoredValue{Val: []byte(val), Ts: &proto.TimeStamp{RequestNumber: n, ClientID: "c"}}
+}
+
+func TestCompare(t *testing.T) {
+	latest := value("new", 3)
+	for _, tc := range []struct {
+		name   string
+		v      replicaValue
+		latest *proto.StoredValue
+		want   string
+	}{
+		{"latest", replicaValue{value: value("new", 3)}, latest, "latest"},
+		{"behind", replicaValue{value: value("old", 2)}, latest, "behind"},
+		{"missing", replicaValue{}, latest, "missing"},
+		{"absent", replicaValue{}, nil, "absent"},
+		{"conflict", replicaValue{value: value("other", 3)}, latest, "conflict"},
+		{"unreachable", replicaValue{err: status.Error(codes.Unavailable, "down")}, latest, "unreachable"},
+		{"error", replicaValue{err: errors.New("broken")}, latest, "error"},
+	} {
+		if got := compare(tc.v, tc.latest); got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestRepair(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		admins   []*fakeAdmin
+		wantErr  bool
+		repaired int // values written back
+	}{
+		{"in sync", []*fakeAdmin{
+			{values: map[string]*proto.StoredValue{"k": value("new", 3)}},
+			{values: map[string]*proto.StoredValue{"k": value("new", 3)}},
+		}, false, 0},
+		{"behind and missing", []*fakeAdmin{
+			{values: map[string]*proto.StoredValue{"k": value("new", 3)}},
+			{values: map[string]*proto.StoredValue{"k": value("old", 2)}},
+			{},
+		}, false, 2},
+		{"conflict left alone", []*fakeAdmin{
+			{values: map[string]*proto.StoredValue{"k": value("new", 3)}},
+			{values: map[string]*proto.StoredValue{"k": value("other", 3)}},
+			{},
+		}, true, 1},
+		{"unreachable replica", []*fakeAdmin{
+			{values: map[string]*proto.StoredValue{"k": value("new", 3)}},
+			{err: status.Error(codes.Unavailable, "down")},
+		}, true, 0},
+		{"absent everywhere", []*fakeAdmin{{}, {}}, false, 0},
+	} {
+		err := repair(fakeReplicas(tc.admins...), "k")
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: expected an error %v, got %v", tc.name, tc.wantErr, err)
+		}
+		repaired := 0
+		for _, a := range tc.admins {
+			repaired += a.repaired
+		}
+		if repaired != tc.repaired {
+			t.Errorf("%s: expected %d repairs, got %d", tc.name, tc.repaired, repaired)
+		}
+	}
+}
+
+func TestRepairChunks(t *testing.T) {
+	manifest := &proto.StoredValue{Ts: &proto.TimeStamp{RequestNumber: 3, ClientID: "c"},
+		Manifest: &proto.ChunkManifest{WriteID: "w", Size: 2, Chunks: [][]byte{[]byte("h1"), []byte("h2")}}}
+	chunks := map[string]*proto.StoredValue{}
+	for _, key := range protocol.ChunkKeys("big", manifest.GetManifest()) {
+		chunks[key] = value("c", 3)
+	}
+	full := map[string]*proto.StoredValue{"big": manifest}
+	for key, v := range chunks {
+		full[key] = v
+	}
+	behind := &fakeAdmin{}
+	if err := repair(fakeReplicas(&fakeAdmin{values: full}, behind), "big"); err != nil {
+		t.Fatal(err)
+	}
+	if len(behind.values) != len(full) {
+		t.Errorf("expected the chunks and the manifest to be repaired, got %d of %d keys", len(behind.values), len(full))
+	}
+}
@@ -0,0 +1,350 @@
+// Command srctl
+// operates the registers and the replicas holding them from the command line:
+//
+//	srctl [flags] get KEY                      print the value of the key as is
+//	srctl [flags] put [-content-type T] [-ttl D] KEY [VALUE]   write VALUE, or stdin if it is missing
+//	srctl [flags] delete KEY...
+//	srctl [flags] scan [-limit N] [-values] [PREFIX]           list the keys under the prefix
+//	srctl [flags] inspect KEY                  show the <value, ts> of every replica side by side
+//	srctl [flags] cluster status               reachability, latency and key counts of every replica
+//	srctl [flags] repair KEY                   copy the latest value of the key to the replicas missing it
+//
+// The replica addresses are taken from -replicas, otherwise from $SRCTL_REPLICAS (both comma
+// separated), otherwise from the -config file ($SRCTL_CONFIG, ./config.txt by default) with one
+// address per line. get, put, delete and scan run the quorum protocol like any client, with the
+// quorums of -read-quorum and -write-quorum (majorities by default) and on erasure-coded registers
+// with -coded-shards; cluster status checks the serving replicas against the same quorums. inspect,
+// cluster status and repair call every replica on its own and need the admin permission on
+// replicas with an ACL. srctl exits with 1 if a command fails or finds diverged or unreachable
+// replicas, and with 2 on usage errors.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"shared-registers/client/protocol"
+	"shared-registers/client/util"
+	"shared-registers/common"
+	"strings"
+	"time"
+)
+
+var (
+	replicas   = flag.String("replicas", "", "comma-separated replica addresses, $SRCTL_REPLICAS by default")
+	configFile = flag.String("config", "", "file with one replica address per line, used without -replicas and $SRCTL_REPLICAS, $SRCTL_CONFIG or ./config.txt by default")
+	timeout    = flag.Duration("timeout", 2*time.Second, "timeout of the calls to a single replica")
+
+	readQuorum  = flag.Int("read-quorum", 0, "replicas forming a read quorum, with -write-quorum, majorities if both are 0")
+	writeQuorum = flag.Int("write-quorum", 0, "replicas forming a write quorum, with -read-quorum, majorities if both are 0")
+	codedShards = flag.Int("coded-shards", 0, "data shards k of erasure-coded registers, 0 if the registers are replicated")
+
+	useHLC    = flag.Bool("hlc", false, "timestamp writes with a hybrid logical clock")
+	idLease   = flag.Duration("client-lease", 10*time.Second, "lease of the client ID registration of put and delete, 0 to skip registering")
+	useTLS    = flag.Bool("tls", false, "connect to the replicas over TLS, implied by -tls-ca and -tls-cert")
+	tlsCA     = flag.String("tls-ca", "", "CA bundle to verify the replicas with instead of the system roots")
+	tlsCert   = flag.String("tls-cert", "", "PEM client certificate for replicas requiring mutual TLS")
+	tlsKey    = flag.String("tls-key", "", "PEM private key of -tls-cert")
+	tokenFile = flag.String("token-file", "", "file with the bearer token to authenticate to replicas with an ACL, needs TLS")
+	valueKeys = flag.String("value-key-file", "", "key file the values are encrypted end to end with, see common.ParseKeyFile")
+	idPrefix  = flag.String("client-id-prefix", "", "start the client ID with this instead of srctl and the hostname, e.g. to match the client IDs an ACL allows")
+)
+
+// errUsage makes srctl print the usage and exit with 2
+var errUsage = errors.New("usage")
+
+// errDiverged makes srctl exit with 1 without printing an error, the command printed what is wrong
+var errDiverged = errors.New("diverged")
+
+// replicaAddrs returns the replica addresses of -replicas, $SRCTL_REPLICAS or the config file
+func replicaAddrs() ([]string, error) {
+	list := *replicas
+	if list == "" {
+		list = os.Getenv("SRCTL_REPLICAS")
+	}
+	if list != "" {
+		addrs := make([]string, 0)
+		for _, addr := range strings.Split(list, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				addrs = append(addrs, addr)
+			}
+		}
+		return addrs, nil
+	}
+	name := *configFile
+	if name == "" {
+		name = os.Getenv("SRCTL_CONFIG")
+	}
+	if name == "" {
+		name = "./config.txt"
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	addrs := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if addr := strings.TrimSpace(scanner.Text()); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New(name + " lists no replicas")
+	}
+	return addrs, nil
+}
+
+// connOptions returns the TLS and token settings of the flags
+func connOptions() (protocol.ConnOptions, error) {
+	var options protocol.ConnOptions
+	var err error
+	if *useTLS || *tlsCA != "" || *tlsCert != "" {
+		options.TLS, err = common.ClientTLSConfig(common.TLSFiles{CertFile: *tlsCert, KeyFile: *tlsKey, CAFile: *tlsCA})
+		if err != nil {
+			return options, err
+		}
+	}
+	if *tokenFile != "" {
+		token, err := os.ReadFile(*tokenFile)
+		if err != nil {
+			return options, err
+		}
+		options.Token = strings.TrimSpace(string(token))
+	}
+	return options, nil
+}
+
+// quorumSystem returns the quorums of -read-quorum and -write-quorum over n replicas, majorities if neither is set
+func quorumSystem(n int) (protocol.QuorumSystem, error) {
+	if *readQuorum == 0 && *writeQuorum == 0 {
+		return protocol.MajorityQuorum{N: n}, nil
+	}
+	q := protocol.FlexibleQuorum{ReadSize: *readQuorum, WriteSize: *writeQuorum}
+	if err := protocol.ValidateQuorumSystem(q, n); err != nil {
+		return nil, fmt.Errorf("-read-quorum %d and -write-quorum %d: %w", *readQuorum, *writeQuorum, err)
+	}
+	return q, nil
+}
+
+// newClient connects a client with the flags' settings, registering its client ID if it writes
+func newClient(addrs []string, options protocol.ConnOptions, writes bool) (*protocol.SharedRegisterClient, error) {
+	hostname, _ := os.Hostname()
+	clientID := "srctl-" + hostname + "-" + protocol.NewClientID()
+	if *idPrefix != "" {
+		clientID = *idPrefix + protocol.NewClientID()
+	}
+	client, err := protocol.CreateSharedRegisterClientWithOptions(clientID, addrs, options)
+	if err != nil {
+		return nil, err
+	}
+	if *useHLC {
+		client.EnableHybridClock(0)
+	}
+	if *readQuorum != 0 || *writeQuorum != 0 {
+		q, err := quorumSystem(len(addrs))
+		if err != nil {
+			return nil, err
+		}
+		if err := client.SetQuorumSystem(q); err != nil {
+			return nil, err
+		}
+	}
+	if *codedShards > 0 {
+		if err := client.EnableErasureCoding(*codedShards); err != nil {
+			return nil, err
+		}
+	}
+	if *valueKeys != "" {
+		data, err := os.ReadFile(*valueKeys)
+		if err != nil {
+			return nil, err
+		}
+		primary, keys, err := common.ParseKeyFile(data)
+		if err != nil {
+			return nil, err
+		}
+		if err := client.EnableEncryption(primary, keys); err != nil {
+			return nil, err
+		}
+	}
+	if writes && *idLease > 0 {
+		if err := client.RegisterClientID(*idLease); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("srctl: ")
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintln(out, "usage: srctl [flags] get KEY | put [-content-type T] [-ttl D] KEY [VALUE] | delete KEY... |")
+		fmt.Fprintln(out, "       scan [-limit N] [-values] [PREFIX] | inspect KEY | cluster status | repair KEY")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	err := run(flag.Args())
+	switch {
+	case errors.Is(err, errUsage):
+		flag.Usage()
+		os.Exit(2)
+	case errors.Is(err, errDiverged):
+		os.Exit(1)
+	case err != nil:
+		log.Fatal(err)
+	}
+}
+
+// run runs the subcommand of args
+func run(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	addrs, err := replicaAddrs()
+	if err != nil {
+		return err
+	}
+	options, err := connOptions()
+	if err != nil {
+		return err
+	}
+	command, args := args[0], args[1:]
+	switch command {
+	case "get", "put", "delete", "scan":
+		client, err := newClient(addrs, options, command == "put" || command == "delete")
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		switch command {
+		case "get":
+			return get(client, args)
+		case "put":
+			return put(client, args)
+		case "delete":
+			return del(client, args)
+		default:
+			return scan(client, args)
+		}
+	case "inspect", "repair":
+		if len(args) != 1 {
+			return errUsage
+		}
+		conns, err := dialReplicas(addrs, options)
+		if err != nil {
+			return err
+		}
+		defer conns.Close()
+		if command == "inspect" {
+			return inspect(conns, args[0])
+		}
+		return repair(conns, args[0])
+	case "cluster":
+		if len(args) != 1 || args[0] != "status" {
+			return errUsage
+		}
+		conns, err := dialReplicas(addrs, options)
+		if err != nil {
+			return err
+		}
+		defer conns.Close()
+		return clusterStatus(conns)
+	}
+	return errUsage
+}
+
+// get writes the value of the key to stdout as is, so it can be piped into a file or back into put
+func get(client *protocol.SharedRegisterClient, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	value, err := client.ReadValue(args[0])
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(value.GetVal())
+	return err
+}
+
+func put(client *protocol.SharedRegisterClient, args []string) error {
+	flags := flag.NewFlagSet("put", flag.ContinueOnError)
+	contentType := flags.String("content-type", "", "MIME type of the value")
+	ttl := flags.Duration("ttl", 0, "delete the value after this long, 0 to keep it")
+	if err := flags.Parse(args); err != nil || flags.NArg() < 1 || flags.NArg() > 2 {
+		return errUsage
+	}
+	var value []byte
+	if flags.NArg() == 2 {
+		value = []byte(flags.Arg(1))
+	} else {
+		var err error
+		if value, err = io.ReadAll(os.Stdin); err != nil {
+			return err
+		}
+	}
+	ts, err := client.WriteBytesWithTimeStamp(flags.Arg(0), value, *contentType, *ttl)
+	if err != nil {
+		return err
+	}
+	fmt.Println("written at", formatTimeStamp(ts))
+	return nil
+}
+
+func del(client *protocol.SharedRegisterClient, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	for _, key := range args {
+		if err := client.Delete(key); err != nil {
+			return fmt.Errorf("deleting %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// scan prints the keys under the prefix one per line, with -values followed by a tab and the value
+// as a literal of the interactive client
+func scan(client *protocol.SharedRegisterClient, args []string) error {
+	flags := flag.NewFlagSet("scan", flag.ContinueOnError)
+	limit := flags.Int("limit", 0, "print at most this many keys, 0 for all")
+	values := flags.Bool("values", false, "print the values too")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 1 {
+		return errUsage
+	}
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	listed, pageToken := 0, ""
+	for {
+		entries, next, err := client.ScanPrefix(flags.Arg(0), 0, pageToken)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if *limit > 0 && listed == *limit {
+				return nil
+			}
+			if *values {
+				fmt.Fprintf(out, "%s\t%s\n", e.GetKey(), util.FormatValue(e.GetValue().GetVal()))
+			} else {
+				fmt.Fprintln(out, e.GetKey())
+			}
+			listed++
+		}
+		if next == "" {
+			return nil
+		}
+		pageToken = next
+	}
+}
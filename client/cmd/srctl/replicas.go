@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"shared-registers/client/protocol"
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"sync"
+	"time"
+)
+
+// replica is a connection to a single replica, for the commands looking at every replica on its own
+type replica struct {
+	addr  string
+	conn  *grpc.ClientConn
+	admin proto.AdminClient
+}
+
+type replicaSet []*replica
+
+// dialReplicas connects to every replica, the connections are established on their first call
+func dialReplicas(addrs []string, options protocol.ConnOptions) (replicaSet, error) {
+	rs := make(replicaSet, 0, len(addrs))
+	for _, addr := range addrs {
+		conn, err := protocol.Dial(addr, options)
+		if err != nil {
+			rs.Close()
+			return nil, err
+		}
+		rs = append(rs, &replica{addr: addr, conn: conn, admin: proto.NewAdminClient(conn)})
+	}
+	return rs, nil
+}
+
+func (rs replicaSet) Close() {
+	for _, r := range rs {
+		r.conn.Close()
+	}
+}
+
+// each calls f for every replica concurrently, with a context that times out after -timeout, and
+// waits for all the calls
+func (rs replicaSet) each(f func(ctx context.Context, i int, r *replica)) {
+	var wg sync.WaitGroup
+	for i, r := range rs {
+		wg.Add(1)
+		go func(i int, r *replica) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+			defer cancel()
+			f(ctx, i, r)
+		}(i, r)
+	}
+	wg.Wait()
+}
+
+// unreachable reports whether the call failed because the replica didn't answer in time
+func unreachable(err error) bool {
+	code := status.Code(err)
+	return code == codes.Unavailable || code == codes.DeadlineExceeded
+}
+
+// describeError is the state of a replica whose call failed
+func describeError(err error) string {
+	if unreachable(err) {
+		return "unreachable"
+	}
+	return "error"
+}
+
+// formatTimeStamp prints <requestNumber, clientID>, with the wall time and logical counter of HLC timestamps
+func formatTimeStamp(ts *proto.TimeStamp) string {
+	if ts == nil {
+		return "-"
+	}
+	if ts.GetWallTime() == 0 {
+		return fmt.Sprintf("<%d, %s>", ts.GetRequestNumber(), ts.GetClientID())
+	}
+	return fmt.Sprintf("<%d, %d.%d, %s>", ts.GetRequestNumber(), ts.GetWallTime(), ts.GetLogical(), ts.GetClientID())
+}
+
+// formatWallTime prints the write time of HLC timestamps
+func formatWallTime(ts *proto.TimeStamp) string {
+	if wallTime := common.WallTime(ts); !wallTime.IsZero() {
+		return wallTime.Format(time.RFC3339Nano)
+	}
+	return "-"
+}
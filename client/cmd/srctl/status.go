@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc/status"
+	"os"
+	"shared-registers/client/protocol"
+	"shared-registers/common/proto"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// replicaStatus is what a replica tells about itself, err if it didn't
+type replicaStatus struct {
+	info    *proto.GetInfoRsp
+	count   *proto.CountKeysRsp
+	latency time.Duration // of the GetInfo call
+	err     error
+}
+
+// clusterStatus
+// print the reachability, latency, key counts, mode, uptime and revision of every replica, and
+// whether the replicas that serve both reads and writes form the quorums of the flags
+func clusterStatus(rs replicaSet) error {
+	q, err := quorumSystem(len(rs))
+	if err != nil {
+		return err
+	}
+	statuses := make([]replicaStatus, len(rs))
+	rs.each(func(ctx context.Context, i int, r *replica) {
+		start := time.Now()
+		info, err := r.admin.GetInfo(ctx, &proto.GetInfoReq{})
+		if err != nil {
+			statuses[i] = replicaStatus{err: err}
+			return
+		}
+		latency := time.Since(start)
+		count, err := r.admin.CountKeys(ctx, &proto.CountKeysReq{})
+		statuses[i] = replicaStatus{info: info, count: count, latency: latency, err: err}
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPLICA\tSTATUS\tLATENCY\tKEYS\tDELETED\tINTERNAL\tMODE\tUPTIME\tREVISION")
+	var serving []int
+	failed := 0
+	for i, st := range statuses {
+		if st.info == nil {
+			failed++
+			fmt.Fprintf(w, "%s\t%s\t-\t-\t-\t-\t-\t-\t%s\n", rs[i].addr, describeError(st.err), status.Convert(st.err).Message())
+			continue
+		}
+		if st.info.GetMode() == proto.ReplicaMode_SERVING {
+			serving = append(serving, i)
+		}
+		keys, deleted, internal := "-", "-", "-"
+		if st.count != nil {
+			keys = strconv.FormatUint(st.count.GetKeys(), 10)
+			deleted = strconv.FormatUint(st.count.GetDeleted(), 10)
+			internal = strconv.FormatUint(st.count.GetInternal(), 10)
+		}
+		uptime := (time.Duration(st.info.GetUptimeMillis()) * time.Millisecond).Round(time.Second)
+		fmt.Fprintf(w, "%s\treachable\t%v\t%s\t%s\t%s\t%v\t%v\t%s\n", rs[i].addr, st.latency.Round(time.Microsecond),
+			keys, deleted, internal, st.info.GetMode(), uptime, formatRevision(st.info.GetBuild()))
+	}
+	w.Flush()
+	fmt.Printf("%d of %d replicas serving, %s\n", len(serving), len(rs), quorumStatus(q, *codedShards, serving, len(rs)))
+	if failed > 0 {
+		return errDiverged
+	}
+	return nil
+}
+
+// quorumStatus
+// tell whether the serving replicas form a read and a write quorum of q and, with erasure-coded
+// registers of k data shards, the ceil((n+k)/2) replicas their phases wait for
+func quorumStatus(q protocol.QuorumSystem, k int, serving []int, n int) string {
+	read, write := q.IsReadQuorum(serving), q.IsWriteQuorum(serving)
+	var desc string
+	switch {
+	case read && write:
+		desc = "enough for reads and writes"
+	case read:
+		desc = "enough for reads but not for writes"
+	case write:
+		desc = "enough for writes but not for reads"
+	default:
+		desc = "not enough for reads or writes"
+	}
+	if k > 0 {
+		coded := (n + k + 1) / 2
+		if len(serving) >= coded {
+			desc += fmt.Sprintf(", erasure-coded registers need %d and have them", coded)
+		} else {
+			desc += fmt.Sprintf(", erasure-coded registers need %d", coded)
+		}
+	}
+	return desc
+}
+
+// formatRevision prints the short VCS revision of a build, marked + if it had local changes
+func formatRevision(build *proto.BuildInfo) string {
+	revision := build.GetRevision()
+	if revision == "" {
+		return build.GetVersion()
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if build.GetModified() {
+		revision += "+"
+	}
+	return revision
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"shared-registers/client/protocol"
+	"testing"
+)
+
+func TestQuorumStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		q       protocol.QuorumSystem
+		k       int
+		serving []int
+		want    string
+	}{
+		{"majority", protocol.MajorityQuorum{N: 5}, 0, []int{0, 1, 2}, "enough for reads and writes"},
+		{"no majority", protocol.MajorityQuorum{N: 5}, 0, []int{0, 1}, "not enough for reads or writes"},
+		{"flexible", protocol.FlexibleQuorum{ReadSize: 2, WriteSize: 4}, 0, []int{0, 1, 2}, "enough for reads but not for writes"},
+		{"coded", protocol.MajorityQuorum{N: 5}, 3, []int{0, 1, 2}, "enough for reads and writes, erasure-coded registers need 4"},
+		{"coded quorum", protocol.MajorityQuorum{N: 5}, 3, []int{0, 1, 2, 3}, "enough for reads and writes, erasure-coded registers need 4 and have them"},
+	} {
+		if got := quorumStatus(tc.q, tc.k, tc.serving, 5); got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package erasure
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrTooFewShards   = errors.New("erasure: too few shards to reconstruct the value")
+	errSingularMatrix = errors.New("erasure: matrix is singular")
+)
+
+// Encoder
+// systematic Reed-Solomon code over GF(2^8): a value is split into DataShards equally sized shards
+// and extended with TotalShards-DataShards parity shards, any DataShards of the TotalShards coded
+// fragments are enough to reconstruct the value
+type Encoder struct {
+	DataShards  int
+	TotalShards int
+	matrix      matrix // TotalShards x DataShards, the top DataShards rows form the identity
+}
+
+func NewEncoder(dataShards, totalShards int) (*Encoder, error) {
+	if dataShards <= 0 || totalShards > 256 || dataShards > totalShards {
+		return nil, fmt.Errorf("erasure: invalid code parameters k=%d n=%d", dataShards, totalShards)
+	}
+	// make the vandermonde matrix systematic by multiplying with the inverse of its top square, this
+	// keeps the property that any dataShards rows are independent
+	v := vandermonde(totalShards, dataShards)
+	top, err := v[:dataShards].invert()
+	if err != nil {
+		return nil, err
+	}
+	return &Encoder{
+		DataShards:  dataShards,
+		TotalShards: totalShards,
+		matrix:      v.multiply(top),
+	}, nil
+}
+
+// ShardSize returns the size of every coded fragment for a value of valueSize bytes
+func (e *Encoder) ShardSize(valueSize int) int {
+	return (valueSize + e.DataShards - 1) / e.DataShards
+}
+
+// Encode
+// split the value into DataShards shards, padding the last one with zeros, and compute the parity
+// shards, the returned slice always has TotalShards entries
+func (e *Encoder) Encode(value []byte) [][]byte {
+	shardSize := e.ShardSize(len(value))
+	shards := make([][]byte, e.TotalShards)
+	for i := 0; i < e.DataShards; i++ {
+		shards[i] = make([]byte, shardSize)
+		if start := i * shardSize; start < len(value) {
+			copy(shards[i], value[start:])
+		}
+	}
+	for r := e.DataShards; r < e.TotalShards; r++ {
+		shards[r] = make([]byte, shardSize)
+		for c := 0; c < e.DataShards; c++ {
+			coef := e.matrix[r][c]
+			if coef == 0 {
+				continue
+			}
+			for b, v := range shards[c] {
+				shards[r][b] ^= gfMul(coef, v)
+			}
+		}
+	}
+	return shards
+}
+
+// Decode
+// reconstruct the original value of valueSize bytes from the shards, missing shards have to be
+// nil and the slice has to be indexed by the shard index, at least DataShards shards are required
+func (e *Encoder) Decode(shards [][]byte, valueSize int) ([]byte, error) {
+	if len(shards) != e.TotalShards {
+		return nil, fmt.Errorf("erasure: expected %d shards, got %d", e.TotalShards, len(shards))
+	}
+	shardSize := e.ShardSize(valueSize)
+	present := make([]int, 0, e.DataShards)
+	for i, s := range shards {
+		if s == nil {
+			continue
+		}
+		if len(s) != shardSize {
+			return nil, fmt.Errorf("erasure: shard %d has size %d, expected %d", i, len(s), shardSize)
+		}
+		present = append(present, i)
+		if len(present) == e.DataShards {
+			break
+		}
+	}
+	if len(present) < e.DataShards {
+		return nil, ErrTooFewShards
+	}
+
+	value := make([]byte, 0, shardSize*e.DataShards)
+	// fast path, all the data shards are available
+	if present[e.DataShards-1] == e.DataShards-1 {
+		for i := 0; i < e.DataShards; i++ {
+			value = append(value, shards[i]...)
+		}
+		return value[:valueSize], nil
+	}
+
+	sub := newMatrix(e.DataShards, e.DataShards)
+	for r, idx := range present {
+		copy(sub[r], e.matrix[idx])
+	}
+	decodeMatrix, err := sub.invert()
+	if err != nil {
+		return nil, err
+	}
+	for r := 0; r < e.DataShards; r++ {
+		shard := make([]byte, shardSize)
+		for c, idx := range present {
+			coef := decodeMatrix[r][c]
+			if coef == 0 {
+				continue
+			}
+			for b, v := range shards[idx] {
+				shard[b] ^= gfMul(coef, v)
+			}
+		}
+		value = append(value, shard...)
+	}
+	return value[:valueSize], nil
+}
@@ -0,0 +1,56 @@
+package erasure
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestEncodeDecodeWithLosses(t *testing.T) {
+	for _, params := range [][2]int{{1, 1}, {1, 5}, {3, 5}, {4, 6}, {10, 14}} {
+		k, n := params[0], params[1]
+		e, err := NewEncoder(k, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, size := range []int{0, 1, k - 1, k, 1000, 4097} {
+			value := make([]byte, size)
+			rand.Read(value)
+			shards := e.Encode(value)
+			if len(shards) != n {
+				t.Fatalf("k=%d n=%d: got %d shards", k, n, len(shards))
+			}
+			// drop n-k random shards
+			for _, i := range rand.Perm(n)[:n-k] {
+				shards[i] = nil
+			}
+			decoded, err := e.Decode(shards, size)
+			if err != nil {
+				t.Fatalf("k=%d n=%d size=%d: %v", k, n, size, err)
+			}
+			if !bytes.Equal(decoded, value) {
+				t.Errorf("k=%d n=%d size=%d: decoded value doesn't match", k, n, size)
+			}
+		}
+	}
+}
+
+func TestDecodeTooFewShards(t *testing.T) {
+	e, err := NewEncoder(3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shards := e.Encode([]byte("hello erasure coding"))
+	shards[0], shards[2], shards[4] = nil, nil, nil
+	if _, err := e.Decode(shards, 20); err != ErrTooFewShards {
+		t.Errorf("expected ErrTooFewShards, got %v", err)
+	}
+}
+
+func TestInvalidParameters(t *testing.T) {
+	for _, params := range [][2]int{{0, 5}, {6, 5}, {3, 257}} {
+		if _, err := NewEncoder(params[0], params[1]); err == nil {
+			t.Errorf("expected error for k=%d n=%d", params[0], params[1])
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"shared-registers/client/protocol"
+	"shared-registers/common/etcdpb"
+	"shared-registers/common/proto"
+	"sort"
+)
+
+// scanPageSize is the number of keys a range or a range delete lists at a time
+const scanPageSize = 1000
+
+// maxRangeKeys is the number of keys a Range holds at most, to return them or to sort them, larger
+// ranges need a limit, and the number of keys a DeleteRange deletes at most
+const maxRangeKeys = 10000
+
+// EtcdKVServer
+// serves the registers through the KV service of etcd v3, so etcd clients work against them for
+// Range (of a key, a range or a prefix), Put and DeleteRange. Registers keep no history: the
+// mod_revision of a key is its Revision, version is 1 and create_revision is 0 for every existing
+// key, and only the latest revision can be read. Range and DeleteRange of more than one key list
+// the keys like Scan, each key is atomic on its own but the range isn't a snapshot, and deleting
+// it isn't atomic as a whole. A Range with a limit in key order stops listing after the key past
+// the limit, so its count and header revision only cover the keys up to that one. A Range that
+// would hold more than maxRangeKeys keys to return or sort them, and a DeleteRange of more keys,
+// fail with ResourceExhausted. Transactions, compaction, leases, prev_kv of a Put and the other
+// etcd services fail with Unimplemented, and too few replicas answering in time with Unavailable.
+type EtcdKVServer struct {
+	etcdpb.UnimplementedKVServer
+	pool *Pool
+}
+
+func NewEtcdKVServer(pool *Pool) *EtcdKVServer {
+	return &EtcdKVServer{pool: pool}
+}
+
+// Revision
+// maps a timestamp to an etcd revision: the request number, or the wall time in nanoseconds of HLC
+// timestamps. Revisions of a key never decrease as long as its writers use the same timestamp scheme,
+// but HLC timestamps differing only in their logical counter share a revision, and unlike etcd
+// revisions they are not comparable across keys.
+func Revision(ts *proto.TimeStamp) int64 {
+	if ts.GetWallTime() != 0 {
+		return int64(ts.GetWallTime())
+	}
+	return int64(ts.GetRequestNumber())
+}
+
+func (s *EtcdKVServer) Range(ctx context.Context, req *etcdpb.RangeRequest) (*etcdpb.RangeResponse, error) {
+	if req.GetRevision() != 0 {
+		return nil, status.Error(codes.Unimplemented, "registers keep no history, only the latest revision (0) can be read")
+	}
+	if req.GetMinCreateRevision() != 0 || req.GetMaxCreateRevision() != 0 {
+		return nil, status.Error(codes.Unimplemented, "registers don't track create revisions")
+	}
+	limit := int(req.GetLimit())
+	// the keys are listed in key order, a limit in that order needs no more than the key past it
+	keyOrder := req.GetSortOrder() == etcdpb.RangeRequest_NONE ||
+		req.GetSortOrder() == etcdpb.RangeRequest_ASCEND && req.GetSortTarget() == etcdpb.RangeRequest_KEY
+	pageSize := scanPageSize
+	if keyOrder && limit > 0 && limit < pageSize {
+		pageSize = limit + 1
+	}
+	resp := &etcdpb.RangeResponse{Header: &etcdpb.ResponseHeader{}}
+	var entries []*proto.KeyValue
+	err := s.list(req.GetKey(), req.GetRangeEnd(), pageSize, func(page []*proto.KeyValue) (bool, error) {
+		for _, e := range page {
+			revision := Revision(e.GetValue().GetTs())
+			if (req.GetMinModRevision() != 0 && revision < req.GetMinModRevision()) ||
+				(req.GetMaxModRevision() != 0 && revision > req.GetMaxModRevision()) {
+				continue
+			}
+			resp.Count++
+			if revision > resp.Header.Revision {
+				resp.Header.Revision = revision
+			}
+			if !req.GetCountOnly() {
+				entries = append(entries, e)
+			}
+		}
+		switch {
+		case keyOrder && limit > 0 && len(entries) > limit:
+			return false, nil
+		case !keyOrder && limit > 0 && len(entries) > limit:
+			// a stable sort keeps the key order of equal entries, so the first limit ones stay the same
+			sortEntries(entries, req.GetSortOrder(), req.GetSortTarget())
+			entries = entries[:limit]
+		}
+		if len(entries) > maxRangeKeys {
+			return false, status.Errorf(codes.ResourceExhausted, "range holds more than %d keys, set a limit", maxRangeKeys)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortEntries(entries, req.GetSortOrder(), req.GetSortTarget())
+	if limit > 0 && resp.Count > int64(limit) {
+		resp.More = true
+		if len(entries) > limit {
+			entries = entries[:limit]
+		}
+	}
+	if !req.GetCountOnly() {
+		for _, e := range entries {
+			kv := keyValue(e)
+			if req.GetKeysOnly() {
+				kv.Value = nil
+			}
+			resp.Kvs = append(resp.Kvs, kv)
+		}
+	}
+	return resp, nil
+}
+
+func (s *EtcdKVServer) Put(ctx context.Context, req *etcdpb.PutRequest) (*etcdpb.PutResponse, error) {
+	switch {
+	case req.GetLease() != 0 || req.GetIgnoreLease():
+		return nil, status.Error(codes.Unimplemented, "leases aren't supported, the client library writes values with a TTL instead")
+	case req.GetIgnoreValue():
+		return nil, status.Error(codes.Unimplemented, "ignore_value isn't supported")
+	case req.GetPrevKv():
+		return nil, status.Error(codes.Unimplemented, "prev_kv isn't supported for puts, the value replaced isn't returned atomically")
+	}
+	key := string(req.GetKey())
+	if err := checkEtcdKey(key); err != nil {
+		return nil, err
+	}
+	var ts *proto.TimeStamp
+	err := s.pool.Do(func(r Registers) (err error) {
+		ts, err = r.WriteBytesWithTimeStamp(key, req.GetValue(), "", 0)
+		return err
+	})
+	if err != nil {
+		return nil, etcdError(err)
+	}
+	return &etcdpb.PutResponse{Header: &etcdpb.ResponseHeader{Revision: Revision(ts)}}, nil
+}
+
+func (s *EtcdKVServer) DeleteRange(ctx context.Context, req *etcdpb.DeleteRangeRequest) (*etcdpb.DeleteRangeResponse, error) {
+	var entries []*proto.KeyValue
+	err := s.list(req.GetKey(), req.GetRangeEnd(), scanPageSize, func(page []*proto.KeyValue) (bool, error) {
+		entries = append(entries, page...)
+		// nothing is deleted yet, a range too large to hold fails as a whole
+		if len(entries) > maxRangeKeys {
+			return false, status.Errorf(codes.ResourceExhausted, "range holds more than %d keys, delete it in parts", maxRangeKeys)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	errs := make([]error, len(entries))
+	parallel(len(entries), func(i int) {
+		errs[i] = s.pool.Do(func(r Registers) error { return r.Delete(entries[i].GetKey()) })
+	})
+	for _, err := range errs {
+		if err != nil {
+			return nil, etcdError(err)
+		}
+	}
+	resp := &etcdpb.DeleteRangeResponse{Header: header(entries...), Deleted: int64(len(entries))}
+	if req.GetPrevKv() {
+		for _, e := range entries {
+			resp.PrevKvs = append(resp.PrevKvs, keyValue(e))
+		}
+	}
+	return resp, nil
+}
+
+func (s *EtcdKVServer) Txn(context.Context, *etcdpb.TxnRequest) (*etcdpb.TxnResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "transactions aren't supported, the client library offers CompareAndSwap of a single key")
+}
+
+func (s *EtcdKVServer) Compact(context.Context, *etcdpb.CompactionRequest) (*etcdpb.CompactionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "registers keep no history to compact")
+}
+
+// list
+// call fn with the existing keys in the etcd range of key and rangeEnd in key order, pageSize at a
+// time, until fn returns false or fails
+func (s *EtcdKVServer) list(key, rangeEnd []byte, pageSize int, fn func(page []*proto.KeyValue) (bool, error)) error {
+	if len(rangeEnd) == 0 {
+		if err := checkEtcdKey(string(key)); err != nil {
+			return err
+		}
+		var value *proto.StoredValue
+		err := s.pool.Do(func(r Registers) (err error) {
+			value, err = r.ReadValue(string(key))
+			return err
+		})
+		if errors.Is(err, protocol.ErrKeyNotFound) {
+			return nil
+		}
+		if err != nil {
+			return etcdError(err)
+		}
+		_, err = fn([]*proto.KeyValue{{Key: string(key), Value: value}})
+		return err
+	}
+	// a range end of "\0" means every key from key on
+	end := string(rangeEnd)
+	if end == "\x00" {
+		end = ""
+	}
+	pageToken := ""
+	for {
+		var page []*proto.KeyValue
+		err := s.pool.Do(func(r Registers) (err error) {
+			page, pageToken, err = r.Scan(string(key), end, pageSize, pageToken)
+			return err
+		})
+		if err != nil {
+			return etcdError(err)
+		}
+		if more, err := fn(page); !more || err != nil || pageToken == "" {
+			return err
+		}
+	}
+}
+
+// sortEntries sorts the entries listed in key order as a Range asks for
+func sortEntries(entries []*proto.KeyValue, order etcdpb.RangeRequest_SortOrder, target etcdpb.RangeRequest_SortTarget) {
+	if order == etcdpb.RangeRequest_NONE {
+		return
+	}
+	less := func(i, j int) bool { return false } // VERSION and CREATE are the same for every key
+	switch target {
+	case etcdpb.RangeRequest_KEY:
+		less = func(i, j int) bool { return entries[i].GetKey() < entries[j].GetKey() }
+	case etcdpb.RangeRequest_MOD:
+		less = func(i, j int) bool {
+			return Revision(entries[i].GetValue().GetTs()) < Revision(entries[j].GetValue().GetTs())
+		}
+	case etcdpb.RangeRequest_VALUE:
+		less = func(i, j int) bool {
+			return bytes.Compare(entries[i].GetValue().GetVal(), entries[j].GetValue().GetVal()) < 0
+		}
+	}
+	if order == etcdpb.RangeRequest_DESCEND {
+		ascending := less
+		less = func(i, j int) bool { return ascending(j, i) }
+	}
+	sort.SliceStable(entries, less)
+}
+
+func keyValue(e *proto.KeyValue) *etcdpb.KeyValue {
+	return &etcdpb.KeyValue{Key: []byte(e.GetKey()), Value: e.GetValue().GetVal(),
+		ModRevision: Revision(e.GetValue().GetTs()), Version: 1}
+}
+
+// header reports the largest revision of the entries as the revision of the response
+func header(entries ...*proto.KeyValue) *etcdpb.ResponseHeader {
+	h := &etcdpb.ResponseHeader{}
+	for _, e := range entries {
+		if revision := Revision(e.GetValue().GetTs()); revision > h.Revision {
+			h.Revision = revision
+		}
+	}
+	return h
+}
+
+func checkEtcdKey(key string) error {
+	if key == "" {
+		return status.Error(codes.InvalidArgument, "etcdserver: key is not provided")
+	}
+	if err := checkKey(key); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// etcdError maps the errors of the registers to gRPC statuses etcd clients handle
+func etcdError(err error) error {
+	switch {
+	case errors.Is(err, protocol.ErrQuorumUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, protocol.ErrConflictingTimeStamp):
+		return status.Error(codes.Aborted, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
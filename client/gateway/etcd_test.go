@@ -0,0 +1,163 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"net"
+	"shared-registers/common/etcdpb"
+	"shared-registers/common/proto"
+	"strconv"
+	"testing"
+)
+
+func newEtcdClient(t *testing.T, registers Registers) etcdpb.KVClient {
+	pool, _ := NewPool(registers)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	etcdpb.RegisterKVServer(server, NewEtcdKVServer(pool))
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return etcdpb.NewKVClient(conn)
+}
+
+func keysOf(kvs []*etcdpb.KeyValue) string {
+	keys := ""
+	for _, kv := range kvs {
+		keys += string(kv.GetKey()) + ","
+	}
+	return keys
+}
+
+func TestEtcdKV(t *testing.T) {
+	registers := newMemoryRegisters()
+	kv := newEtcdClient(t, registers)
+	ctx := context.Background()
+
+	put, err := kv.Put(ctx, &etcdpb.PutRequest{Key: []byte("app/b"), Value: []byte("2")})
+	if err != nil || put.GetHeader().GetRevision() != 1 {
+		t.Fatalf("put failed: %v %v", put, err)
+	}
+	kv.Put(ctx, &etcdpb.PutRequest{Key: []byte("app/a"), Value: []byte("1")})
+	kv.Put(ctx, &etcdpb.PutRequest{Key: []byte("app/c"), Value: []byte("3")})
+	kv.Put(ctx, &etcdpb.PutRequest{Key: []byte("other"), Value: []byte("x")})
+
+	rsp, err := kv.Range(ctx, &etcdpb.RangeRequest{Key: []byte("app/b")})
+	if err != nil || len(rsp.GetKvs()) != 1 || string(rsp.GetKvs()[0].GetValue()) != "2" || rsp.GetKvs()[0].GetModRevision() != 1 {
+		t.Fatalf("range of a key failed: %v %v", rsp, err)
+	}
+	if rsp, err := kv.Range(ctx, &etcdpb.RangeRequest{Key: []byte("missing")}); err != nil || rsp.GetCount() != 0 || len(rsp.GetKvs()) != 0 {
+		t.Errorf("expected no keys, got %v %v", rsp, err)
+	}
+
+	// the prefix app/ is the range [app/, app0)
+	prefix := &etcdpb.RangeRequest{Key: []byte("app/"), RangeEnd: []byte("app0"), Limit: 2,
+		SortOrder: etcdpb.RangeRequest_DESCEND, SortTarget: etcdpb.RangeRequest_KEY}
+	rsp, err = kv.Range(ctx, prefix)
+	if err != nil || keysOf(rsp.GetKvs()) != "app/c,app/b," || rsp.GetCount() != 3 || !rsp.GetMore() {
+		t.Fatalf("prefix range failed: %v %v", rsp, err)
+	}
+	if rsp.GetHeader().GetRevision() != 3 {
+		t.Errorf("expected the header to carry the latest revision, got %d", rsp.GetHeader().GetRevision())
+	}
+	rsp, _ = kv.Range(ctx, &etcdpb.RangeRequest{Key: []byte("app/"), RangeEnd: []byte("app0"), KeysOnly: true,
+		SortOrder: etcdpb.RangeRequest_ASCEND, SortTarget: etcdpb.RangeRequest_MOD})
+	if keysOf(rsp.GetKvs()) != "app/b,app/a,app/c," || rsp.GetKvs()[0].GetValue() != nil {
+		t.Errorf("range by mod revision failed: %v", rsp)
+	}
+	rsp, _ = kv.Range(ctx, &etcdpb.RangeRequest{Key: []byte("app/"), RangeEnd: []byte("\x00"), CountOnly: true})
+	if rsp.GetCount() != 4 || len(rsp.GetKvs()) != 0 {
+		t.Errorf("count of every key from app/ failed: %v", rsp)
+	}
+	rsp, _ = kv.Range(ctx, &etcdpb.RangeRequest{Key: []byte("app/"), RangeEnd: []byte("app0"), MinModRevision: 2})
+	if keysOf(rsp.GetKvs()) != "app/a,app/c," {
+		t.Errorf("range from mod revision 2 failed: %v", rsp)
+	}
+
+	del, err := kv.DeleteRange(ctx, &etcdpb.DeleteRangeRequest{Key: []byte("app/"), RangeEnd: []byte("app0"), PrevKv: true})
+	if err != nil || del.GetDeleted() != 3 || keysOf(del.GetPrevKvs()) != "app/a,app/b,app/c," {
+		t.Fatalf("range delete failed: %v %v", del, err)
+	}
+	if rsp, _ := kv.Range(ctx, &etcdpb.RangeRequest{Key: []byte{0}, RangeEnd: []byte{0}}); keysOf(rsp.GetKvs()) != "other," {
+		t.Errorf("expected only other to be left, got %v", rsp)
+	}
+	if del, _ := kv.DeleteRange(ctx, &etcdpb.DeleteRangeRequest{Key: []byte("app/a")}); del.GetDeleted() != 0 {
+		t.Errorf("deleted a missing key: %v", del)
+	}
+
+	for name, call := range map[string]func() error{
+		"txn":         func() error { _, err := kv.Txn(ctx, &etcdpb.TxnRequest{}); return err },
+		"compact":     func() error { _, err := kv.Compact(ctx, &etcdpb.CompactionRequest{}); return err },
+		"lease":       func() error { _, err := kv.Put(ctx, &etcdpb.PutRequest{Key: []byte("k"), Lease: 7}); return err },
+		"prev kv":     func() error { _, err := kv.Put(ctx, &etcdpb.PutRequest{Key: []byte("k"), PrevKv: true}); return err },
+		"old version": func() error { _, err := kv.Range(ctx, &etcdpb.RangeRequest{Key: []byte("k"), Revision: 1}); return err },
+	} {
+		if status.Code(call()) != codes.Unimplemented {
+			t.Errorf("%s: expected Unimplemented", name)
+		}
+	}
+	if _, err := kv.Put(ctx, &etcdpb.PutRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an empty key, got %v", err)
+	}
+	registers.down = true
+	if _, err := kv.Range(ctx, &etcdpb.RangeRequest{Key: []byte("other")}); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected Unavailable without a quorum, got %v", err)
+	}
+}
+
+func TestEtcdRangeLimit(t *testing.T) {
+	registers := newMemoryRegisters()
+	kv := newEtcdClient(t, registers)
+	ctx := context.Background()
+	for i := 0; i <= maxRangeKeys; i++ {
+		kv.Put(ctx, &etcdpb.PutRequest{Key: []byte(fmt.Sprintf("k/%05d", i)), Value: []byte(strconv.Itoa(maxRangeKeys - i))})
+	}
+	all := &etcdpb.RangeRequest{Key: []byte("k/"), RangeEnd: []byte("k0")}
+
+	// in key order the range stops listing right after the limit
+	registers.scanned = 0
+	rsp, err := kv.Range(ctx, &etcdpb.RangeRequest{Key: all.Key, RangeEnd: all.RangeEnd, Limit: 2})
+	if err != nil || keysOf(rsp.GetKvs()) != "k/00000,k/00001," || !rsp.GetMore() || registers.scanned != 3 {
+		t.Fatalf("expected 2 keys out of 3 listed, got %v %v after listing %d", rsp, err, registers.scanned)
+	}
+	// other orders list everything, but only keep the limit
+	rsp, err = kv.Range(ctx, &etcdpb.RangeRequest{Key: all.Key, RangeEnd: all.RangeEnd, Limit: 2,
+		SortOrder: etcdpb.RangeRequest_ASCEND, SortTarget: etcdpb.RangeRequest_VALUE})
+	if err != nil || keysOf(rsp.GetKvs()) != fmt.Sprintf("k/%05d,k/%05d,", maxRangeKeys, maxRangeKeys-1) ||
+		rsp.GetCount() != maxRangeKeys+1 || !rsp.GetMore() {
+		t.Fatalf("expected the 2 smallest values, got %v %v", rsp.GetKvs(), err)
+	}
+	if rsp, err := kv.Range(ctx, &etcdpb.RangeRequest{Key: all.Key, RangeEnd: all.RangeEnd, CountOnly: true}); err != nil || rsp.GetCount() != maxRangeKeys+1 {
+		t.Errorf("expected every key counted, got %v %v", rsp.GetCount(), err)
+	}
+	if _, err := kv.Range(ctx, all); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected ResourceExhausted for a range without limit, got %v", err)
+	}
+	// a delete of too many keys fails before deleting any
+	if _, err := kv.DeleteRange(ctx, &etcdpb.DeleteRangeRequest{Key: all.Key, RangeEnd: all.RangeEnd}); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected ResourceExhausted for a large delete, got %v", err)
+	}
+	if rsp, err := kv.Range(ctx, &etcdpb.RangeRequest{Key: all.Key, RangeEnd: all.RangeEnd, CountOnly: true}); err != nil || rsp.GetCount() != maxRangeKeys+1 {
+		t.Errorf("expected every key kept, got %v %v", rsp.GetCount(), err)
+	}
+}
+
+func TestRevision(t *testing.T) {
+	if r := Revision(&proto.TimeStamp{RequestNumber: 7, ClientID: "c"}); r != 7 {
+		t.Errorf("expected the request number, got %d", r)
+	}
+	if r := Revision(&proto.TimeStamp{RequestNumber: 7, WallTime: 1e18, Logical: 2}); r != 1e18 {
+		t.Errorf("expected the wall time, got %d", r)
+	}
+}
@@ -0,0 +1,351 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"shared-registers/client/protocol"
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	registersPath  = "/v1/registers/"
+	batchGetPath   = "/v1/batch/get"
+	batchWritePath = "/v1/batch/write"
+
+	// DefaultMaxBodySize is the default limit of request bodies, values are at most this large
+	DefaultMaxBodySize = 32 << 20
+	// MaxBatchSize is the most keys a batch request may contain
+	MaxBatchSize = 1000
+)
+
+// headers carrying the timestamp of a value, besides its ETag
+const (
+	RequestNumberHeader = "X-Register-Request-Number"
+	ClientIDHeader      = "X-Register-Client-Id"
+	WrittenHeader       = "X-Register-Written" // RFC 3339 write time, only if the writer used the HLC scheme
+)
+
+// Register is the JSON form of a register
+type Register struct {
+	Key         string   `json:"key"`
+	Value       *string  `json:"value,omitempty"`       // the value if it is valid UTF-8
+	ValueBase64 []byte   `json:"valueBase64,omitempty"` // the value otherwise, writes may use either
+	ContentType string   `json:"contentType,omitempty"`
+	TTL         string   `json:"ttl,omitempty"`    // writes only, a duration like 30s after which the value expires
+	Delete      bool     `json:"delete,omitempty"` // batch writes only, delete the key instead
+	Version     *Version `json:"version,omitempty"`
+	Status      int      `json:"status,omitempty"` // batch results only, the HTTP status of the operation
+	Error       string   `json:"error,omitempty"`
+}
+
+// Version is the timestamp of a value, later writes have larger ones
+type Version struct {
+	RequestNumber uint64 `json:"requestNumber"`
+	ClientID      string `json:"clientID"`
+	WallTime      uint64 `json:"wallTime,omitempty"`
+	Logical       uint32 `json:"logical,omitempty"`
+	Written       string `json:"written,omitempty"` // RFC 3339, only if the writer used the HLC scheme
+}
+
+type batchGetRequest struct {
+	Keys []string `json:"keys"`
+}
+
+type batchWriteRequest struct {
+	Writes []*Register `json:"writes"`
+}
+
+type batchResponse struct {
+	Registers []*Register `json:"registers"`
+}
+
+// badRequest is an error of the caller, as opposed to one of the registers
+type badRequest struct {
+	message string
+}
+
+func (e *badRequest) Error() string {
+	return e.message
+}
+
+// HTTPHandler
+// serves the registers as JSON over HTTP:
+//
+//	GET, HEAD, PUT, DELETE /v1/registers/{key}
+//	POST /v1/batch/get   {"keys": [...]}
+//	POST /v1/batch/write {"writes": [{"key": ..., "value": ...}, {"key": ..., "delete": true}]}
+//
+// Reads return the timestamp of the value as its ETag and in the X-Register-* headers, and honor
+// If-None-Match. The operations of a batch run concurrently on the pool and each has a status of its
+// own; a batch isn't atomic. Missing keys are 404, and 503 means too few replicas answered in time,
+// so the operation may or may not have taken effect.
+type HTTPHandler struct {
+	pool        *Pool
+	MaxBodySize int64
+}
+
+func NewHTTPHandler(pool *Pool) *HTTPHandler {
+	return &HTTPHandler{pool: pool, MaxBodySize: DefaultMaxBodySize}
+}
+
+func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.MaxBodySize)
+	switch {
+	case strings.HasPrefix(r.URL.Path, registersPath):
+		h.serveRegister(w, r, strings.TrimPrefix(r.URL.Path, registersPath))
+	case r.URL.Path == batchGetPath || r.URL.Path == batchWritePath:
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, http.StatusMethodNotAllowed, errors.New("batches are POSTed"))
+			return
+		}
+		if r.URL.Path == batchGetPath {
+			h.batchGet(w, r)
+		} else {
+			h.batchWrite(w, r)
+		}
+	default:
+		writeError(w, http.StatusNotFound, errors.New("no such endpoint"))
+	}
+}
+
+func (h *HTTPHandler) serveRegister(w http.ResponseWriter, r *http.Request, key string) {
+	if err := checkKey(key); err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		register := h.read(key)
+		if register.Error != "" {
+			writeError(w, register.Status, errors.New(register.Error))
+			return
+		}
+		setVersionHeaders(w.Header(), register.Version)
+		if match := r.Header.Get("If-None-Match"); match != "" && match == w.Header().Get("ETag") {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if r.Method == http.MethodHead {
+			return
+		}
+		register.Status = 0
+		writeJSON(w, http.StatusOK, register)
+	case http.MethodPut:
+		register := &Register{}
+		if !readJSON(w, r, register) {
+			return
+		}
+		register.Key = key
+		result := h.write(register)
+		if result.Error != "" {
+			writeError(w, result.Status, errors.New(result.Error))
+			return
+		}
+		setVersionHeaders(w.Header(), result.Version)
+		result.Status = 0
+		writeJSON(w, http.StatusOK, result)
+	case http.MethodDelete:
+		if err := h.pool.Do(func(c Registers) error { return c.Delete(key) }); err != nil {
+			writeError(w, statusOf(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	}
+}
+
+func (h *HTTPHandler) batchGet(w http.ResponseWriter, r *http.Request) {
+	request := &batchGetRequest{}
+	if !readJSON(w, r, request) {
+		return
+	}
+	if len(request.Keys) > MaxBatchSize {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("batches are limited to %d keys", MaxBatchSize))
+		return
+	}
+	response := &batchResponse{Registers: make([]*Register, len(request.Keys))}
+	parallel(len(request.Keys), func(i int) {
+		response.Registers[i] = h.read(request.Keys[i])
+	})
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *HTTPHandler) batchWrite(w http.ResponseWriter, r *http.Request) {
+	request := &batchWriteRequest{}
+	if !readJSON(w, r, request) {
+		return
+	}
+	if len(request.Writes) > MaxBatchSize {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("batches are limited to %d keys", MaxBatchSize))
+		return
+	}
+	response := &batchResponse{Registers: make([]*Register, len(request.Writes))}
+	parallel(len(request.Writes), func(i int) {
+		response.Registers[i] = h.write(request.Writes[i])
+	})
+	writeJSON(w, http.StatusOK, response)
+}
+
+// read returns the register of the key, or its error and status
+func (h *HTTPHandler) read(key string) *Register {
+	register := &Register{Key: key, Status: http.StatusOK}
+	if err := checkKey(key); err != nil {
+		return register.failed(err)
+	}
+	var value *proto.StoredValue
+	err := h.pool.Do(func(c Registers) (err error) {
+		value, err = c.ReadValue(key)
+		return err
+	})
+	if err != nil {
+		return register.failed(err)
+	}
+	if utf8.Valid(value.GetVal()) {
+		s := string(value.GetVal())
+		register.Value = &s
+	} else {
+		register.ValueBase64 = value.GetVal()
+	}
+	register.ContentType = value.GetContentType()
+	register.Version = versionOf(value.GetTs())
+	return register
+}
+
+// write writes or deletes the register, returning its key and new version, or its error and status
+func (h *HTTPHandler) write(register *Register) *Register {
+	result := &Register{Key: register.Key, Status: http.StatusOK}
+	if err := checkKey(register.Key); err != nil {
+		return result.failed(err)
+	}
+	if register.Delete {
+		if err := h.pool.Do(func(c Registers) error { return c.Delete(register.Key) }); err != nil {
+			return result.failed(err)
+		}
+		result.Status = http.StatusNoContent
+		return result
+	}
+	value, err := register.value()
+	if err != nil {
+		return result.failed(err)
+	}
+	var ttl time.Duration
+	if register.TTL != "" {
+		if ttl, err = time.ParseDuration(register.TTL); err != nil || ttl <= 0 {
+			return result.failed(&badRequest{"ttl must be a positive duration, e.g. 30s"})
+		}
+	}
+	var ts *proto.TimeStamp
+	err = h.pool.Do(func(c Registers) (err error) {
+		ts, err = c.WriteBytesWithTimeStamp(register.Key, value, register.ContentType, ttl)
+		return err
+	})
+	if err != nil {
+		return result.failed(err)
+	}
+	result.Version = versionOf(ts)
+	return result
+}
+
+// value returns the value a write sets
+func (r *Register) value() ([]byte, error) {
+	switch {
+	case r.Value != nil && r.ValueBase64 != nil:
+		return nil, &badRequest{"set either value or valueBase64"}
+	case r.Value != nil:
+		return []byte(*r.Value), nil
+	case r.ValueBase64 != nil:
+		return r.ValueBase64, nil
+	}
+	return nil, &badRequest{"value or valueBase64 is required"}
+}
+
+func (r *Register) failed(err error) *Register {
+	r.Status, r.Error = statusOf(err), err.Error()
+	return r
+}
+
+func checkKey(key string) error {
+	if key == "" {
+		return &badRequest{"the key is missing"}
+	}
+	if common.IsInternalKey(key) {
+		return &badRequest{"keys starting with " + strconv.Quote(common.InternalKeyPrefix) + " are reserved"}
+	}
+	return nil
+}
+
+// statusOf maps the errors of the registers to HTTP statuses
+func statusOf(err error) int {
+	var bad *badRequest
+	switch {
+	case errors.As(err, &bad):
+		return http.StatusBadRequest
+	case errors.Is(err, protocol.ErrKeyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, protocol.ErrQuorumUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, protocol.ErrConflictingTimeStamp):
+		return http.StatusConflict
+	case errors.Is(err, protocol.ErrDecryption), errors.Is(err, protocol.ErrNotEncrypted):
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
+
+func versionOf(ts *proto.TimeStamp) *Version {
+	v := &Version{RequestNumber: ts.GetRequestNumber(), ClientID: ts.GetClientID(), WallTime: ts.GetWallTime(), Logical: ts.GetLogical()}
+	if written := common.WallTime(ts); !written.IsZero() {
+		v.Written = written.UTC().Format(time.RFC3339Nano)
+	}
+	return v
+}
+
+// ETag returns the entity tag of the version, which changes with every write
+func (v *Version) ETag() string {
+	return fmt.Sprintf("\"%d.%d.%d.%s\"", v.RequestNumber, v.WallTime, v.Logical, v.ClientID)
+}
+
+func setVersionHeaders(header http.Header, v *Version) {
+	header.Set("ETag", v.ETag())
+	header.Set(RequestNumberHeader, strconv.FormatUint(v.RequestNumber, 10))
+	header.Set(ClientIDHeader, v.ClientID)
+	if v.Written != "" {
+		header.Set(WrittenHeader, v.Written)
+		header.Set("Last-Modified", time.Unix(0, int64(v.WallTime)).UTC().Format(http.TimeFormat))
+	}
+}
+
+// readJSON decodes the request body into v, or responds with an error and returns false
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, err)
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+	default:
+		return true
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
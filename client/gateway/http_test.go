@@ -0,0 +1,205 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"shared-registers/client/protocol"
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryRegisters keeps the registers in a map, enough to test the front-ends without replicas
+type memoryRegisters struct {
+	lock   sync.Mutex
+	values map[string]*proto.StoredValue
+	number  uint64
+	down    bool // fail every operation as if no quorum answered
+	scanned int  // entries returned by Scan
+}
+
+func newMemoryRegisters() *memoryRegisters {
+	return &memoryRegisters{values: make(map[string]*proto.StoredValue)}
+}
+
+func (m *memoryRegisters) ReadValue(key string) (*proto.StoredValue, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.down {
+		return nil, &protocol.PhaseTimeoutError{Phase: "completeGetPhase"}
+	}
+	v, ok := m.values[key]
+	if !ok || v.GetDeleted() {
+		return nil, &protocol.KeyNotFoundError{Key: key}
+	}
+	return v, nil
+}
+
+func (m *memoryRegisters) WriteBytesWithTimeStamp(key string, value []byte, contentType string, ttl time.Duration) (*proto.TimeStamp, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.down {
+		return nil, &protocol.PhaseTimeoutError{Phase: "completeSetPhase"}
+	}
+	m.number++
+	ts := &proto.TimeStamp{RequestNumber: m.number, ClientID: "memory"}
+	m.values[key] = &proto.StoredValue{Val: value, ContentType: contentType, Ts: ts}
+	return ts, nil
+}
+
+func (m *memoryRegisters) Delete(key string) error {
+	_, err := m.WriteBytesWithTimeStamp(key, nil, "", 0)
+	if err == nil {
+		m.lock.Lock()
+		m.values[key].Deleted = true
+		m.lock.Unlock()
+	}
+	return err
+}
+
+func (m *memoryRegisters) Scan(start, end string, limit int, pageToken string) ([]*proto.KeyValue, string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.down {
+		return nil, "", &protocol.PhaseTimeoutError{Phase: "completeScanPhase"}
+	}
+	keys := make([]string, 0)
+	for key, v := range m.values {
+		if key >= start && (end == "" || key < end) && key > pageToken && !v.GetDeleted() {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	next := ""
+	if limit > 0 && len(keys) > limit {
+		keys, next = keys[:limit], keys[limit-1]
+	}
+	entries := make([]*proto.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, &proto.KeyValue{Key: key, Value: m.values[key]})
+	}
+	m.scanned += len(entries)
+	return entries, next, nil
+}
+
+func (m *memoryRegisters) ScanPrefix(prefix string, limit int, pageToken string) ([]*proto.KeyValue, string, error) {
+	return m.Scan(prefix, common.PrefixEnd(prefix), limit, pageToken)
+}
+
+func request(t *testing.T, handler http.Handler, method, path, body string, header ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestHTTPRegisters(t *testing.T) {
+	registers := newMemoryRegisters()
+	pool, _ := NewPool(registers)
+	handler := NewHTTPHandler(pool)
+
+	if w := request(t, handler, "GET", "/v1/registers/a/b", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a missing key, got %d", w.Code)
+	}
+	w := request(t, handler, "PUT", "/v1/registers/a/b", `{"value": "hello", "contentType": "text/plain"}`)
+	if w.Code != http.StatusOK || w.Header().Get(RequestNumberHeader) != "1" {
+		t.Fatalf("write failed: %d %v %s", w.Code, w.Header(), w.Body)
+	}
+	etag := w.Header().Get("ETag")
+
+	w = request(t, handler, "GET", "/v1/registers/a/b", "")
+	register := &Register{}
+	json.Unmarshal(w.Body.Bytes(), register)
+	if w.Code != http.StatusOK || register.Value == nil || *register.Value != "hello" || register.ContentType != "text/plain" {
+		t.Fatalf("read failed: %d %s", w.Code, w.Body)
+	}
+	if w.Header().Get("ETag") != etag || register.Version.ETag() != etag {
+		t.Errorf("read returned another version than written: %s", w.Header().Get("ETag"))
+	}
+	if w := request(t, handler, "GET", "/v1/registers/a/b", "", "If-None-Match", etag); w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for an unchanged value, got %d", w.Code)
+	}
+
+	// binary values are base64 encoded
+	request(t, handler, "PUT", "/v1/registers/bin", `{"valueBase64": "AP8="}`)
+	w = request(t, handler, "GET", "/v1/registers/bin", "")
+	register = &Register{}
+	json.Unmarshal(w.Body.Bytes(), register)
+	if !bytes.Equal(register.ValueBase64, []byte{0, 0xff}) || register.Value != nil {
+		t.Errorf("binary value not base64 encoded: %s", w.Body)
+	}
+
+	if w := request(t, handler, "DELETE", "/v1/registers/a/b", ""); w.Code != http.StatusNoContent {
+		t.Errorf("delete failed: %d", w.Code)
+	}
+	if w := request(t, handler, "GET", "/v1/registers/a/b", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after the delete, got %d", w.Code)
+	}
+
+	for _, bad := range []struct{ method, path, body string }{
+		{"PUT", "/v1/registers/x", `{}`},
+		{"PUT", "/v1/registers/x", `{"value": "v", "valueBase64": "AA=="}`},
+		{"PUT", "/v1/registers/x", `{"value": "v", "ttl": "soon"}`},
+		{"PUT", "/v1/registers/x", `not json`},
+		{"GET", "/v1/registers/", ""},
+		{"GET", "/v1/registers/%00internal", ""},
+	} {
+		if w := request(t, handler, bad.method, bad.path, bad.body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s %q %s: expected 400, got %d", bad.method, bad.path, bad.body, w.Code)
+		}
+	}
+	if w := request(t, handler, "POST", "/v1/registers/x", ""); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+	handler.MaxBodySize = 10
+	if w := request(t, handler, "PUT", "/v1/registers/x", `{"value": "too large"}`); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d", w.Code)
+	}
+
+	registers.down = true
+	if w := request(t, handler, "GET", "/v1/registers/bin", ""); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without a quorum, got %d", w.Code)
+	}
+}
+
+func TestHTTPBatches(t *testing.T) {
+	pool, _ := NewPool(newMemoryRegisters())
+	handler := NewHTTPHandler(pool)
+	w := request(t, handler, "POST", batchWritePath, `{"writes": [
+		{"key": "k1", "value": "v1"},
+		{"key": "k2", "value": "v2", "ttl": "1m"},
+		{"key": "k3", "delete": true},
+		{"key": "k4"}]}`)
+	response := &batchResponse{}
+	json.Unmarshal(w.Body.Bytes(), response)
+	if w.Code != http.StatusOK || len(response.Registers) != 4 {
+		t.Fatalf("batch write failed: %d %s", w.Code, w.Body)
+	}
+	for i, status := range []int{http.StatusOK, http.StatusOK, http.StatusNoContent, http.StatusBadRequest} {
+		if response.Registers[i].Status != status {
+			t.Errorf("write %d: expected status %d, got %+v", i, status, response.Registers[i])
+		}
+	}
+
+	w = request(t, handler, "POST", batchGetPath, `{"keys": ["k1", "k2", "k3", "missing"]}`)
+	response = &batchResponse{}
+	json.Unmarshal(w.Body.Bytes(), response)
+	if len(response.Registers) != 4 || *response.Registers[0].Value != "v1" || *response.Registers[1].Value != "v2" {
+		t.Fatalf("batch read failed: %s", w.Body)
+	}
+	if response.Registers[2].Status != http.StatusNotFound || response.Registers[3].Status != http.StatusNotFound {
+		t.Errorf("expected the deleted and missing keys to be 404: %s", w.Body)
+	}
+	if w := request(t, handler, "GET", batchGetPath, ""); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
@@ -0,0 +1,68 @@
+// Package gateway
+// serves the registers to programs that can't use the Go client library: every front-end translates
+// its protocol into operations of a pool of protocol.SharedRegisterClient, so callers get the same
+// atomic registers without implementing ABD themselves.
+package gateway
+
+import (
+	"errors"
+	"shared-registers/client/protocol"
+	"shared-registers/common/proto"
+	"sync"
+	"time"
+)
+
+// Registers is the part of protocol.SharedRegisterClient the front-ends use
+type Registers interface {
+	ReadValue(key string) (*proto.StoredValue, error)
+	WriteBytesWithTimeStamp(key string, value []byte, contentType string, ttl time.Duration) (*proto.TimeStamp, error)
+	Delete(key string) error
+	Scan(start, end string, limit int, pageToken string) ([]*proto.KeyValue, string, error)
+	ScanPrefix(prefix string, limit int, pageToken string) ([]*proto.KeyValue, string, error)
+}
+
+var _ Registers = (*protocol.SharedRegisterClient)(nil)
+
+// Pool
+// hands each operation a client of its own, since a SharedRegisterClient runs its operations one at
+// a time: the pool runs as many operations concurrently as it has clients, which need distinct
+// client IDs.
+type Pool struct {
+	clients chan Registers
+}
+
+func NewPool(clients ...Registers) (*Pool, error) {
+	if len(clients) == 0 {
+		return nil, errors.New("a pool needs at least one client")
+	}
+	p := &Pool{clients: make(chan Registers, len(clients))}
+	for _, c := range clients {
+		p.clients <- c
+	}
+	return p, nil
+}
+
+// Size returns the number of operations the pool runs concurrently
+func (p *Pool) Size() int {
+	return cap(p.clients)
+}
+
+// Do runs fn with a client, waiting for one to be free
+func (p *Pool) Do(fn func(c Registers) error) error {
+	c := <-p.clients
+	defer func() { p.clients <- c }()
+	return fn(c)
+}
+
+// parallel runs op for 0..n-1 concurrently, operations on a pool wait for its clients
+func parallel(n int, op func(i int)) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			op(i)
+		}(i)
+	}
+	wg.Wait()
+}
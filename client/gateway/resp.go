@@ -0,0 +1,584 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"shared-registers/client/protocol"
+	"shared-registers/common/proto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxArrayLength is the most arguments a command may have
+	maxArrayLength = 1 << 20
+	// maxCursors is the most SCAN cursors a connection keeps, older ones become invalid
+	maxCursors = 1024
+	// defaultScanCount is the number of keys a SCAN looks at without COUNT, as in Redis
+	defaultScanCount = 10
+)
+
+// errProtocol closes the connection, the commands can't be told apart anymore
+var errProtocol = errors.New("Protocol error")
+
+// RESPServer
+// serves the registers to Redis clients over RESP2, or RESP3 after HELLO 3. Each key is an atomic
+// register holding a string: GET, SET (with EX or PX), DEL, EXISTS, MGET, MSET and SCAN (with MATCH,
+// COUNT and TYPE) map onto the operations of the pool, plus the connection commands clients send on
+// their own. Commands on several keys run concurrently on the pool, each key is atomic on its own
+// but MSET and DEL are not atomic as a whole. CLUSTERDOWN errors mean too few replicas answered in
+// time, so a write may or may not have taken effect.
+type RESPServer struct {
+	pool        *Pool
+	MaxBulkSize int64 // largest argument accepted, in bytes
+}
+
+func NewRESPServer(pool *Pool) *RESPServer {
+	return &RESPServer{pool: pool, MaxBulkSize: DefaultMaxBodySize}
+}
+
+// Serve accepts connections on lis until it fails
+func (s *RESPServer) Serve(lis net.Listener) error {
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			return err
+		}
+		go s.ServeConn(conn)
+	}
+}
+
+// respConn is the state of a client connection
+type respConn struct {
+	r       *bufio.Reader
+	w       *respWriter
+	cursors map[uint64]string // SCAN cursors by the page tokens they stand for
+	cursor  uint64            // last cursor handed out
+}
+
+// ServeConn runs the commands of the connection until it is closed, replying in order
+func (s *RESPServer) ServeConn(conn net.Conn) {
+	defer conn.Close()
+	// a bug triggered by one client must not take down the gateway
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("RESP connection from %v: panic: %v", conn.RemoteAddr(), r)
+		}
+	}()
+	c := &respConn{r: bufio.NewReaderSize(conn, 64<<10), w: &respWriter{w: bufio.NewWriter(conn)},
+		cursors: make(map[uint64]string)}
+	for {
+		args, err := readCommand(c.r, s.MaxBulkSize)
+		if err != nil {
+			if errors.Is(err, errProtocol) {
+				c.w.error("ERR " + err.Error())
+				c.w.w.Flush()
+			} else if err != io.EOF {
+				log.Printf("RESP connection from %v: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if quit := s.run(c, args); quit {
+			c.w.w.Flush()
+			return
+		}
+		// answer pipelined commands together
+		if c.r.Buffered() == 0 {
+			if err := c.w.w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// run runs the command and writes its reply, returning whether the connection is to be closed
+func (s *RESPServer) run(c *respConn, args [][]byte) bool {
+	name := strings.ToUpper(string(args[0]))
+	args = args[1:]
+	arity := map[string]int{"GET": 1, "ECHO": 1, "SELECT": 1}
+	if n, ok := arity[name]; ok && len(args) != n {
+		c.w.error("ERR wrong number of arguments for '" + strings.ToLower(name) + "' command")
+		return false
+	}
+	switch name {
+	case "PING":
+		if len(args) > 0 {
+			c.w.bulk(args[0])
+		} else {
+			c.w.simple("PONG")
+		}
+	case "ECHO":
+		c.w.bulk(args[0])
+	case "QUIT":
+		c.w.simple("OK")
+		return true
+	case "HELLO":
+		s.hello(c, args)
+	case "SELECT":
+		if string(args[0]) != "0" {
+			c.w.error("ERR DB index is out of range")
+		} else {
+			c.w.simple("OK")
+		}
+	case "CLIENT":
+		// clients set their name and library on connecting, there is nothing to keep
+		c.w.simple("OK")
+	case "COMMAND":
+		c.w.array(0)
+	case "AUTH":
+		c.w.error("ERR AUTH is not supported, the gateway authenticates to the replicas itself")
+	case "GET":
+		value, err := s.get(string(args[0]))
+		if err != nil {
+			c.w.error(errorReply(err))
+		} else {
+			c.w.bulkOrNull(value)
+		}
+	case "MGET":
+		if len(args) == 0 {
+			c.w.error("ERR wrong number of arguments for 'mget' command")
+			return false
+		}
+		values, errs := make([][]byte, len(args)), make([]error, len(args))
+		parallel(len(args), func(i int) {
+			values[i], errs[i] = s.get(string(args[i]))
+		})
+		for _, err := range errs {
+			if err != nil {
+				c.w.error(errorReply(err))
+				return false
+			}
+		}
+		c.w.array(len(values))
+		for _, v := range values {
+			c.w.bulkOrNull(v)
+		}
+	case "SET":
+		s.set(c, args)
+	case "MSET":
+		if len(args) == 0 || len(args)%2 != 0 {
+			c.w.error("ERR wrong number of arguments for 'mset' command")
+			return false
+		}
+		errs := make([]error, len(args)/2)
+		parallel(len(errs), func(i int) {
+			errs[i] = s.write(string(args[2*i]), args[2*i+1], 0)
+		})
+		for _, err := range errs {
+			if err != nil {
+				c.w.error(errorReply(err))
+				return false
+			}
+		}
+		c.w.simple("OK")
+	case "DEL", "UNLINK", "EXISTS":
+		if len(args) == 0 {
+			c.w.error("ERR wrong number of arguments for '" + strings.ToLower(name) + "' command")
+			return false
+		}
+		found, errs := make([]bool, len(args)), make([]error, len(args))
+		parallel(len(args), func(i int) {
+			found[i], errs[i] = s.exists(string(args[i]), name != "EXISTS")
+		})
+		n := 0
+		for i, err := range errs {
+			if err != nil {
+				c.w.error(errorReply(err))
+				return false
+			}
+			if found[i] {
+				n++
+			}
+		}
+		c.w.integer(int64(n))
+	case "SCAN":
+		s.scan(c, args)
+	default:
+		c.w.error("ERR unknown command '" + strings.ToLower(name) + "'")
+	}
+	return false
+}
+
+// hello switches the protocol version and describes the server
+func (s *RESPServer) hello(c *respConn, args [][]byte) {
+	if len(args) > 0 {
+		version, err := strconv.Atoi(string(args[0]))
+		if err != nil || (version != 2 && version != 3) {
+			c.w.error("NOPROTO unsupported protocol version")
+			return
+		}
+		for i := 1; i < len(args); i++ {
+			if strings.EqualFold(string(args[i]), "AUTH") {
+				c.w.error("ERR AUTH is not supported, the gateway authenticates to the replicas itself")
+				return
+			}
+		}
+		c.w.resp3 = version == 3
+	}
+	version := int64(2)
+	if c.w.resp3 {
+		version = 3
+	}
+	c.w.mapHeader(7)
+	c.w.bulk([]byte("server"))
+	c.w.bulk([]byte("shared-registers"))
+	c.w.bulk([]byte("version"))
+	c.w.bulk([]byte("1.0.0"))
+	c.w.bulk([]byte("proto"))
+	c.w.integer(version)
+	c.w.bulk([]byte("id"))
+	c.w.integer(0)
+	c.w.bulk([]byte("mode"))
+	c.w.bulk([]byte("standalone"))
+	c.w.bulk([]byte("role"))
+	c.w.bulk([]byte("master"))
+	c.w.bulk([]byte("modules"))
+	c.w.array(0)
+}
+
+// set runs SET key value [EX seconds | PX milliseconds]
+func (s *RESPServer) set(c *respConn, args [][]byte) {
+	if len(args) < 2 {
+		c.w.error("ERR wrong number of arguments for 'set' command")
+		return
+	}
+	var ttl time.Duration
+	for i := 2; i < len(args); i++ {
+		option := strings.ToUpper(string(args[i]))
+		if option != "EX" && option != "PX" {
+			c.w.error("ERR syntax error, only the EX and PX options are supported")
+			return
+		}
+		if i+1 >= len(args) || ttl != 0 {
+			c.w.error("ERR syntax error")
+			return
+		}
+		unit := time.Millisecond
+		if option == "EX" {
+			unit = time.Second
+		}
+		n, err := strconv.ParseInt(string(args[i+1]), 10, 64)
+		if err != nil || n <= 0 || n > math.MaxInt64/int64(unit) {
+			c.w.error("ERR invalid expire time in 'set' command")
+			return
+		}
+		ttl = time.Duration(n) * unit
+		i++
+	}
+	if err := s.write(string(args[0]), args[1], ttl); err != nil {
+		c.w.error(errorReply(err))
+		return
+	}
+	c.w.simple("OK")
+}
+
+// scan runs SCAN cursor [MATCH pattern] [COUNT count] [TYPE type]. Redis cursors are numbers, each
+// stands for the page token of the scan, which only lists the keys under the literal prefix of the
+// pattern; the other keys of the page are filtered out.
+func (s *RESPServer) scan(c *respConn, args [][]byte) {
+	if len(args) == 0 || len(args)%2 != 1 {
+		c.w.error("ERR syntax error")
+		return
+	}
+	cursor, err := strconv.ParseUint(string(args[0]), 10, 64)
+	if err != nil {
+		c.w.error("ERR invalid cursor")
+		return
+	}
+	pattern, count, onlyStrings := "*", defaultScanCount, true
+	for i := 1; i < len(args); i += 2 {
+		switch strings.ToUpper(string(args[i])) {
+		case "MATCH":
+			pattern = string(args[i+1])
+		case "COUNT":
+			if count, err = strconv.Atoi(string(args[i+1])); err != nil || count < 1 {
+				c.w.error("ERR value is not an integer or out of range")
+				return
+			}
+		case "TYPE":
+			// every register holds a string
+			onlyStrings = strings.EqualFold(string(args[i+1]), "string")
+		default:
+			c.w.error("ERR syntax error")
+			return
+		}
+	}
+	pageToken := ""
+	if cursor != 0 {
+		var ok bool
+		// a cursor stays valid until maxCursors newer ones evict it, so clients may retry it
+		if pageToken, ok = c.cursors[cursor]; !ok {
+			c.w.error("ERR invalid cursor")
+			return
+		}
+	}
+
+	var entries []*proto.KeyValue
+	var next string
+	err = s.pool.Do(func(r Registers) (err error) {
+		entries, next, err = r.ScanPrefix(globPrefix(pattern), count, pageToken)
+		return err
+	})
+	if err != nil {
+		c.w.error(errorReply(err))
+		return
+	}
+	keys := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if onlyStrings && matchGlob(pattern, e.GetKey()) {
+			keys = append(keys, e.GetKey())
+		}
+	}
+	cursor = 0
+	if next != "" {
+		c.cursor++
+		cursor = c.cursor
+		c.cursors[cursor] = next
+		delete(c.cursors, cursor-maxCursors)
+	}
+	c.w.array(2)
+	c.w.bulk([]byte(strconv.FormatUint(cursor, 10)))
+	c.w.array(len(keys))
+	for _, key := range keys {
+		c.w.bulk([]byte(key))
+	}
+}
+
+// get returns the value of the key, nil if it doesn't exist
+func (s *RESPServer) get(key string) ([]byte, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	var value *proto.StoredValue
+	err := s.pool.Do(func(r Registers) (err error) {
+		value, err = r.ReadValue(key)
+		return err
+	})
+	if errors.Is(err, protocol.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if value.GetVal() == nil {
+		return []byte{}, nil
+	}
+	return value.GetVal(), nil
+}
+
+func (s *RESPServer) write(key string, value []byte, ttl time.Duration) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	return s.pool.Do(func(r Registers) error {
+		_, err := r.WriteBytesWithTimeStamp(key, value, "", ttl)
+		return err
+	})
+}
+
+// exists returns whether the key exists, deleting it if it does and del is set
+func (s *RESPServer) exists(key string, del bool) (bool, error) {
+	value, err := s.get(key)
+	if err != nil || value == nil || !del {
+		return value != nil, err
+	}
+	return true, s.pool.Do(func(r Registers) error { return r.Delete(key) })
+}
+
+// errorReply maps the errors of the registers to Redis errors
+func errorReply(err error) string {
+	if errors.Is(err, protocol.ErrQuorumUnavailable) {
+		return "CLUSTERDOWN " + err.Error()
+	}
+	return "ERR " + err.Error()
+}
+
+// readCommand reads a command as an array of bulk strings, or as an inline command
+func readCommand(r *bufio.Reader, maxBulkSize int64) ([][]byte, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		// the line is only valid until the next read
+		return bytes.Fields(append([]byte(nil), line...)), nil
+	}
+	n, err := strconv.Atoi(string(line[1:]))
+	if err != nil || n > maxArrayLength {
+		return nil, fmt.Errorf("%w: invalid multibulk length", errProtocol)
+	}
+	// like Redis, *0 and negative lengths are empty commands; the arguments are only allocated as
+	// they arrive, a large length alone doesn't take memory
+	var args [][]byte
+	for i := 0; i < n; i++ {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, fmt.Errorf("%w: expected '$'", errProtocol)
+		}
+		size, err := strconv.ParseInt(string(line[1:]), 10, 64)
+		if err != nil || size < 0 || size > maxBulkSize {
+			return nil, fmt.Errorf("%w: invalid bulk length", errProtocol)
+		}
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(r, arg); err != nil {
+			return nil, err
+		}
+		if !bytes.HasSuffix(arg, []byte("\r\n")) {
+			return nil, fmt.Errorf("%w: bulk string not terminated by CRLF", errProtocol)
+		}
+		args = append(args, arg[:size])
+	}
+	return args, nil
+}
+
+// readLine reads a line without its CRLF
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		return nil, fmt.Errorf("%w: too big inline request", errProtocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(line[:len(line)-1], []byte("\r")), nil
+}
+
+// respWriter encodes replies in RESP2, or in RESP3 once the client asked for it
+type respWriter struct {
+	w     *bufio.Writer
+	resp3 bool
+}
+
+func (w *respWriter) simple(s string) {
+	w.w.WriteString("+" + s + "\r\n")
+}
+
+func (w *respWriter) error(s string) {
+	w.w.WriteString("-" + strings.NewReplacer("\r", " ", "\n", " ").Replace(s) + "\r\n")
+}
+
+func (w *respWriter) integer(n int64) {
+	w.w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n")
+}
+
+func (w *respWriter) bulk(b []byte) {
+	w.w.WriteString("$" + strconv.Itoa(len(b)) + "\r\n")
+	w.w.Write(b)
+	w.w.WriteString("\r\n")
+}
+
+// bulkOrNull writes nil as the null reply
+func (w *respWriter) bulkOrNull(b []byte) {
+	switch {
+	case b != nil:
+		w.bulk(b)
+	case w.resp3:
+		w.w.WriteString("_\r\n")
+	default:
+		w.w.WriteString("$-1\r\n")
+	}
+}
+
+func (w *respWriter) array(n int) {
+	w.w.WriteString("*" + strconv.Itoa(n) + "\r\n")
+}
+
+// mapHeader starts a map of n pairs, which RESP2 sends as an array of keys and values
+func (w *respWriter) mapHeader(n int) {
+	if w.resp3 {
+		w.w.WriteString("%" + strconv.Itoa(n) + "\r\n")
+	} else {
+		w.array(2 * n)
+	}
+}
+
+// globPrefix returns the literal prefix of a glob pattern, all the keys matching it start with it
+func globPrefix(pattern string) string {
+	var prefix []byte
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*', '?', '[':
+			return string(prefix)
+		case '\\':
+			if i+1 == len(pattern) {
+				return string(prefix)
+			}
+			i++
+		}
+		prefix = append(prefix, pattern[i])
+	}
+	return string(prefix)
+}
+
+// matchGlob matches s against a Redis glob pattern: * and ? match any bytes and any byte, [abc],
+// [a-z] and [^abc] match classes of bytes, and \ escapes the next byte
+func matchGlob(pattern, s string) bool {
+	if pattern == "" {
+		return s == ""
+	}
+	switch pattern[0] {
+	case '*':
+		for len(pattern) > 1 && pattern[1] == '*' {
+			pattern = pattern[1:]
+		}
+		for i := len(s); i >= 0; i-- {
+			if matchGlob(pattern[1:], s[len(s)-i:]) {
+				return true
+			}
+		}
+		return false
+	case '?':
+		return s != "" && matchGlob(pattern[1:], s[1:])
+	case '[':
+		if s == "" {
+			return false
+		}
+		end := 1
+		if end < len(pattern) && pattern[end] == '^' {
+			end++
+		}
+		matched := false
+		for ; end < len(pattern) && pattern[end] != ']'; end++ {
+			switch {
+			case pattern[end] == '\\' && end+1 < len(pattern):
+				end++
+				matched = matched || pattern[end] == s[0]
+			case end+2 < len(pattern) && pattern[end+1] == '-' && pattern[end+2] != ']':
+				lo, hi := pattern[end], pattern[end+2]
+				if lo > hi {
+					lo, hi = hi, lo
+				}
+				matched = matched || (lo <= s[0] && s[0] <= hi)
+				end += 2
+			default:
+				matched = matched || pattern[end] == s[0]
+			}
+		}
+		if end == len(pattern) {
+			// an unterminated class matches the [ literally, like Redis
+			return s[0] == '[' && matchGlob(pattern[1:], s[1:])
+		}
+		if len(pattern) > 1 && pattern[1] == '^' {
+			matched = !matched
+		}
+		return matched && matchGlob(pattern[end+1:], s[1:])
+	case '\\':
+		if len(pattern) > 1 {
+			pattern = pattern[1:]
+		}
+	}
+	return s != "" && pattern[0] == s[0] && matchGlob(pattern[1:], s[1:])
+}
@@ -0,0 +1,233 @@
+package gateway
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// respClient sends commands to a RESPServer and compares its replies byte by byte
+type respClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func newRESPClient(t *testing.T, server *RESPServer) *respClient {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lis.Close() })
+	go server.Serve(lis)
+	conn, err := net.Dial("tcp", lis.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	return &respClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func encodeCommand(args ...string) string {
+	command := "*" + strconv.Itoa(len(args)) + "\r\n"
+	for _, arg := range args {
+		command += "$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n"
+	}
+	return command
+}
+
+// expect sends the command and checks the reply
+func (c *respClient) expect(reply string, args ...string) {
+	c.t.Helper()
+	c.send(encodeCommand(args...))
+	c.read(reply)
+}
+
+func (c *respClient) send(raw string) {
+	c.t.Helper()
+	if _, err := c.conn.Write([]byte(raw)); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *respClient) read(reply string) {
+	c.t.Helper()
+	got := make([]byte, len(reply))
+	if _, err := io.ReadFull(c.r, got); err != nil {
+		c.t.Fatalf("expected %q, got %q: %v", reply, got, err)
+	}
+	if string(got) != reply {
+		c.t.Fatalf("expected %q, got %q", reply, got)
+	}
+}
+
+func TestRESPCommands(t *testing.T) {
+	registers := newMemoryRegisters()
+	pool, _ := NewPool(registers)
+	c := newRESPClient(t, NewRESPServer(pool))
+
+	c.expect("+PONG\r\n", "PING")
+	c.expect("$-1\r\n", "GET", "k")
+	c.expect("+OK\r\n", "SET", "k", "v")
+	c.expect("$1\r\nv\r\n", "get", "k")
+	c.expect("+OK\r\n", "SET", "empty", "")
+	c.expect("$0\r\n\r\n", "GET", "empty")
+	c.expect("+OK\r\n", "SET", "ttl", "v", "EX", "60")
+	c.expect("-ERR syntax error, only the EX and PX options are supported\r\n", "SET", "k", "v", "NX")
+	c.expect("-ERR invalid expire time in 'set' command\r\n", "SET", "k", "v", "PX", "0")
+
+	c.expect("+OK\r\n", "MSET", "a", "1", "b", "2")
+	c.expect("*3\r\n$1\r\n1\r\n$-1\r\n$1\r\n2\r\n", "MGET", "a", "missing", "b")
+	c.expect(":3\r\n", "EXISTS", "a", "a", "b", "missing")
+	c.expect(":2\r\n", "DEL", "a", "b", "missing")
+	c.expect(":0\r\n", "EXISTS", "a", "b")
+
+	c.expect("-ERR wrong number of arguments for 'get' command\r\n", "GET")
+	c.expect("-ERR unknown command 'flushall'\r\n", "FLUSHALL")
+	c.expect("-ERR keys starting with \"\\x00\" are reserved\r\n", "GET", "\x00chunk")
+
+	// pipelined and inline commands
+	c.send(encodeCommand("SET", "p", "1") + encodeCommand("GET", "p") + "PING\r\n")
+	c.read("+OK\r\n$1\r\n1\r\n+PONG\r\n")
+
+	// RESP3 has a null of its own and maps
+	c.expect("%7\r\n$6\r\nserver\r\n$16\r\nshared-registers\r\n$7\r\nversion\r\n$5\r\n1.0.0\r\n"+
+		"$5\r\nproto\r\n:3\r\n$2\r\nid\r\n:0\r\n$4\r\nmode\r\n$10\r\nstandalone\r\n"+
+		"$4\r\nrole\r\n$6\r\nmaster\r\n$7\r\nmodules\r\n*0\r\n", "HELLO", "3")
+	c.expect("_\r\n", "GET", "missing")
+	c.expect("-NOPROTO unsupported protocol version\r\n", "HELLO", "4")
+
+	// PX takes milliseconds, bounded by the largest duration
+	c.expect("+OK\r\n", "SET", "px", "v", "PX", "9223372036854")
+	c.expect("-ERR invalid expire time in 'set' command\r\n", "SET", "px", "v", "PX", "9223372036855")
+
+	registers.down = true
+	c.expect("-CLUSTERDOWN completeGetPhase timeout\r\n", "GET", "k")
+	c.expect("-CLUSTERDOWN completeSetPhase timeout\r\n", "SET", "k", "v")
+}
+
+func TestRESPScan(t *testing.T) {
+	registers := newMemoryRegisters()
+	pool, _ := NewPool(registers)
+	for _, key := range []string{"user:1", "user:2", "user:3", "user:10", "other:1", "user:x"} {
+		registers.WriteBytesWithTimeStamp(key, []byte("v"), "", 0)
+	}
+	c := newRESPClient(t, NewRESPServer(pool))
+
+	// page through the keys, the cursor is 0 once they are exhausted
+	found := []string{}
+	cursor := "0"
+	for i := 0; ; i++ {
+		if i > 10 {
+			t.Fatal("the scan doesn't end")
+		}
+		c.send(encodeCommand("SCAN", cursor, "MATCH", "user:[0-9]*", "COUNT", "2"))
+		c.read("*2\r\n")
+		cursor = readBulk(t, c.r)
+		c.read("*")
+		n, _ := strconv.Atoi(strings.TrimSpace(readLine2(t, c.r)))
+		for j := 0; j < n; j++ {
+			found = append(found, readBulk(t, c.r))
+		}
+		if cursor == "0" {
+			break
+		}
+	}
+	if strings.Join(found, ",") != "user:1,user:10,user:2,user:3" {
+		t.Errorf("unexpected keys %v", found)
+	}
+	// a cursor can be retried, e.g. after a timeout
+	c.send(encodeCommand("SCAN", "0", "MATCH", "user:[0-9]*", "COUNT", "2"))
+	c.read("*2\r\n")
+	first := readBulk(t, c.r)
+	c.read("*2\r\n$6\r\nuser:1\r\n$7\r\nuser:10\r\n")
+	for i := 0; i < 2; i++ {
+		c.send(encodeCommand("SCAN", first, "MATCH", "user:[0-9]*", "COUNT", "2"))
+		c.read("*2\r\n")
+		readBulk(t, c.r)
+		c.read("*2\r\n$6\r\nuser:2\r\n$6\r\nuser:3\r\n")
+	}
+	c.expect("-ERR invalid cursor\r\n", "SCAN", "12345")
+	c.expect("*2\r\n$1\r\n0\r\n*0\r\n", "SCAN", "0", "TYPE", "hash")
+}
+
+func TestRESPMalformedCommands(t *testing.T) {
+	pool, _ := NewPool(newMemoryRegisters())
+	server := NewRESPServer(pool)
+
+	// empty and negative multibulk lengths are empty commands, as in Redis
+	c := newRESPClient(t, server)
+	c.send("*-1\r\n*0\r\n*-2147483648\r\n")
+	c.expect("+PONG\r\n", "PING")
+
+	for _, header := range []string{"*abc\r\n", "*9999999999\r\n", "*1\r\n$-1\r\n", "*1\r\n+PING\r\n",
+		"*1\r\n$4\r\nPINGxx\r\n", "*1\r\n$99999999999999999999\r\n"} {
+		c := newRESPClient(t, server)
+		c.send(header)
+		c.read("-ERR Protocol error: ")
+		// the connection is closed after the error
+		if _, err := io.ReadAll(c.r); err != nil {
+			t.Errorf("%q: %v", header, err)
+		}
+	}
+	// a huge length doesn't allocate before the arguments arrive, the connection just ends
+	c = newRESPClient(t, server)
+	c.send("*1048576\r\n$4\r\nPING\r\n")
+	c.conn.(*net.TCPConn).CloseWrite()
+	if rest, err := io.ReadAll(c.r); err != nil || len(rest) != 0 {
+		t.Errorf("expected the connection to end silently, got %q, %v", rest, err)
+	}
+	// the server still serves other connections
+	newRESPClient(t, server).expect("+PONG\r\n", "PING")
+}
+
+func readLine2(t *testing.T, r *bufio.Reader) string {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSuffix(line, "\r\n")
+}
+
+func readBulk(t *testing.T, r *bufio.Reader) string {
+	header := readLine2(t, r)
+	if !strings.HasPrefix(header, "$") {
+		t.Fatalf("expected a bulk string, got %q", header)
+	}
+	return readLine2(t, r)
+}
+
+func TestMatchGlob(t *testing.T) {
+	for _, c := range []struct {
+		pattern, s string
+		match      bool
+	}{
+		{"*", "anything", true},
+		{"user:*", "user:1", true},
+		{"user:*", "users", false},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-b]llo", "hbllo", true},
+		{"a\\*b", "a*b", true},
+		{"a\\*b", "axb", false},
+		{"**x", "abx", true},
+		{"a*b*c", "aXbYc", true},
+		{"a*b*c", "aXbY", false},
+	} {
+		if matchGlob(c.pattern, c.s) != c.match {
+			t.Errorf("matchGlob(%q, %q) != %v", c.pattern, c.s, c.match)
+		}
+	}
+	if p := globPrefix("user:\\*[0-9]*"); p != "user:*" {
+		t.Errorf("unexpected prefix %q", p)
+	}
+}
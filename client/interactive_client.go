@@ -2,21 +2,46 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"shared-registers/client/protocol"
+	"shared-registers/client/util"
+	"shared-registers/common"
 	"strconv"
 	"strings"
+	"time"
 )
 
-var client *protocol.SharedRegisterClient
+var (
+	client *protocol.SharedRegisterClient
 
-func init() {
+	configFile = flag.String("config", "./config.txt", "file with one replica address per line")
+	useHLC     = flag.Bool("hlc", false, "timestamp writes with a hybrid logical clock")
+	maxOffset  = flag.Duration("hlc-max-offset", 0, "reject timestamps this far ahead of the local clock, 0 to disable")
+	idLease    = flag.Duration("client-lease", 10*time.Second, "lease of the client ID registration on the replicas, 0 to skip registering")
+	wireGzip   = flag.Bool("wire-compression", false, "gzip the requests to the replicas and their responses")
+	gzipAbove  = flag.Int("compress-threshold", 0, "store values of at least this many bytes gzip-compressed, 0 to disable")
+	useTLS     = flag.Bool("tls", false, "connect to the replicas over TLS, implied by -tls-ca and -tls-cert")
+	tlsCA      = flag.String("tls-ca", "", "CA bundle to verify the replicas with instead of the system roots")
+	tlsCert    = flag.String("tls-cert", "", "PEM client certificate for replicas requiring mutual TLS, reloaded when it changes")
+	tlsKey     = flag.String("tls-key", "", "PEM private key of -tls-cert")
+	tokenFile  = flag.String("token-file", "", "file with the bearer token to authenticate to replicas with an ACL, needs TLS")
+	valueKeys  = flag.String("value-key-file", "", "key file to encrypt values end to end with, see common.ParseKeyFile; the replicas only see ciphertext")
+	idPrefix   = flag.String("client-id-prefix", "", "start the client ID with this instead of the hostname and pid, e.g. to match the client IDs an ACL allows")
+)
+
+func setUpClient() {
+	// hostname and pid are kept for readability, the random part makes the ID unique
 	hostname, _ := os.Hostname()
-	myClientId := hostname + strconv.Itoa(os.Getpid())
+	myClientId := hostname + "-" + strconv.Itoa(os.Getpid()) + "-" + protocol.NewClientID()
+	if *idPrefix != "" {
+		myClientId = *idPrefix + protocol.NewClientID()
+	}
 
-	file, err := os.Open("./config.txt")
+	file, err := os.Open(*configFile)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -32,10 +57,49 @@ func init() {
 		log.Fatal(err)
 	}
 
-	client, err = protocol.CreateSharedRegisterClient(myClientId, addrs)
+	var options protocol.ConnOptions
+	if *useTLS || *tlsCA != "" || *tlsCert != "" {
+		options.TLS, err = common.ClientTLSConfig(common.TLSFiles{CertFile: *tlsCert, KeyFile: *tlsKey, CAFile: *tlsCA})
+		if err != nil {
+			log.Fatal("ClientTLSConfig: ", err)
+		}
+	}
+	if *tokenFile != "" {
+		token, err := os.ReadFile(*tokenFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options.Token = strings.TrimSpace(string(token))
+	}
+	client, err = protocol.CreateSharedRegisterClientWithOptions(myClientId, addrs, options)
 	if err != nil {
 		log.Fatal("CreateSharedRegisterClient: ", err)
 	}
+	if *useHLC {
+		client.EnableHybridClock(*maxOffset)
+	}
+	if *wireGzip {
+		client.EnableWireCompression()
+	}
+	client.CompressionThreshold = *gzipAbove
+	if *valueKeys != "" {
+		data, err := os.ReadFile(*valueKeys)
+		if err != nil {
+			log.Fatal(err)
+		}
+		primary, keys, err := common.ParseKeyFile(data)
+		if err != nil {
+			log.Fatal("ParseKeyFile: ", err)
+		}
+		if err := client.EnableEncryption(primary, keys); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *idLease > 0 {
+		if err := client.RegisterClientID(*idLease); err != nil {
+			log.Fatal("RegisterClientID: ", err)
+		}
+	}
 }
 
 func execBatchOperations(fileName, resultFilename string) {
@@ -60,33 +124,22 @@ func execBatchOperations(fileName, resultFilename string) {
 	// for each operation in the batch file
 	for scanner.Scan() {
 		inputLine := scanner.Text()
-		operationFileds := strings.Fields(inputLine)
+		operationFileds, err := util.SplitCommand(inputLine)
+		if err != nil {
+			fmt.Printf("Error when parsing line %d: %v\n", lineNumber, err)
+			fmt.Println(inputLine)
+			os.Exit(1)
+		}
 		var result = ""
-		switch len(operationFileds) {
-		case 2:
-			if strings.EqualFold(operationFileds[0], "R") {
-				key := operationFileds[1]
-				resultValue, err := client.Read(key)
-				if err != nil {
-					result = err.Error()
-				} else {
-					result = "READ\tKey=" + key + "\tValue=" + resultValue
-				}
-			} else {
-				fmt.Printf("Error when parsing line %d\n", lineNumber)
-				fmt.Println(inputLine)
-				os.Exit(1)
-			}
-		case 3:
-			if strings.EqualFold(operationFileds[0], "W") {
-				key, value := operationFileds[1], operationFileds[2]
-				err = client.Write(key, value)
-				if err != nil {
-					log.Fatal("Client Write err: ", err)
-				}
-				result = "WRITE\tKey=" + key + "\tValue=" + value
-			} else {
-				fmt.Printf("Error when parsing line %d\n", lineNumber)
+		switch {
+		case len(operationFileds) == 2 && strings.EqualFold(operationFileds[0].Text, "R"):
+			result = readKey(operationFileds[1].Text)
+		case len(operationFileds) == 2 && strings.EqualFold(operationFileds[0].Text, "D"):
+			result = deleteKey(operationFileds[1].Text)
+		case (len(operationFileds) == 3 || len(operationFileds) == 4) && strings.EqualFold(operationFileds[0].Text, "W"):
+			result, err = writeKey(operationFileds)
+			if err != nil {
+				fmt.Printf("Error when parsing line %d: %v\n", lineNumber, err)
 				fmt.Println(inputLine)
 				os.Exit(1)
 			}
@@ -99,7 +152,7 @@ func execBatchOperations(fileName, resultFilename string) {
 		}
 
 		// write result into file
-		_, err := resultWriter.WriteString(result + "\n")
+		_, err = resultWriter.WriteString(result + "\n")
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -115,43 +168,156 @@ func execBatchOperations(fileName, resultFilename string) {
 	}
 }
 
+// readKey reads the key and prints the value so that it can be pasted back into a W command
+func readKey(key string) string {
+	value, contentType, err := client.ReadBytes(key)
+	if err != nil {
+		return err.Error()
+	}
+	result := "READ\tKey=" + key + "\tValue=" + util.FormatValue(value)
+	if contentType != "" {
+		result += "\tContentType=" + contentType
+	}
+	return result
+}
+
+// writeKey runs W [key] [value] [contentType], only a malformed value literal is returned as error
+func writeKey(operationFileds []util.Token) (string, error) {
+	key := operationFileds[1].Text
+	value, err := operationFileds[2].Value()
+	if err != nil {
+		return "", err
+	}
+	contentType := ""
+	if len(operationFileds) == 4 {
+		contentType = operationFileds[3].Text
+	}
+	err = client.WriteBytes(key, value, contentType)
+	if err != nil {
+		log.Fatal("Client Write err: ", err)
+	}
+	return "WRITE\tKey=" + key + "\tValue=" + util.FormatValue(value), nil
+}
+
+// writeKeyWithTTL runs WT [key] [value] [ttl], ttl is a duration like 30s or 5m
+func writeKeyWithTTL(operationFileds []util.Token) (string, error) {
+	key := operationFileds[1].Text
+	value, err := operationFileds[2].Value()
+	if err != nil {
+		return "", err
+	}
+	ttl, err := time.ParseDuration(operationFileds[3].Text)
+	if err != nil || ttl <= 0 {
+		return "", errors.New("ttl must be a positive duration, e.g. 30s")
+	}
+	err = client.WriteBytesWithTTL(key, value, "", ttl)
+	if err != nil {
+		log.Fatal("Client Write err: ", err)
+	}
+	return "WRITE\tKey=" + key + "\tValue=" + util.FormatValue(value) + "\tTTL=" + ttl.String(), nil
+}
+
+// compareAndSwap runs CAS [key] [expected] [new]
+func compareAndSwap(key, expected, newValue string) string {
+	swapped, err := client.CompareAndSwap(key, expected, newValue)
+	if err != nil {
+		return err.Error()
+	}
+	return "CAS\tKey=" + key + "\tSwapped=" + strconv.FormatBool(swapped)
+}
+
+// deleteKey runs D [key]
+func deleteKey(key string) string {
+	if err := client.Delete(key); err != nil {
+		return err.Error()
+	}
+	return "DELETE\tKey=" + key
+}
+
+// scanPrefix runs SCAN [prefix] and prints every key under the prefix, one line per key
+func scanPrefix(prefix string) string {
+	lines := make([]string, 0)
+	pageToken := ""
+	for {
+		entries, next, err := client.ScanPrefix(prefix, 0, pageToken)
+		if err != nil {
+			return err.Error()
+		}
+		for _, e := range entries {
+			lines = append(lines, "SCAN\tKey="+util.FormatValue([]byte(e.GetKey()))+"\tValue="+util.FormatValue(e.GetValue().GetVal()))
+		}
+		if next == "" {
+			return strings.Join(lines, "\n")
+		}
+		pageToken = next
+	}
+}
+
+// readTimeStamp prints the value of the key along with its timestamp and approximate write time
+func readTimeStamp(key string) string {
+	value, ts, err := client.ReadWithTimeStamp(key)
+	if err != nil {
+		return err.Error()
+	}
+	result := "READ\tKey=" + key + "\tValue=" + util.FormatValue([]byte(value)) +
+		"\tRequestNumber=" + strconv.FormatUint(ts.GetRequestNumber(), 10) + "\tClientID=" + ts.GetClientID()
+	if writeTime := common.WallTime(ts); !writeTime.IsZero() {
+		result += "\tWritten=" + writeTime.Format(time.RFC3339Nano)
+	}
+	return result
+}
+
 func main() {
+	flag.Parse()
+	setUpClient()
+
 	fmt.Println("Usage:")
 	fmt.Println("R [key]")
-	fmt.Println("W [key] [value]")
+	fmt.Println("W [key] [value] [contentType]")
+	fmt.Println("  value: word, \"quoted\\tstring\", 'raw string', hex:00ff, base64:AP8=, @filepath")
+	fmt.Println("WT [key] [value] [ttl]")
+	fmt.Println("D [key]")
+	fmt.Println("CAS [key] [expected] [new]")
+	fmt.Println("TS [key]")
+	fmt.Println("SCAN [prefix]")
 	fmt.Println("EXEC [filepath] [resultFilepath]")
 
 	// read commands from the console
 	scanner := bufio.NewScanner(os.Stdin)
 	for scanner.Scan() {
-		operationFileds := strings.Fields(scanner.Text())
+		operationFileds, err := util.SplitCommand(scanner.Text())
+		if err != nil {
+			fmt.Println("Invalid Operation!", err)
+			continue
+		}
 		var result = ""
-		switch len(operationFileds) {
-		case 2:
-			if strings.EqualFold(operationFileds[0], "R") {
-				key := operationFileds[1]
-				resultValue, err := client.Read(key)
-				if err != nil {
-					result = err.Error()
-				} else {
-					result = "READ\tKey=" + key + "\tValue=" + resultValue
-				}
-			} else {
-				fmt.Println("Invalid Operation!")
+		switch {
+		case len(operationFileds) == 2 && strings.EqualFold(operationFileds[0].Text, "R"):
+			result = readKey(operationFileds[1].Text)
+		case len(operationFileds) == 2 && strings.EqualFold(operationFileds[0].Text, "D"):
+			result = deleteKey(operationFileds[1].Text)
+		case (len(operationFileds) == 1 || len(operationFileds) == 2) && strings.EqualFold(operationFileds[0].Text, "SCAN"):
+			prefix := ""
+			if len(operationFileds) == 2 {
+				prefix = operationFileds[1].Text
 			}
-		case 3:
-			if strings.EqualFold(operationFileds[0], "W") {
-				key, value := operationFileds[1], operationFileds[2]
-				err := client.Write(key, value)
-				if err != nil {
-					log.Fatal("Client Write err: ", err)
-				}
-				result = "WRITE\tKey=" + key + "\tValue=" + value
-			} else if strings.EqualFold(operationFileds[0], "EXEC") {
-				execBatchOperations(operationFileds[1], operationFileds[2])
-			} else {
-				fmt.Println("Invalid Operation!")
+			result = scanPrefix(prefix)
+		case len(operationFileds) == 2 && strings.EqualFold(operationFileds[0].Text, "TS"):
+			result = readTimeStamp(operationFileds[1].Text)
+		case (len(operationFileds) == 3 || len(operationFileds) == 4) && strings.EqualFold(operationFileds[0].Text, "W"):
+			result, err = writeKey(operationFileds)
+			if err != nil {
+				fmt.Println("Invalid Operation!", err)
+			}
+		case len(operationFileds) == 4 && strings.EqualFold(operationFileds[0].Text, "WT"):
+			result, err = writeKeyWithTTL(operationFileds)
+			if err != nil {
+				fmt.Println("Invalid Operation!", err)
 			}
+		case len(operationFileds) == 4 && strings.EqualFold(operationFileds[0].Text, "CAS"):
+			result = compareAndSwap(operationFileds[1].Text, operationFileds[2].Text, operationFileds[3].Text)
+		case len(operationFileds) == 3 && strings.EqualFold(operationFileds[0].Text, "EXEC"):
+			execBatchOperations(operationFileds[1].Text, operationFileds[2].Text)
 		default:
 			{
 				fmt.Println("Invalid Operation!")
@@ -0,0 +1,285 @@
+package mutex
+
+import (
+	"context"
+	"errors"
+	"log"
+	"shared-registers/client/protocol"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultPollInterval = 50 * time.Millisecond
+
+	// keyPrefix is where the registers of the locks live, one per lock and participant
+	keyPrefix = "mutex/"
+)
+
+var (
+	ErrNotParticipant = errors.New("not a participant of the lock")
+	ErrLocked         = errors.New("mutex is already locked")
+	ErrNotLocked      = errors.New("mutex is not locked")
+)
+
+// Client is the part of protocol.SharedRegisterClient the bakery needs
+type Client interface {
+	Write(key string, value string) error
+	WriteWithTTL(key string, value string, ttl time.Duration) error
+	Read(key string) (string, error)
+}
+
+var _ Client = (*protocol.SharedRegisterClient)(nil)
+
+// ticket is the content of a participant's register: whether it is choosing a number, and the
+// number it holds, 0 when it neither holds nor waits for the lock
+type ticket struct {
+	choosing bool
+	number   uint64
+}
+
+func (t ticket) String() string {
+	choosing := "0"
+	if t.choosing {
+		choosing = "1"
+	}
+	return choosing + "," + strconv.FormatUint(t.number, 10)
+}
+
+func parseTicket(value string) (ticket, error) {
+	choosing, number, ok := strings.Cut(value, ",")
+	if !ok || (choosing != "0" && choosing != "1") {
+		return ticket{}, errors.New("malformed ticket " + strconv.Quote(value))
+	}
+	n, err := strconv.ParseUint(number, 10, 64)
+	if err != nil {
+		return ticket{}, errors.New("malformed ticket " + strconv.Quote(value))
+	}
+	return ticket{choosing: choosing == "1", number: n}, nil
+}
+
+// Mutex
+// Lamport's bakery algorithm over the shared registers: a named lock shared by a fixed list of
+// participants, each with its own Mutex and the only writer of its register. Since the registers are
+// atomic, the bakery's mutual exclusion and first-come-first-served order hold as long as no
+// participant crashes holding a number. Without a Lease such a participant blocks the lock until it
+// comes back and unlocks; with a Lease its register expires and the others move on, at the price of
+// the usual lease caveat: a holder that can't renew for a whole Lease has lost the lock, see Held.
+// A Mutex is not reentrant and must not be locked by two goroutines at once.
+type Mutex struct {
+	// PollInterval is how long Lock waits before reading the register of a busy participant again
+	PollInterval time.Duration
+	// Lease, if set, is the TTL of the participant's register, renewed every Lease/3 from Lock
+	// until Unlock. It has to be set before Lock and be the same for every participant.
+	Lease time.Duration
+
+	client       Client
+	name         string
+	participants []string
+	self         int
+
+	lock        sync.Mutex // guards the fields below, held while writing the register
+	held        bool
+	current     ticket
+	renewed     time.Time // start of the last successful write of the register
+	stopRenewal chan struct{}
+}
+
+// New
+// create the handle of participant self on the lock, participants must be the same list, in the
+// same order, for every participant since ties between equal numbers go to the earlier one
+func New(client Client, name string, participants []string, self string) (*Mutex, error) {
+	if name == "" || strings.Contains(name, "/") {
+		return nil, errors.New("lock name must be non-empty and can't contain /")
+	}
+	index := -1
+	seen := make(map[string]bool)
+	for i, p := range participants {
+		if p == "" || seen[p] {
+			return nil, errors.New("participants must be unique and non-empty")
+		}
+		seen[p] = true
+		if p == self {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, ErrNotParticipant
+	}
+	return &Mutex{
+		PollInterval: DefaultPollInterval,
+		client:       client,
+		name:         name,
+		participants: participants,
+		self:         index,
+	}, nil
+}
+
+func (m *Mutex) key(participant int) string {
+	return keyPrefix + m.name + "/" + m.participants[participant]
+}
+
+// Lock
+// 1. doorway: announce choosing, take a number larger than every number read, stop choosing
+// 2. for every other participant, wait until it isn't choosing, then until it holds no number or
+// a larger one (ties go to the earlier participant)
+// If ctx is done first, the number is given up and ctx.Err() returned.
+func (m *Mutex) Lock(ctx context.Context) error {
+	m.lock.Lock()
+	if m.held || m.stopRenewal != nil {
+		m.lock.Unlock()
+		return ErrLocked
+	}
+	if m.Lease > 0 {
+		m.stopRenewal = make(chan struct{})
+		go m.renew(m.stopRenewal)
+	}
+	m.lock.Unlock()
+
+	if err := m.lockBakery(ctx); err != nil {
+		m.release()
+		return err
+	}
+	m.lock.Lock()
+	m.held = true
+	m.lock.Unlock()
+	return nil
+}
+
+func (m *Mutex) lockBakery(ctx context.Context) error {
+	if err := m.writeTicket(ticket{choosing: true}); err != nil {
+		return err
+	}
+	var max uint64
+	for j := range m.participants {
+		if j == m.self {
+			continue
+		}
+		t, err := m.readTicket(j)
+		if err != nil {
+			return err
+		}
+		if t.number > max {
+			max = t.number
+		}
+	}
+	mine := ticket{number: max + 1}
+	if err := m.writeTicket(mine); err != nil {
+		return err
+	}
+
+	for j := range m.participants {
+		if j == m.self {
+			continue
+		}
+		for {
+			t, err := m.readTicket(j)
+			if err != nil {
+				return err
+			}
+			if !t.choosing && (t.number == 0 || t.number > mine.number || (t.number == mine.number && j > m.self)) {
+				break
+			}
+			select {
+			case <-time.After(m.PollInterval):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+// Unlock gives up the number, which lets the next waiting participant in
+func (m *Mutex) Unlock() error {
+	m.lock.Lock()
+	held := m.held
+	m.lock.Unlock()
+	if !held {
+		return ErrNotLocked
+	}
+	return m.release()
+}
+
+// Held
+// whether the lock is still held: always true between Lock and Unlock without a Lease, with a
+// Lease only while the last successful renewal is less than a Lease old
+func (m *Mutex) Held() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.held && (m.Lease <= 0 || time.Since(m.renewed) < m.Lease)
+}
+
+// release stops the renewal and clears the register
+func (m *Mutex) release() error {
+	m.lock.Lock()
+	if m.stopRenewal != nil {
+		close(m.stopRenewal)
+		m.stopRenewal = nil
+	}
+	m.held = false
+	m.lock.Unlock()
+	return m.writeTicket(ticket{})
+}
+
+// renew rewrites the current ticket every Lease/3 so it doesn't expire while it is needed
+func (m *Mutex) renew(stop <-chan struct{}) {
+	ticker := time.NewTicker(m.Lease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+		m.lock.Lock()
+		if m.stopRenewal != stop {
+			m.lock.Unlock()
+			return
+		}
+		err := m.write(m.current)
+		m.lock.Unlock()
+		if err != nil {
+			log.Printf("renewing lease of lock %s: %v", m.name, err)
+		}
+	}
+}
+
+// writeTicket
+// write the participant's register, with the Lease as TTL unless the ticket is idle. Writes are
+// serialized so a renewal can't overwrite a newer ticket with an older one.
+func (m *Mutex) writeTicket(t ticket) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.write(t)
+}
+
+// write is writeTicket for callers holding m.lock
+func (m *Mutex) write(t ticket) error {
+	start := time.Now()
+	var err error
+	if m.Lease > 0 && t != (ticket{}) {
+		err = m.client.WriteWithTTL(m.key(m.self), t.String(), m.Lease)
+	} else {
+		err = m.client.Write(m.key(m.self), t.String())
+	}
+	if err != nil {
+		return err
+	}
+	m.current, m.renewed = t, start
+	return nil
+}
+
+// readTicket reads the register of a participant, one that was never written or expired is idle
+func (m *Mutex) readTicket(participant int) (ticket, error) {
+	value, err := m.client.Read(m.key(participant))
+	if errors.Is(err, protocol.ErrKeyNotFound) {
+		return ticket{}, nil
+	}
+	if err != nil {
+		return ticket{}, err
+	}
+	return parseTicket(value)
+}
@@ -0,0 +1,182 @@
+package mutex
+
+import (
+	"context"
+	"errors"
+	"shared-registers/client/protocol"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryClient keeps the registers in a map, enough to test the bakery without replicas. Setting
+// down makes every operation of this participant fail, as if it had crashed.
+type memoryClient struct {
+	store *memoryStore
+	down  bool
+}
+
+type memoryStore struct {
+	lock      sync.Mutex
+	values    map[string]string
+	expiresAt map[string]time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{values: map[string]string{}, expiresAt: map[string]time.Time{}}
+}
+
+func (m *memoryClient) Write(key string, value string) error {
+	return m.WriteWithTTL(key, value, 0)
+}
+
+func (m *memoryClient) WriteWithTTL(key string, value string, ttl time.Duration) error {
+	m.store.lock.Lock()
+	defer m.store.lock.Unlock()
+	if m.down {
+		return errors.New("client is down")
+	}
+	m.store.values[key] = value
+	delete(m.store.expiresAt, key)
+	if ttl > 0 {
+		m.store.expiresAt[key] = time.Now().Add(ttl)
+	}
+	return nil
+}
+
+func (m *memoryClient) Read(key string) (string, error) {
+	m.store.lock.Lock()
+	defer m.store.lock.Unlock()
+	if m.down {
+		return "", errors.New("client is down")
+	}
+	v, ok := m.store.values[key]
+	if expiresAt, expiring := m.store.expiresAt[key]; !ok || (expiring && !time.Now().Before(expiresAt)) {
+		return "", &protocol.KeyNotFoundError{Key: key}
+	}
+	return v, nil
+}
+
+func newMutexes(t *testing.T, store *memoryStore, n int) ([]*Mutex, []*memoryClient) {
+	participants := make([]string, n)
+	for i := range participants {
+		participants[i] = "p" + strconv.Itoa(i)
+	}
+	mutexes := make([]*Mutex, n)
+	clients := make([]*memoryClient, n)
+	for i := range participants {
+		clients[i] = &memoryClient{store: store}
+		m, err := New(clients[i], "jobs", participants, participants[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.PollInterval = time.Millisecond
+		mutexes[i] = m
+	}
+	return mutexes, clients
+}
+
+func TestMutualExclusion(t *testing.T) {
+	mutexes, _ := newMutexes(t, newMemoryStore(), 5)
+	var inside, counter int
+	var wg sync.WaitGroup
+	for _, m := range mutexes {
+		wg.Add(1)
+		go func(m *Mutex) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				if err := m.Lock(context.Background()); err != nil {
+					t.Error(err)
+					return
+				}
+				inside++
+				if inside != 1 {
+					t.Errorf("%d participants in the critical section", inside)
+				}
+				counter++
+				inside--
+				if err := m.Unlock(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(m)
+	}
+	wg.Wait()
+	if counter != 100 {
+		t.Errorf("expected 100 increments, got %d", counter)
+	}
+}
+
+func TestLockCanceled(t *testing.T) {
+	mutexes, _ := newMutexes(t, newMemoryStore(), 2)
+	if err := mutexes[0].Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := mutexes[1].Lock(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the lock to time out, got %v", err)
+	}
+	// the canceled participant gave up its number and doesn't block the holder's next Lock
+	if err := mutexes[0].Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := mutexes[0].Lock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := mutexes[0].Lock(ctx); err != ErrLocked {
+		t.Errorf("expected ErrLocked, got %v", err)
+	}
+	if err := mutexes[1].Unlock(); err != ErrNotLocked {
+		t.Errorf("expected ErrNotLocked, got %v", err)
+	}
+}
+
+func TestLeaseExpiresAfterCrash(t *testing.T) {
+	mutexes, clients := newMutexes(t, newMemoryStore(), 2)
+	for _, m := range mutexes {
+		m.Lease = 60 * time.Millisecond
+	}
+	if err := mutexes[0].Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// renewals keep the register alive past the lease
+	time.Sleep(100 * time.Millisecond)
+	if !mutexes[0].Held() {
+		t.Fatal("lease expired while renewing")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if err := mutexes[1].Lock(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the lock to be held, got %v", err)
+	}
+
+	clients[0].store.lock.Lock()
+	clients[0].down = true
+	clients[0].store.lock.Unlock()
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := mutexes[1].Lock(ctx); err != nil {
+		t.Fatalf("expected the lock after the lease expired, got %v", err)
+	}
+	if mutexes[0].Held() {
+		t.Error("crashed participant still believes it holds the lock")
+	}
+}
+
+func TestNewRejectsBadParticipants(t *testing.T) {
+	client := &memoryClient{store: newMemoryStore()}
+	if _, err := New(client, "jobs", []string{"a", "b"}, "c"); err != ErrNotParticipant {
+		t.Errorf("expected ErrNotParticipant, got %v", err)
+	}
+	if _, err := New(client, "jobs", []string{"a", "a"}, "a"); err == nil {
+		t.Error("duplicate participants accepted")
+	}
+	if _, err := New(client, "a/b", []string{"a"}, "a"); err == nil {
+		t.Error("lock name with / accepted")
+	}
+}
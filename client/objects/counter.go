@@ -0,0 +1,114 @@
+package objects
+
+import (
+	"errors"
+	"shared-registers/client/snapshot"
+	"shared-registers/client/typed"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrNotParticipant = errors.New("handle isn't a participant of the object")
+
+// Counter
+// PN counter over one component register per participant: each participant only adds to its own
+// totals of increments and decrements, and the value is the sum over all components. The components
+// are those of an atomic snapshot object, so Value is linearizable even with concurrent Adds of
+// arbitrary deltas, where summing independent reads could return a total that never existed. Both
+// operations are wait-free, an Add costs a snapshot scan plus one write.
+type Counter struct {
+	components *snapshot.Snapshot
+	self       int
+
+	lock     sync.Mutex // serializes the Adds of the participant
+	inc, dec uint64
+	loaded   bool
+}
+
+// NewCounter
+// create the handle of participant self on the named counter, or a read-only handle if self isn't
+// one of the participants, which must be listed in the same order by every handle
+func NewCounter(client typed.Client, name string, participants []string, self string) (*Counter, error) {
+	components, err := snapshot.New(client, "counter-"+name, participants, self)
+	if err != nil {
+		return nil, err
+	}
+	return &Counter{components: components, self: indexOf(participants, self)}, nil
+}
+
+// Add adds delta, which may be negative, to the counter
+func (c *Counter) Add(delta int64) error {
+	if c.self < 0 {
+		return ErrNotParticipant
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if !c.loaded {
+		// a previous handle of the participant may have counted already
+		values, err := c.components.Scan()
+		if err != nil {
+			return err
+		}
+		if c.inc, c.dec, err = parseTotals(values[c.self]); err != nil {
+			return err
+		}
+		c.loaded = true
+	}
+	inc, dec := c.inc, c.dec
+	if delta >= 0 {
+		inc += uint64(delta)
+	} else {
+		dec += uint64(-delta)
+	}
+	if err := c.components.Update([]byte(strconv.FormatUint(inc, 10) + "," + strconv.FormatUint(dec, 10))); err != nil {
+		return err
+	}
+	c.inc, c.dec = inc, dec
+	return nil
+}
+
+// Inc adds 1 to the counter
+func (c *Counter) Inc() error {
+	return c.Add(1)
+}
+
+// Value returns the sum of all the Adds linearized before it
+func (c *Counter) Value() (int64, error) {
+	values, err := c.components.Scan()
+	if err != nil {
+		return 0, err
+	}
+	var inc, dec uint64
+	for _, v := range values {
+		i, d, err := parseTotals(v)
+		if err != nil {
+			return 0, err
+		}
+		inc, dec = inc+i, dec+d
+	}
+	return int64(inc - dec), nil
+}
+
+// parseTotals parses the "increments,decrements" of a component, an empty component counted nothing
+func parseTotals(value []byte) (uint64, uint64, error) {
+	if value == nil {
+		return 0, 0, nil
+	}
+	inc, dec, ok := strings.Cut(string(value), ",")
+	i, err1 := strconv.ParseUint(inc, 10, 64)
+	d, err2 := strconv.ParseUint(dec, 10, 64)
+	if !ok || err1 != nil || err2 != nil {
+		return 0, 0, errors.New("malformed counter component " + strconv.Quote(string(value)))
+	}
+	return i, d, nil
+}
+
+func indexOf(participants []string, self string) int {
+	for i, p := range participants {
+		if p == self {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,90 @@
+package objects
+
+import (
+	"errors"
+	"shared-registers/client/snapshot"
+	"shared-registers/client/typed"
+	"strconv"
+	"sync"
+)
+
+// MaxRegister
+// register whose reads return the largest value written so far, over one component register per
+// participant holding the largest value that participant wrote. Like Counter, the components form
+// an atomic snapshot object, which makes Read linearizable and both operations wait-free.
+type MaxRegister struct {
+	components *snapshot.Snapshot
+	self       int
+
+	lock    sync.Mutex // serializes the writes of the participant
+	largest uint64
+	loaded  bool
+}
+
+// NewMaxRegister
+// create the handle of participant self on the named max-register, or a read-only handle if self
+// isn't one of the participants, which must be listed in the same order by every handle
+func NewMaxRegister(client typed.Client, name string, participants []string, self string) (*MaxRegister, error) {
+	components, err := snapshot.New(client, "max-"+name, participants, self)
+	if err != nil {
+		return nil, err
+	}
+	return &MaxRegister{components: components, self: indexOf(participants, self)}, nil
+}
+
+// Write raises the register to v, a value not larger than the participant's previous ones is a no-op
+func (m *MaxRegister) Write(v uint64) error {
+	if m.self < 0 {
+		return ErrNotParticipant
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if !m.loaded {
+		values, err := m.components.Scan()
+		if err != nil {
+			return err
+		}
+		if m.largest, err = parseMax(values[m.self]); err != nil {
+			return err
+		}
+		m.loaded = true
+	}
+	if v <= m.largest {
+		return nil
+	}
+	if err := m.components.Update([]byte(strconv.FormatUint(v, 10))); err != nil {
+		return err
+	}
+	m.largest = v
+	return nil
+}
+
+// Read returns the largest value written before it, 0 if there is none
+func (m *MaxRegister) Read() (uint64, error) {
+	values, err := m.components.Scan()
+	if err != nil {
+		return 0, err
+	}
+	var largest uint64
+	for _, v := range values {
+		n, err := parseMax(v)
+		if err != nil {
+			return 0, err
+		}
+		if n > largest {
+			largest = n
+		}
+	}
+	return largest, nil
+}
+
+func parseMax(value []byte) (uint64, error) {
+	if value == nil {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(string(value), 10, 64)
+	if err != nil {
+		return 0, errors.New("malformed max-register component " + strconv.Quote(string(value)))
+	}
+	return n, nil
+}
@@ -0,0 +1,259 @@
+package objects
+
+import (
+	"math/rand"
+	"shared-registers/client/protocol"
+	"shared-registers/client/typed"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+var _testServiceAddrs = []string{
+	"amd183.utah.cloudlab.us:50051",
+	"amd185.utah.cloudlab.us:50051",
+	"amd192.utah.cloudlab.us:50051",
+	"amd200.utah.cloudlab.us:50051",
+	"amd204.utah.cloudlab.us:50051",
+}
+
+// memoryClient keeps the registers in a map, enough to test the objects without replicas
+type memoryClient struct {
+	lock   sync.Mutex
+	values map[string][]byte
+}
+
+func (m *memoryClient) WriteBytes(key string, value []byte, contentType string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.values[key] = value
+	return nil
+}
+
+func (m *memoryClient) ReadBytes(key string) ([]byte, string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	v, ok := m.values[key]
+	if !ok {
+		return nil, "", &protocol.KeyNotFoundError{Key: key}
+	}
+	return v, "application/json", nil
+}
+
+// operation is one call in a history, start and end are ticks of a clock shared by all callers
+type operation struct {
+	start, end int64
+	read       bool
+	arg        int64 // Add delta or written value
+	result     int64 // value read
+}
+
+type history struct {
+	clock int64
+	lock  sync.Mutex
+	ops   []operation
+}
+
+func (h *history) record(read bool, arg int64, call func() (int64, error)) error {
+	start := atomic.AddInt64(&h.clock, 1)
+	result, err := call()
+	end := atomic.AddInt64(&h.clock, 1)
+	if err != nil {
+		return err
+	}
+	h.lock.Lock()
+	h.ops = append(h.ops, operation{start: start, end: end, read: read, arg: arg, result: result})
+	h.lock.Unlock()
+	return nil
+}
+
+// linearizable
+// search for an order of the operations that respects their real-time order and in which every
+// read returns the state of the sequential object, step applies an operation to the state
+func linearizable(ops []operation, step func(state int64, op operation) (int64, bool)) bool {
+	type key struct {
+		done  uint64
+		state int64
+	}
+	failed := make(map[key]bool)
+	var search func(done uint64, state int64) bool
+	search = func(done uint64, state int64) bool {
+		if done == 1<<len(ops)-1 {
+			return true
+		}
+		if failed[key{done, state}] {
+			return false
+		}
+		// an operation can go next only if no pending operation ended before it started
+		firstEnd := int64(-1)
+		for i, op := range ops {
+			if done&(1<<i) == 0 && (firstEnd < 0 || op.end < firstEnd) {
+				firstEnd = op.end
+			}
+		}
+		for i, op := range ops {
+			if done&(1<<i) != 0 || op.start > firstEnd {
+				continue
+			}
+			if next, ok := step(state, op); ok && search(done|1<<i, next) {
+				return true
+			}
+		}
+		failed[key{done, state}] = true
+		return false
+	}
+	return search(0, 0)
+}
+
+func counterStep(state int64, op operation) (int64, bool) {
+	if op.read {
+		return state, op.result == state
+	}
+	return state + op.arg, true
+}
+
+func maxStep(state int64, op operation) (int64, bool) {
+	if op.read {
+		return state, op.result == state
+	}
+	if op.arg > state {
+		return op.arg, true
+	}
+	return state, true
+}
+
+// runCounterHistory has every participant alternate random Adds and Values, and checks the history
+func runCounterHistory(t *testing.T, clients []typed.Client, name string) {
+	participants := make([]string, len(clients))
+	for i := range participants {
+		participants[i] = "w" + strconv.Itoa(i)
+	}
+	h := &history{}
+	var wg sync.WaitGroup
+	for i, client := range clients {
+		counter, err := NewCounter(client, name, participants, participants[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(seed))
+			for j := 0; j < 3; j++ {
+				delta := r.Int63n(11) - 5
+				if err := h.record(false, delta, func() (int64, error) { return 0, counter.Add(delta) }); err != nil {
+					t.Error(err)
+					return
+				}
+				if err := h.record(true, 0, counter.Value); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(int64(i))
+	}
+	wg.Wait()
+	if !linearizable(h.ops, counterStep) {
+		t.Errorf("counter history isn't linearizable: %+v", h.ops)
+	}
+}
+
+// runMaxHistory has every participant alternate random Writes and Reads, and checks the history
+func runMaxHistory(t *testing.T, clients []typed.Client, name string) {
+	participants := make([]string, len(clients))
+	for i := range participants {
+		participants[i] = "w" + strconv.Itoa(i)
+	}
+	h := &history{}
+	var wg sync.WaitGroup
+	for i, client := range clients {
+		register, err := NewMaxRegister(client, name, participants, participants[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(seed))
+			for j := 0; j < 3; j++ {
+				v := r.Int63n(100)
+				if err := h.record(false, v, func() (int64, error) { return 0, register.Write(uint64(v)) }); err != nil {
+					t.Error(err)
+					return
+				}
+				if err := h.record(true, 0, func() (int64, error) {
+					v, err := register.Read()
+					return int64(v), err
+				}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(int64(i))
+	}
+	wg.Wait()
+	if !linearizable(h.ops, maxStep) {
+		t.Errorf("max-register history isn't linearizable: %+v", h.ops)
+	}
+}
+
+func memoryClients(n int) []typed.Client {
+	shared := &memoryClient{values: map[string][]byte{}}
+	clients := make([]typed.Client, n)
+	for i := range clients {
+		clients[i] = shared
+	}
+	return clients
+}
+
+func clusterClients(t *testing.T, n int) []typed.Client {
+	clients := make([]typed.Client, n)
+	for i := range clients {
+		client, err := protocol.CreateSharedRegisterClient(protocol.NewClientID(), _testServiceAddrs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clients[i] = client
+	}
+	return clients
+}
+
+func TestCounterLinearizable(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		runCounterHistory(t, memoryClients(3), "c"+strconv.Itoa(i))
+	}
+}
+
+func TestMaxRegisterLinearizable(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		runMaxHistory(t, memoryClients(3), "m"+strconv.Itoa(i))
+	}
+}
+
+func TestCounterResumesTotals(t *testing.T) {
+	client := &memoryClient{values: map[string][]byte{}}
+	first, _ := NewCounter(client, "jobs", []string{"a", "b"}, "a")
+	if err := first.Add(5); err != nil {
+		t.Fatal(err)
+	}
+	second, _ := NewCounter(client, "jobs", []string{"a", "b"}, "a")
+	if err := second.Add(-2); err != nil {
+		t.Fatal(err)
+	}
+	reader, _ := NewCounter(client, "jobs", []string{"a", "b"}, "")
+	if v, err := reader.Value(); err != nil || v != 3 {
+		t.Errorf("expected 3, got %d %v", v, err)
+	}
+	if err := reader.Inc(); err != ErrNotParticipant {
+		t.Errorf("expected ErrNotParticipant, got %v", err)
+	}
+}
+
+func TestCounterOnCluster(t *testing.T) {
+	runCounterHistory(t, clusterClients(t, 3), protocol.NewClientID())
+}
+
+func TestMaxRegisterOnCluster(t *testing.T) {
+	runMaxHistory(t, clusterClients(t, 3), protocol.NewClientID())
+}
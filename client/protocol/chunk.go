@@ -0,0 +1,197 @@
+package protocol
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"time"
+)
+
+const (
+	DefaultChunkThreshold = 1 << 20
+	DefaultChunkSize      = 256 << 10
+
+	// chunkExpiryGrace is how much longer than their manifest the chunks of a TTL write may live
+	chunkExpiryGrace = time.Minute
+
+	// chunkReadRetries bounds how often a read restarts because a newer write replaced the manifest
+	// and collected its chunks while they were being read
+	chunkReadRetries = 3
+)
+
+// errChunkGone means a chunk of the manifest was deleted or never written
+var errChunkGone = errors.New("chunk is gone")
+
+// chunkKey
+// derive the key of a chunk from the register key, the write it belongs to and its sha256, chunks
+// live under common.InternalKeyPrefix so they never collide with user keys
+func chunkKey(key, writeID string, sum []byte) string {
+	return common.InternalKeyPrefix + "chunk/" + key + "/" + writeID + "/" + hex.EncodeToString(sum)
+}
+
+// ChunkKeys returns the keys the chunks of the manifest stored under key are stored under, in order
+func ChunkKeys(key string, manifest *proto.ChunkManifest) []string {
+	keys := make([]string, 0, len(manifest.GetChunks()))
+	for _, sum := range manifest.GetChunks() {
+		keys = append(keys, chunkKey(key, manifest.GetWriteID(), sum))
+	}
+	return keys
+}
+
+// writeValue
+// store the value under the key, compressed if it reaches CompressionThreshold, a value that is still
+// larger than ChunkThreshold is stored as ChunkSize chunks
+// under derived keys followed by a manifest listing them under the key itself. The manifest is
+// written last, so readers either find the old value or a manifest whose chunks are all on a quorum.
+// The chunks of the value being replaced, as the get or query phase of the write found it, are
+// deleted afterwards. Returns the timestamp the value got, the caller holds opsLock.
+func (s *SharedRegisterClient) writeValue(key string, value *proto.StoredValue, ttl time.Duration) (*proto.TimeStamp, error) {
+	if err := s.compressValue(value); err != nil {
+		return nil, err
+	}
+	if s.ChunkThreshold > 0 && len(value.GetVal()) > s.ChunkThreshold {
+		// the chunks of a TTL write expire a bit after the manifest at the latest
+		var expiresAt uint64
+		if ttl > 0 {
+			expiresAt = uint64(time.Now().Add(ttl + chunkExpiryGrace).UnixNano())
+		}
+		manifest, err := s.writeChunks(key, value.GetVal(), expiresAt)
+		if err != nil {
+			return nil, err
+		}
+		value = &proto.StoredValue{ContentType: value.GetContentType(), Compression: value.GetCompression(),
+			Manifest: manifest, ExpiresAt: expiresAt}
+	}
+	replaced, err := s.writeRaw(key, value, ttl)
+	if err != nil {
+		return nil, err
+	}
+	s.deleteChunks(key, replaced.GetManifest())
+	return value.GetTs(), nil
+}
+
+// readValue
+// find the latest value of the key, reassemble it if it is chunked and decompress it, the caller
+// holds opsLock
+func (s *SharedRegisterClient) readValue(key string) (*proto.StoredValue, error) {
+	var lastManifestTs *proto.TimeStamp
+	for i := 0; i < chunkReadRetries; i++ {
+		value, err := s.readRaw(key)
+		if err != nil {
+			return nil, err
+		}
+		if value.GetManifest() == nil {
+			return value, s.decompressValue(value)
+		}
+		if lastManifestTs != nil && common.CompareTimeStamps(value.GetTs(), lastManifestTs) == 0 {
+			// the manifest didn't change, so its chunks are really missing
+			return nil, errors.New("chunks of key " + key + " are missing")
+		}
+		data, err := s.readChunks(key, value.GetManifest())
+		if err == errChunkGone {
+			lastManifestTs = value.GetTs()
+			continue // a newer write replaced the manifest and deleted its chunks, read the newer value
+		}
+		if err != nil {
+			return nil, err
+		}
+		value = &proto.StoredValue{Val: data, Ts: value.GetTs(), ContentType: value.GetContentType(),
+			Compression: value.GetCompression()}
+		return value, s.decompressValue(value)
+	}
+	return nil, errors.New("key " + key + " changed too often while reading its chunks")
+}
+
+func (s *SharedRegisterClient) writeChunks(key string, value []byte, expiresAt uint64) (*proto.ChunkManifest, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	chunkSize := s.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	manifest := &proto.ChunkManifest{WriteID: hex.EncodeToString(id), Size: uint64(len(value))}
+	for start := 0; start < len(value); start += chunkSize {
+		end := start + chunkSize
+		if end > len(value) {
+			end = len(value)
+		}
+		sum := sha256.Sum256(value[start:end])
+		if s.encryption != nil {
+			// the hash would tell the replicas about the plaintext, decryption authenticates chunks instead
+			if _, err := rand.Read(sum[:]); err != nil {
+				return nil, err
+			}
+		}
+		chunk := &proto.StoredValue{Val: value[start:end], ExpiresAt: expiresAt}
+		_, err := s.writeRaw(chunkKey(key, manifest.WriteID, sum[:]), chunk, 0)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Chunks = append(manifest.Chunks, sum[:])
+	}
+	return manifest, nil
+}
+
+// readChunks
+// read the chunks of the manifest and check them against their hashes, or decrypt them if they are
+// encrypted, which authenticates them with the chunk key. A chunk is never modified
+// and the manifest is only written once all its chunks are on a quorum, so a get phase is enough
+// for fully replicated chunks, there is nothing to write back.
+func (s *SharedRegisterClient) readChunks(key string, manifest *proto.ChunkManifest) ([]byte, error) {
+	data := make([]byte, 0, manifest.GetSize())
+	for _, sum := range manifest.GetChunks() {
+		var chunk *proto.StoredValue
+		var err error
+		if s.coder != nil {
+			chunk, err = s.readRaw(chunkKey(key, manifest.GetWriteID(), sum))
+		} else {
+			chunk, err = s.completeGetPhase(chunkKey(key, manifest.GetWriteID(), sum))
+		}
+		if errors.Is(err, ErrKeyNotFound) {
+			return nil, errChunkGone
+		}
+		if err != nil {
+			return nil, err
+		}
+		if chunk.GetTs().GetClientID() == "" {
+			return nil, errChunkGone
+		}
+		encrypted := chunk.GetKeyID() != ""
+		if s.coder == nil {
+			if chunk, err = s.openValue(chunkKey(key, manifest.GetWriteID(), sum), chunk); err != nil {
+				return nil, err
+			}
+		}
+		if chunk.GetDeleted() {
+			return nil, errChunkGone
+		}
+		if got := sha256.Sum256(chunk.GetVal()); !encrypted && !bytes.Equal(got[:], sum) {
+			return nil, errors.New("chunk of key " + key + " doesn't match its hash")
+		}
+		data = append(data, chunk.GetVal()...)
+	}
+	if uint64(len(data)) != manifest.GetSize() {
+		return nil, errors.New("chunks of key " + key + " don't add up to the value size")
+	}
+	return data, nil
+}
+
+// deleteChunks
+// write tombstones over the chunks of a replaced manifest. This is best effort: a failure only
+// leaves garbage behind, and so do concurrent writes of the key, which each only delete the chunks
+// of the value their own get phase found.
+func (s *SharedRegisterClient) deleteChunks(key string, manifest *proto.ChunkManifest) {
+	for _, sum := range manifest.GetChunks() {
+		_, err := s.writeRaw(chunkKey(key, manifest.GetWriteID(), sum), &proto.StoredValue{Deleted: true}, 0)
+		if err != nil && s.DebugMode {
+			log.Printf("deleting chunk of key %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package protocol
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log"
+	"shared-registers/common/proto"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrClientIDInUse = errors.New("client ID is registered by another client")
+	// ErrConflictingTimeStamp is returned when a replica already stores a different value with the
+	// timestamp of the write, which means another client is using the same client ID
+	ErrConflictingTimeStamp = errors.New("replica holds a different value for the same timestamp")
+)
+
+// NewClientID returns a random 128-bit client ID, the chance of two clients picking the same one is
+// negligible, RegisterClientID additionally detects the collision
+func NewClientID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("NewClientID: %v", err)
+	}
+	return hex.EncodeToString(b)
+}
+
+type clientRegistration struct {
+	token string
+	lease time.Duration
+	stop  chan struct{}
+	once  sync.Once
+}
+
+// RegisterClientID
+// lease the client ID on a write quorum of replicas, fails with ErrClientIDInUse if another client
+// holds it on any replica. Since any two write quorums intersect and a replica leases an ID to one
+// client at a time, at most one live client owns an ID, and replicas refuse the writes of other
+// clients under it with ErrClientIDInUse. The lease is renewed every lease/3 in the background
+// until Close.
+func (s *SharedRegisterClient) RegisterClientID(lease time.Duration) error {
+	if lease < time.Millisecond {
+		return errors.New("client ID lease has to be at least 1ms")
+	}
+	s.opsLock.Lock()
+	defer s.opsLock.Unlock()
+	if s.registration != nil {
+		return errors.New("client ID is already registered")
+	}
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return err
+	}
+	r := &clientRegistration{
+		token: hex.EncodeToString(tokenBytes),
+		lease: lease,
+		stop:  make(chan struct{}),
+	}
+	if err := s.registerClientID(r.token, r.lease); err != nil {
+		// give back the leases granted by the replicas that didn't see the conflict
+		s.registerClientID(r.token, 0)
+		return err
+	}
+	s.registration = r
+
+	go func() {
+		ticker := time.NewTicker(lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				if err := s.registerClientID(r.token, r.lease); err != nil {
+					log.Printf("failed to renew the lease of client ID %s: %v", s.ClientID, err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// registerClientID sends the registration to every replica, a lease of 0 releases it
+func (s *SharedRegisterClient) registerClientID(token string, lease time.Duration) error {
+	var inUse int32
+	requests := make([]func() bool, 0)
+	for _, conn := range s.replicaConns {
+		conn := conn
+		registerOnReplica := func() bool {
+			err := conn.RegisterClient(&proto.RegisterClientReq{
+				ClientID:    s.ClientID,
+				Token:       token,
+				LeaseMillis: uint64(lease / time.Millisecond),
+			})
+			if status.Code(err) == codes.AlreadyExists {
+				atomic.StoreInt32(&inUse, 1)
+			}
+			return err == nil
+		}
+		requests = append(requests, registerOnReplica)
+	}
+	timedOut := s.waitForQuorum(s.quorumSystem().IsWriteQuorum, requests)
+	if atomic.LoadInt32(&inUse) == 1 {
+		return ErrClientIDInUse
+	}
+	if timedOut {
+		return &PhaseTimeoutError{Phase: "registerClientID"}
+	}
+	return nil
+}
+
+// Close
+// stop renewing and release the client ID registration, then close the connections to the replicas
+func (s *SharedRegisterClient) Close() error {
+	s.opsLock.Lock()
+	r := s.registration
+	s.opsLock.Unlock()
+	if r != nil {
+		r.once.Do(func() {
+			close(r.stop)
+			s.registerClientID(r.token, 0)
+		})
+	}
+	var err error
+	for _, conn := range s.replicaConns {
+		if closeErr := conn.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,235 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	protobuf "google.golang.org/protobuf/proto"
+	"shared-registers/client/erasure"
+	"shared-registers/client/util"
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"sync"
+	"time"
+)
+
+// codedReadRetries bounds how often a read restarts when the replicas already garbage collected the
+// fragments of the timestamp it found, which only happens under concurrent writes
+const codedReadRetries = 3
+
+// EnableErasureCoding
+// switch the client to erasure-coded registers (CAS algorithm by Cadambe et al.): every value is
+// encoded into n = len(serverAddrs) fragments with a Reed-Solomon code, any dataShards of which
+// reconstruct the value, and replica i only stores fragment i. Each phase waits for
+// ceil((n + dataShards) / 2) replicas so it tolerates (n - dataShards) / 2 failures.
+// All the clients accessing the same keys must use the same dataShards and the same server order,
+// erasure-coded registers are stored apart from the replicated ones on the replicas.
+func (s *SharedRegisterClient) EnableErasureCoding(dataShards int) error {
+	s.opsLock.Lock()
+	defer s.opsLock.Unlock()
+	coder, err := erasure.NewEncoder(dataShards, s.replicaNum)
+	if err != nil {
+		return err
+	}
+	quorum := (s.replicaNum + dataShards + 1) / 2
+	if quorum > len(s.replicaConns) {
+		return fmt.Errorf("erasure coding with k=%d needs %d reachable replicas, only connected to %d",
+			dataShards, quorum, len(s.replicaConns))
+	}
+	s.coder = coder
+	s.codedQuorumSize = quorum
+	return nil
+}
+
+// completeCodedWrite
+// 1. query phase: find the largest finalized timestamp from a quorum and choose a higher one
+// 2. pre-write phase: send fragment i to replica i, wait for a quorum of acks
+// 3. finalize phase: tell the replicas the new timestamp is complete, wait for a quorum of acks
+// Returns the value the write replaced without its val, as the query phase found it: nil if the key
+// was never written or the quorum doesn't hold its fragments.
+func (s *SharedRegisterClient) completeCodedWrite(key string, value *proto.StoredValue, ttl time.Duration) (*proto.StoredValue, error) {
+	maxTs, replaced, err := s.completeCodedQueryPhase(key)
+	if err != nil {
+		return nil, err
+	}
+	newTs, err := s.nextTimeStamp(maxTs)
+	if err != nil {
+		return nil, err
+	}
+	value.Ts = newTs
+
+	stampExpiry(value, newTs, ttl)
+	if err := s.sealValue(key, value, newTs); err != nil {
+		return nil, err
+	}
+	shards := s.coder.Encode(value.GetVal())
+	// every fragment carries the rest of the value, e.g. the content type or the tombstone flag
+	meta := protobuf.Clone(value).(*proto.StoredValue)
+	meta.Val, meta.Ts = nil, nil
+	requests := make([]func() bool, 0)
+	for _, conn := range s.replicaConns {
+		conn := conn
+		preWriteToReplica := func() bool {
+			err := conn.CodedPreWrite(&proto.CodedPreWriteReq{
+				Key: key,
+				Ts:  newTs,
+				Fragment: &proto.CodedFragment{
+					Data:        shards[conn.index],
+					Index:       uint32(conn.index),
+					DataShards:  uint32(s.coder.DataShards),
+					TotalShards: uint32(s.coder.TotalShards),
+					ValueSize:   uint64(len(value.GetVal())),
+					Meta:        meta,
+				}})
+			return err == nil
+		}
+		requests = append(requests, preWriteToReplica)
+	}
+	timedOut := util.WaitForMajoritySuccessFromJobs(s.codedQuorumSize, s.PhaseTimeout, requests)
+	if timedOut {
+		return nil, &PhaseTimeoutError{Phase: "completeCodedWrite pre-write"}
+	}
+
+	_, err = s.completeCodedFinalizePhase(key, newTs, false)
+	return replaced, err
+}
+
+// completeCodedRead
+// 1. query phase: find the largest finalized timestamp from a quorum
+// 2. finalize phase: make sure a quorum has the timestamp finalized, which is what makes the read
+// atomic, and collect the fragments the replicas hold for it, any k of them decode the value
+func (s *SharedRegisterClient) completeCodedRead(key string) (*proto.StoredValue, error) {
+	for i := 0; i < codedReadRetries; i++ {
+		ts, _, err := s.completeCodedQueryPhase(key)
+		if err != nil {
+			return nil, err
+		}
+		if ts == nil {
+			return nil, &KeyNotFoundError{Key: key}
+		}
+		fragments, err := s.completeCodedFinalizePhase(key, ts, true)
+		if err != nil {
+			return nil, err
+		}
+		value, err := s.decodeFragments(fragments)
+		if err == erasure.ErrTooFewShards {
+			continue // the fragments were garbage collected by a newer write, read the newer one
+		}
+		if err != nil {
+			return nil, err
+		}
+		value.Ts = ts
+		return value, nil
+	}
+	return nil, errors.New("completeCodedRead: not enough fragments for key " + key)
+}
+
+// completeCodedQueryPhase
+// return the largest finalized timestamp of a quorum and the value it stands for without its val,
+// nil if none of the replicas reporting the timestamp holds its fragment
+func (s *SharedRegisterClient) completeCodedQueryPhase(key string) (*proto.TimeStamp, *proto.StoredValue, error) {
+	var mu sync.Mutex
+	var maxTs *proto.TimeStamp
+	var meta *proto.StoredValue
+	requests := make([]func() bool, 0)
+	for _, conn := range s.replicaConns {
+		conn := conn
+		queryReplica := func() bool {
+			resp, err := conn.CodedQuery(&proto.CodedQueryReq{Key: key})
+			if err != nil {
+				return false
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			switch common.CompareTimeStamps(resp.GetTs(), maxTs) {
+			case 1:
+				maxTs, meta = resp.GetTs(), resp.GetMeta()
+			case 0:
+				if meta == nil {
+					meta = resp.GetMeta()
+				}
+			}
+			return true
+		}
+		requests = append(requests, queryReplica)
+	}
+	timedOut := util.WaitForMajoritySuccessFromJobs(s.codedQuorumSize, s.PhaseTimeout, requests)
+	if timedOut {
+		return nil, nil, &PhaseTimeoutError{Phase: "completeCodedQueryPhase"}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if meta != nil {
+		meta = protobuf.Clone(meta).(*proto.StoredValue)
+		meta.Ts = maxTs
+	}
+	return maxTs, meta, nil
+}
+
+// completeCodedFinalizePhase returns the fragments of ts received so far, indexed by fragment index
+func (s *SharedRegisterClient) completeCodedFinalizePhase(key string, ts *proto.TimeStamp, wantFragment bool) ([]*proto.CodedFragment, error) {
+	var mu sync.Mutex
+	fragments := make([]*proto.CodedFragment, s.replicaNum)
+	requests := make([]func() bool, 0)
+	for _, conn := range s.replicaConns {
+		conn := conn
+		finalizeOnReplica := func() bool {
+			resp, err := conn.CodedFinalize(&proto.CodedFinalizeReq{
+				Key:          key,
+				Ts:           ts,
+				WantFragment: wantFragment,
+			})
+			if err != nil {
+				return false
+			}
+			if f := resp.GetFragment(); f != nil && int(f.GetIndex()) < len(fragments) {
+				mu.Lock()
+				fragments[f.GetIndex()] = f
+				mu.Unlock()
+			}
+			return true
+		}
+		requests = append(requests, finalizeOnReplica)
+	}
+	timedOut := util.WaitForMajoritySuccessFromJobs(s.codedQuorumSize, s.PhaseTimeout, requests)
+	if timedOut {
+		return nil, &PhaseTimeoutError{Phase: "completeCodedFinalizePhase"}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]*proto.CodedFragment(nil), fragments...), nil
+}
+
+// decodeFragments returns the value encoded in the fragments, without timestamp
+func (s *SharedRegisterClient) decodeFragments(fragments []*proto.CodedFragment) (*proto.StoredValue, error) {
+	shards := make([][]byte, s.coder.TotalShards)
+	valueSize := -1
+	var meta *proto.StoredValue
+	for i, f := range fragments {
+		if f == nil {
+			continue
+		}
+		if int(f.GetDataShards()) != s.coder.DataShards || int(f.GetTotalShards()) != s.coder.TotalShards {
+			return nil, fmt.Errorf("fragment encoded with k=%d n=%d, client uses k=%d n=%d",
+				f.GetDataShards(), f.GetTotalShards(), s.coder.DataShards, s.coder.TotalShards)
+		}
+		shards[i] = f.GetData()
+		if shards[i] == nil {
+			shards[i] = []byte{} // protobuf doesn't tell an empty shard of an empty value from a missing one
+		}
+		valueSize = int(f.GetValueSize())
+		meta = f.GetMeta()
+	}
+	if valueSize < 0 {
+		return nil, erasure.ErrTooFewShards
+	}
+	value, err := s.coder.Decode(shards, valueSize)
+	if err != nil {
+		return nil, err
+	}
+	result := &proto.StoredValue{}
+	if meta != nil {
+		result = protobuf.Clone(meta).(*proto.StoredValue)
+	}
+	result.Val = value
+	return result, nil
+}
@@ -0,0 +1,85 @@
+package protocol
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"shared-registers/common/proto"
+	"sync/atomic"
+)
+
+// DefaultDecompressionLimit is how large a compressed value may get decompressed by default
+const DefaultDecompressionLimit = 64 << 20
+
+// ErrDecompressionLimit means a compressed value inflates beyond DecompressionLimit
+var ErrDecompressionLimit = errors.New("value inflates beyond the decompression limit")
+
+// EnableWireCompression
+// gzip the requests to the replicas and have them gzip their responses, this saves bandwidth for
+// text-heavy values at the cost of CPU on both sides. Replicas that don't support it are detected on
+// the first request and get uncompressed requests from then on.
+func (s *SharedRegisterClient) EnableWireCompression() {
+	for _, conn := range s.replicaConns {
+		atomic.StoreInt32(&conn.compress, 1)
+	}
+}
+
+// compressValue
+// gzip the value in place if CompressionThreshold is set and the value is at least that large, the
+// value is left as is if it doesn't get smaller or is larger than the decompression limit, which
+// readers would refuse to inflate. Replicas store the compressed bytes, so compression also saves
+// their memory, and only clients of this version can read such values.
+func (s *SharedRegisterClient) compressValue(value *proto.StoredValue) error {
+	if s.CompressionThreshold <= 0 || len(value.GetVal()) < s.CompressionThreshold || len(value.GetVal()) > s.decompressionLimit() {
+		return nil
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(value.GetVal()); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if buf.Len() < len(value.GetVal()) {
+		value.Val = buf.Bytes()
+		value.Compression = proto.Compression_GZIP
+	}
+	return nil
+}
+
+// decompressValue
+// restore the original bytes of a value written with compressValue, in place. A replica may send a
+// small value that inflates to gigabytes, it fails with ErrDecompressionLimit past the limit.
+func (s *SharedRegisterClient) decompressValue(value *proto.StoredValue) error {
+	switch value.GetCompression() {
+	case proto.Compression_NONE:
+		return nil
+	case proto.Compression_GZIP:
+		r, err := gzip.NewReader(bytes.NewReader(value.GetVal()))
+		if err != nil {
+			return err
+		}
+		limit := s.decompressionLimit()
+		data, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+		if err != nil {
+			return err
+		}
+		if len(data) > limit {
+			return ErrDecompressionLimit
+		}
+		value.Val = data
+		value.Compression = proto.Compression_NONE
+		return nil
+	}
+	return errors.New("value compressed with unknown codec " + value.GetCompression().String())
+}
+
+// decompressionLimit returns DecompressionLimit, or the default if it isn't set
+func (s *SharedRegisterClient) decompressionLimit() int {
+	if s.DecompressionLimit <= 0 {
+		return DefaultDecompressionLimit
+	}
+	return s.DecompressionLimit
+}
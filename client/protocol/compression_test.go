@@ -0,0 +1,53 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"shared-registers/common/proto"
+	"strings"
+	"testing"
+)
+
+func TestCompressValue(t *testing.T) {
+	s := &SharedRegisterClient{CompressionThreshold: 100}
+	text := []byte(strings.Repeat("GET /index.html 200\n", 50))
+
+	value := &proto.StoredValue{Val: append([]byte(nil), text...)}
+	if err := s.compressValue(value); err != nil {
+		t.Fatal(err)
+	}
+	if value.GetCompression() != proto.Compression_GZIP || len(value.GetVal()) >= len(text) {
+		t.Fatalf("expected a smaller gzip value, got %v with %d bytes", value.GetCompression(), len(value.GetVal()))
+	}
+	if err := s.decompressValue(value); err != nil || !bytes.Equal(value.GetVal(), text) {
+		t.Fatalf("round trip failed: %v", err)
+	}
+
+	// below the threshold and incompressible values stay as they are
+	for _, val := range [][]byte{[]byte("short"), {0x1f, 0x8b, 0x08, 0x00, 0xff, 0xfe, 0xfd, 0xfc}} {
+		s.CompressionThreshold = 5
+		value := &proto.StoredValue{Val: val}
+		if err := s.compressValue(value); err != nil || value.GetCompression() != proto.Compression_NONE {
+			t.Errorf("value %q shouldn't be compressed: %v", val, err)
+		}
+	}
+}
+
+func TestDecompressionLimit(t *testing.T) {
+	writer := &SharedRegisterClient{CompressionThreshold: 100}
+	bomb := &proto.StoredValue{Val: make([]byte, 1<<20)}
+	if err := writer.compressValue(bomb); err != nil || bomb.GetCompression() != proto.Compression_GZIP {
+		t.Fatalf("expected a compressed value: %v", err)
+	}
+
+	reader := &SharedRegisterClient{DecompressionLimit: 1 << 10}
+	if err := reader.decompressValue(bomb); !errors.Is(err, ErrDecompressionLimit) {
+		t.Errorf("expected ErrDecompressionLimit, got %v", err)
+	}
+	// the writer doesn't compress what readers with its limit would refuse
+	reader.CompressionThreshold = 100
+	value := &proto.StoredValue{Val: make([]byte, 2<<10)}
+	if err := reader.compressValue(value); err != nil || value.GetCompression() != proto.Compression_NONE {
+		t.Errorf("value above the limit shouldn't be compressed: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package protocol
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"shared-registers/common"
+	"shared-registers/common/proto"
+)
+
+var (
+	// ErrNotEncrypted means a replica returned a plaintext value to a client that encrypts values
+	ErrNotEncrypted = errors.New("value isn't encrypted")
+	// ErrDecryption means a value failed authentication: it was modified, or replayed under another key
+	// or timestamp than it was written with
+	ErrDecryption = errors.New("value failed authentication")
+)
+
+// valueKeys are the client-side encryption keys by key ID
+type valueKeys struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// EnableEncryption
+// encrypt values end to end with AES-256-GCM, so the replicas only ever see ciphertext: new values are
+// encrypted with keys[primary] before they are sent, and values read are decrypted with the key whose
+// ID they carry, so keys can be rotated by making a new key primary while keeping the old ones. The key
+// and the timestamp of a value are authenticated along with it, as are its content type, compression,
+// expiry and chunk manifest, so replicas can't replay a value under another key or timestamp.
+// Tombstones carry a seal of the key and timestamp, as do values with a TTL for the tombstone they
+// expire into, so replicas can't make a key read as deleted either. Tombstones and the sizes of
+// values are not hidden. Plaintext values and unsealed tombstones fail with ErrNotEncrypted unless
+// AcceptPlaintext is set, e.g. while migrating keys written before encryption was enabled.
+func (s *SharedRegisterClient) EnableEncryption(primary string, keys map[string][]byte) error {
+	s.opsLock.Lock()
+	defer s.opsLock.Unlock()
+	if keys[primary] == nil {
+		return errors.New("EnableEncryption: the primary key is missing")
+	}
+	v := &valueKeys{primary: primary, aeads: make(map[string]cipher.AEAD)}
+	for id, key := range keys {
+		if len(key) != common.KeySize {
+			return errors.New("EnableEncryption: key " + id + " isn't 32 bytes long")
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return err
+		}
+		if v.aeads[id], err = cipher.NewGCM(block); err != nil {
+			return err
+		}
+	}
+	s.encryption = v
+	return nil
+}
+
+// sealValue
+// encrypt the value in place if encryption is on, once its timestamp ts is chosen. Chunk manifests
+// have no value of their own, sealing an empty one authenticates the manifest. Tombstones and values
+// with a TTL get the seal of their tombstone.
+func (s *SharedRegisterClient) sealValue(key string, value *proto.StoredValue, ts *proto.TimeStamp) error {
+	if s.encryption == nil {
+		return nil
+	}
+	aead := s.encryption.aeads[s.encryption.primary]
+	value.KeyID = s.encryption.primary
+	if value.GetDeleted() || value.GetExpiresAt() != 0 {
+		seal, err := sealEmpty(aead, sealedData(key, common.ExpiryTombstone(value), ts))
+		if err != nil {
+			return err
+		}
+		value.Seal = seal
+	}
+	if value.GetDeleted() {
+		return nil
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value.GetVal())+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	value.Val = aead.Seal(nonce, nonce, value.GetVal(), sealedData(key, value, ts))
+	return nil
+}
+
+// sealEmpty returns a nonce and the tag authenticating only the associated data
+func sealEmpty(aead cipher.AEAD, data []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, nil, data), nil
+}
+
+// openValue
+// return a copy of the value with its value decrypted, which keeps the KeyID it was encrypted with,
+// or the value itself if it isn't encrypted. The value as stored stays as it is, since it may still
+// be written back.
+func (s *SharedRegisterClient) openValue(key string, value *proto.StoredValue) (*proto.StoredValue, error) {
+	if value.GetKeyID() == "" {
+		if s.encryption != nil && !s.AcceptPlaintext {
+			return nil, ErrNotEncrypted
+		}
+		return value, nil
+	}
+	if s.encryption == nil {
+		if value.GetDeleted() {
+			// nothing to decrypt, the seal can't be checked without the keys though
+			return value, nil
+		}
+		return nil, errors.New("value of key " + key + " is encrypted, but encryption isn't enabled")
+	}
+	aead := s.encryption.aeads[value.GetKeyID()]
+	if aead == nil {
+		return nil, errors.New("value of key " + key + " is encrypted with unknown key " + value.GetKeyID())
+	}
+	if value.GetDeleted() {
+		// a forged tombstone would hide the value, it is returned as is once its seal checks out
+		seal := value.GetSeal()
+		if len(seal) < aead.NonceSize() {
+			return nil, ErrDecryption
+		}
+		data := sealedData(key, common.ExpiryTombstone(value), value.GetTs())
+		if _, err := aead.Open(nil, seal[:aead.NonceSize()], seal[aead.NonceSize():], data); err != nil {
+			return nil, ErrDecryption
+		}
+		return value, nil
+	}
+	if len(value.GetVal()) < aead.NonceSize() {
+		return nil, ErrDecryption
+	}
+	nonce, sealed := value.GetVal()[:aead.NonceSize()], value.GetVal()[aead.NonceSize():]
+	data, err := aead.Open(nil, nonce, sealed, sealedData(key, value, value.GetTs()))
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	return &proto.StoredValue{Val: data, Ts: value.GetTs(), ContentType: value.GetContentType(),
+		Manifest: value.GetManifest(), Compression: value.GetCompression(), ExpiresAt: value.GetExpiresAt(),
+		KeyID: value.GetKeyID()}, nil
+}
+
+// sealedData is the associated data a value is encrypted with, everything about it but the value
+func sealedData(key string, value *proto.StoredValue, ts *proto.TimeStamp) []byte {
+	var data []byte
+	appendBytes := func(b []byte) {
+		data = binary.AppendUvarint(data, uint64(len(b)))
+		data = append(data, b...)
+	}
+	appendBytes([]byte(key))
+	data = binary.AppendUvarint(data, ts.GetRequestNumber())
+	appendBytes([]byte(ts.GetClientID()))
+	data = binary.AppendUvarint(data, ts.GetWallTime())
+	data = binary.AppendUvarint(data, uint64(ts.GetLogical()))
+	appendBytes([]byte(value.GetContentType()))
+	data = binary.AppendUvarint(data, uint64(value.GetCompression()))
+	data = binary.AppendUvarint(data, value.GetExpiresAt())
+	appendBytes([]byte(value.GetKeyID()))
+	if m := value.GetManifest(); m != nil {
+		appendBytes([]byte(m.GetWriteID()))
+		data = binary.AppendUvarint(data, m.GetSize())
+		for _, sum := range m.GetChunks() {
+			appendBytes(sum)
+		}
+	}
+	if value.GetDeleted() {
+		// tombstones have no manifest, nothing else ends with this field
+		appendBytes([]byte("deleted"))
+	}
+	return data
+}
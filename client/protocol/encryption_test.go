@@ -0,0 +1,179 @@
+package protocol
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	protobuf "google.golang.org/protobuf/proto"
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"testing"
+	"time"
+)
+
+func newKey(t *testing.T) []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestValueEncryption(t *testing.T) {
+	k1 := newKey(t)
+	s := &SharedRegisterClient{}
+	if err := s.EnableEncryption("k1", map[string][]byte{"k1": k1}); err != nil {
+		t.Fatal(err)
+	}
+	ts := &proto.TimeStamp{RequestNumber: 7, ClientID: "writer"}
+	value := &proto.StoredValue{Val: []byte("secret"), Ts: ts, ContentType: "text/plain"}
+	if err := s.sealValue("k", value, ts); err != nil {
+		t.Fatal(err)
+	}
+	if value.GetKeyID() != "k1" || bytes.Contains(value.GetVal(), []byte("secret")) {
+		t.Fatalf("value isn't encrypted: %v", value)
+	}
+	opened, err := s.openValue("k", value)
+	if err != nil || string(opened.GetVal()) != "secret" || opened.GetContentType() != "text/plain" {
+		t.Fatalf("round trip failed: %v, %v", opened, err)
+	}
+
+	// replaying the ciphertext under another key or timestamp, or changing its metadata, is detected
+	tampered := []func(v *proto.StoredValue) string{
+		func(v *proto.StoredValue) string { return "other" },
+		func(v *proto.StoredValue) string { v.Ts.RequestNumber++; return "k" },
+		func(v *proto.StoredValue) string { v.Ts.ClientID = "mallory"; return "k" },
+		func(v *proto.StoredValue) string { v.ContentType = "text/html"; return "k" },
+		func(v *proto.StoredValue) string { v.ExpiresAt = 1; return "k" },
+		func(v *proto.StoredValue) string { v.Val[len(v.Val)-1] ^= 1; return "k" },
+	}
+	for i, tamper := range tampered {
+		v := protobuf.Clone(value).(*proto.StoredValue)
+		if _, err := s.openValue(tamper(v), v); !errors.Is(err, ErrDecryption) {
+			t.Errorf("tampering %d: expected ErrDecryption, got %v", i, err)
+		}
+	}
+
+	plaintext := &proto.StoredValue{Val: []byte("old"), Ts: ts}
+	if _, err := s.openValue("k", plaintext); !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("expected ErrNotEncrypted, got %v", err)
+	}
+	s.AcceptPlaintext = true
+	if v, err := s.openValue("k", plaintext); err != nil || string(v.GetVal()) != "old" {
+		t.Errorf("plaintext not accepted: %v, %v", v, err)
+	}
+	if _, err := (&SharedRegisterClient{}).openValue("k", value); err == nil {
+		t.Error("encrypted value opened without encryption")
+	}
+}
+
+func TestTombstoneSeal(t *testing.T) {
+	s := &SharedRegisterClient{}
+	if err := s.EnableEncryption("k1", map[string][]byte{"k1": newKey(t)}); err != nil {
+		t.Fatal(err)
+	}
+	ts := &proto.TimeStamp{RequestNumber: 3, ClientID: "writer"}
+	tombstone := &proto.StoredValue{Ts: ts, Deleted: true}
+	if err := s.sealValue("k", tombstone, ts); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := s.openValue("k", tombstone); err != nil || !v.GetDeleted() {
+		t.Fatalf("sealed tombstone not accepted: %v, %v", v, err)
+	}
+
+	// a value with a TTL carries the seal of the tombstone replicas turn it into
+	expiring := &proto.StoredValue{Val: []byte("v"), Ts: ts, ExpiresAt: 1}
+	if err := s.sealValue("k", expiring, ts); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := s.openValue("k", common.Visible(expiring, time.Now())); err != nil || !v.GetDeleted() {
+		t.Errorf("expiry tombstone not accepted: %v, %v", v, err)
+	}
+
+	// a replica can't fake a delete: move the tombstone, forge one, or turn a value without a TTL into one
+	live := &proto.StoredValue{Val: []byte("v"), Ts: ts}
+	if err := s.sealValue("k", live, ts); err != nil {
+		t.Fatal(err)
+	}
+	forged := []func() (string, *proto.StoredValue){
+		func() (string, *proto.StoredValue) { return "other", tombstone },
+		func() (string, *proto.StoredValue) {
+			v := protobuf.Clone(tombstone).(*proto.StoredValue)
+			v.Ts.RequestNumber++
+			return "k", v
+		},
+		func() (string, *proto.StoredValue) { return "k", &proto.StoredValue{Ts: ts, Deleted: true, KeyID: "k1"} },
+		func() (string, *proto.StoredValue) { return "k", common.ExpiryTombstone(live) },
+	}
+	for i, forge := range forged {
+		if _, err := s.openValue(forge()); !errors.Is(err, ErrDecryption) {
+			t.Errorf("forgery %d: expected ErrDecryption, got %v", i, err)
+		}
+	}
+	if _, err := s.openValue("k", &proto.StoredValue{Ts: ts, Deleted: true}); !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("expected ErrNotEncrypted for an unsealed tombstone, got %v", err)
+	}
+}
+
+func TestValueKeyRotation(t *testing.T) {
+	k1, k2 := newKey(t), newKey(t)
+	old, rotated := &SharedRegisterClient{}, &SharedRegisterClient{}
+	old.EnableEncryption("k1", map[string][]byte{"k1": k1})
+	rotated.EnableEncryption("k2", map[string][]byte{"k1": k1, "k2": k2})
+
+	ts := &proto.TimeStamp{RequestNumber: 1, ClientID: "writer"}
+	before := &proto.StoredValue{Val: []byte("before"), Ts: ts}
+	after := &proto.StoredValue{Val: []byte("after"), Ts: ts}
+	old.sealValue("k", before, ts)
+	rotated.sealValue("k", after, ts)
+	if after.GetKeyID() != "k2" {
+		t.Fatalf("expected the new primary key, got %s", after.GetKeyID())
+	}
+	if v, err := rotated.openValue("k", before); err != nil || string(v.GetVal()) != "before" {
+		t.Errorf("value of the old key not readable after the rotation: %v", err)
+	}
+	if _, err := old.openValue("k", after); err == nil {
+		t.Error("value of the new key read with the old keys")
+	}
+	if err := old.EnableEncryption("k3", map[string][]byte{"k1": k1}); err == nil {
+		t.Error("missing primary key accepted")
+	}
+}
+
+func TestForgedValueNotWrittenBack(t *testing.T) {
+	s, err := CreateSharedRegisterClient(NewClientID(), _testServiceAddrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.EnableEncryption("k1", map[string][]byte{"k1": newKey(t)}); err != nil {
+		t.Fatal(err)
+	}
+	key := "forged-" + NewClientID()
+	if err := s.Write(key, "v"); err != nil {
+		t.Fatal(err)
+	}
+	// one replica stores a value nobody sealed under a huge timestamp
+	forged := &proto.StoredValue{Val: []byte("forged"), KeyID: "k1",
+		Ts: &proto.TimeStamp{RequestNumber: 1 << 60, ClientID: "mallory"}}
+	if err := s.replicaConns[0].SetPhase(&proto.SetPhaseReq{Key: key, Value: forged}); err != nil {
+		t.Fatal(err)
+	}
+	for _, read := range []func() error{
+		func() error { _, err := s.Read(key); return err },
+		func() error { _, _, err := s.ScanPrefix(key, 0, ""); return err },
+	} {
+		if err := read(); !errors.Is(err, ErrDecryption) {
+			t.Errorf("expected ErrDecryption, got %v", err)
+		}
+	}
+	for _, conn := range s.replicaConns[1:] {
+		rsp, err := conn.GetPhase(&proto.GetPhaseReq{Key: key})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rsp.GetValue().GetTs().GetClientID() == "mallory" {
+			t.Errorf("the forged value was written back to replica %d", conn.index)
+		}
+	}
+}
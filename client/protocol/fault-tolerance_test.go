@@ -1,8 +1,13 @@
 package protocol
 
 import (
+	"context"
+	"errors"
 	"log"
+	"shared-registers/common"
+	"shared-registers/common/proto"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -19,10 +24,15 @@ var (
 	}
 )
 
+// quorumSize is the number of replicas in the default majority quorums of the client
+func quorumSize(c *SharedRegisterClient) int {
+	return c.replicaNum/2 + 1
+}
+
 // Write phase failure tests
 func TestWriteFailBeforeGetPhase(t *testing.T) {
 	commandNum := 10
-	testClient, err := CreateSharedRegisterClient("testClient", _testServiceAddrs)
+	testClient, err := CreateSharedRegisterClient(NewClientID(), _testServiceAddrs)
 	if err != nil {
 		t.Error(err)
 	}
@@ -30,7 +40,7 @@ func TestWriteFailBeforeGetPhase(t *testing.T) {
 		t.Error("fail to connect to 5 replicas")
 	}
 	// simulate less than quorumSize replicas fail to process request before GetPhase
-	for i := 0; i < testClient.quorumSize-1; i++ {
+	for i := 0; i < quorumSize(testClient)-1; i++ {
 		testClient.replicaConns[i].GetPhaseMockFail = true
 		testClient.replicaConns[i].SetPhaseMockFail = true
 	}
@@ -50,7 +60,7 @@ func TestWriteFailBeforeGetPhase(t *testing.T) {
 
 func TestWriteFailBetweenTwoPhases(t *testing.T) {
 	commandNum := 10
-	testClient, err := CreateSharedRegisterClient("testClient", _testServiceAddrs)
+	testClient, err := CreateSharedRegisterClient(NewClientID(), _testServiceAddrs)
 	if err != nil {
 		t.Error(err)
 	}
@@ -58,7 +68,7 @@ func TestWriteFailBetweenTwoPhases(t *testing.T) {
 		t.Error("fail to connect to 5 replicas")
 	}
 	// simulate less than quorumSize replicas fail to process request after GetPhase but before SetPhase
-	for i := 0; i < testClient.quorumSize-1; i++ {
+	for i := 0; i < quorumSize(testClient)-1; i++ {
 		testClient.replicaConns[i].GetPhaseMockFail = false
 		testClient.replicaConns[i].SetPhaseMockFail = true
 	}
@@ -78,7 +88,7 @@ func TestWriteFailBetweenTwoPhases(t *testing.T) {
 
 func TestWriteAfterTwoPhases(t *testing.T) {
 	commandNum := 10
-	testClient, err := CreateSharedRegisterClient("testClient", _testServiceAddrs)
+	testClient, err := CreateSharedRegisterClient(NewClientID(), _testServiceAddrs)
 	if err != nil {
 		t.Error(err)
 	}
@@ -86,7 +96,7 @@ func TestWriteAfterTwoPhases(t *testing.T) {
 		t.Error("fail to connect to 5 replicas")
 	}
 	// use microsecond timeout to simulate the error could not receive the ack from replica
-	for i := 0; i < testClient.quorumSize-1; i++ {
+	for i := 0; i < quorumSize(testClient)-1; i++ {
 		testClient.replicaConns[i].RespMockFail = true
 	}
 
@@ -106,7 +116,7 @@ func TestWriteAfterTwoPhases(t *testing.T) {
 // Read phase failure tests
 func TestReadFailBeforeGetPhase(t *testing.T) {
 	commandNum := 10
-	testClient, err := CreateSharedRegisterClient("testClient", _testServiceAddrs)
+	testClient, err := CreateSharedRegisterClient(NewClientID(), _testServiceAddrs)
 	if err != nil {
 		t.Error(err)
 	}
@@ -124,7 +134,7 @@ func TestReadFailBeforeGetPhase(t *testing.T) {
 	}
 
 	// simulate less than quorumSize replicas fail to process request before Read GetPhase
-	for i := 0; i < testClient.quorumSize-1; i++ {
+	for i := 0; i < quorumSize(testClient)-1; i++ {
 		testClient.replicaConns[i].GetPhaseMockFail = true
 		testClient.replicaConns[i].SetPhaseMockFail = true
 	}
@@ -141,7 +151,7 @@ func TestReadFailBeforeGetPhase(t *testing.T) {
 
 func TestReadFailBetweenTwoPhases(t *testing.T) {
 	commandNum := 10
-	testClient, err := CreateSharedRegisterClient("testClient", _testServiceAddrs)
+	testClient, err := CreateSharedRegisterClient(NewClientID(), _testServiceAddrs)
 	if err != nil {
 		t.Error(err)
 	}
@@ -158,7 +168,7 @@ func TestReadFailBetweenTwoPhases(t *testing.T) {
 	}
 
 	// simulate less than quorumSize replicas fail to process request between Read GetPhase and SetPhase
-	for i := 0; i < testClient.quorumSize-1; i++ {
+	for i := 0; i < quorumSize(testClient)-1; i++ {
 		testClient.replicaConns[i].GetPhaseMockFail = false
 		testClient.replicaConns[i].SetPhaseMockFail = true
 	}
@@ -175,7 +185,7 @@ func TestReadFailBetweenTwoPhases(t *testing.T) {
 
 func TestReadAfterTwoPhases(t *testing.T) {
 	commandNum := 10
-	testClient, err := CreateSharedRegisterClient("testClient", _testServiceAddrs)
+	testClient, err := CreateSharedRegisterClient(NewClientID(), _testServiceAddrs)
 	if err != nil {
 		t.Error(err)
 	}
@@ -193,7 +203,7 @@ func TestReadAfterTwoPhases(t *testing.T) {
 	}
 
 	// use microsecond timeout to simulate the error could not receive the ack from replica
-	for i := 0; i < testClient.quorumSize-1; i++ {
+	for i := 0; i < quorumSize(testClient)-1; i++ {
 		testClient.replicaConns[i].RespMockFail = true
 	}
 
@@ -211,7 +221,7 @@ func TestReadAfterTwoPhases(t *testing.T) {
 
 func TestReadFailsAfterMajorityFailure(t *testing.T) {
 	commandNum := 10
-	testClient, err := CreateSharedRegisterClient("testClient", _testServiceAddrs)
+	testClient, err := CreateSharedRegisterClient(NewClientID(), _testServiceAddrs)
 	if err != nil {
 		t.Error(err)
 	}
@@ -229,7 +239,7 @@ func TestReadFailsAfterMajorityFailure(t *testing.T) {
 	}
 
 	// use microsecond timeout to simulate the error could not receive the ack from replica
-	for i := 0; i < testClient.quorumSize; i++ {
+	for i := 0; i < quorumSize(testClient); i++ {
 		testClient.replicaConns[i].RespMockFail = true
 	}
 
@@ -245,7 +255,7 @@ func TestReadFailsAfterMajorityFailure(t *testing.T) {
 
 func TestWriteFailsAfterMajorityFailure(t *testing.T) {
 	commandNum := 10
-	testClient, err := CreateSharedRegisterClient("testClient", _testServiceAddrs)
+	testClient, err := CreateSharedRegisterClient(NewClientID(), _testServiceAddrs)
 	if err != nil {
 		t.Error(err)
 	}
@@ -253,7 +263,7 @@ func TestWriteFailsAfterMajorityFailure(t *testing.T) {
 		t.Error("fail to connect to 5 replicas")
 	}
 	// use microsecond timeout to simulate the error could not receive the ack from replica
-	for i := 0; i < testClient.quorumSize; i++ {
+	for i := 0; i < quorumSize(testClient); i++ {
 		testClient.replicaConns[i].RespMockFail = true
 	}
 
@@ -270,6 +280,252 @@ func TestWriteFailsAfterMajorityFailure(t *testing.T) {
 	}
 }
 
+// erasure coding failure tests
+func TestCodedWriteAndReadWithFailures(t *testing.T) {
+	commandNum := 10
+	testClient, err := CreateSharedRegisterClient(NewClientID(), _testServiceAddrs)
+	if err != nil {
+		t.Error(err)
+	}
+	// k=3 out of 5 fragments, each phase waits for 4 replicas so at most 1 replica may fail
+	if err := testClient.EnableErasureCoding(3); err != nil {
+		t.Fatal(err)
+	}
+	testClient.replicaConns[0].GetPhaseMockFail = true
+	testClient.replicaConns[0].SetPhaseMockFail = true
+
+	for i := 0; i < commandNum; i++ {
+		key, value := "FK"+strconv.Itoa(i), "FV"+strconv.Itoa(i)
+		err := testClient.Write(key, value)
+		if err != nil {
+			t.Errorf("Failed write: key=%s", key)
+		}
+		result, err := testClient.Read(key)
+		if err != nil || result != value {
+			t.Errorf("Incorrect read: key=%s, actualValue=%s, expectedValue=%s", key, result, value)
+		}
+	}
+
+	// a second failure leaves only 3 replicas, less than the coded quorum
+	testClient.replicaConns[1].GetPhaseMockFail = true
+	testClient.replicaConns[1].SetPhaseMockFail = true
+	if err := testClient.Write("FK0", "FV0"); err == nil {
+		t.Errorf("TEST FAILED: Expected timeout error on write call")
+	}
+}
+
+func TestChunkedWriteAndDelete(t *testing.T) {
+	testClient, err := CreateSharedRegisterClient(NewClientID(), _testServiceAddrs)
+	if err != nil {
+		t.Error(err)
+	}
+	testClient.ChunkThreshold = 1000
+	testClient.ChunkSize = 300
+	testClient.replicaConns[0].GetPhaseMockFail = true
+	testClient.replicaConns[0].SetPhaseMockFail = true
+
+	value := strings.Repeat("0123456789", 250)
+	if err := testClient.Write("BK", value); err != nil {
+		t.Errorf("Failed write: key=BK")
+	}
+	result, err := testClient.Read("BK")
+	if err != nil || result != value {
+		t.Errorf("Incorrect read: key=BK, err=%v, len=%d", err, len(result))
+	}
+	manifest, err := testClient.readRaw("BK")
+	if err != nil || len(manifest.GetManifest().GetChunks()) != 9 {
+		t.Fatalf("expected a manifest of 9 chunks, got %v %v", manifest, err)
+	}
+
+	// overwriting the key deletes the chunks of the old value
+	if err := testClient.Write("BK", "small"); err != nil {
+		t.Errorf("Failed write: key=BK")
+	}
+	oldChunk := chunkKey("BK", manifest.GetManifest().GetWriteID(), manifest.GetManifest().GetChunks()[0])
+	if _, err := testClient.readRaw(oldChunk); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("TEST FAILED: chunk of the old value wasn't deleted: %v", err)
+	}
+
+	if err := testClient.Delete("BK"); err != nil {
+		t.Errorf("Failed delete: key=BK")
+	}
+	if _, err := testClient.Read("BK"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("TEST FAILED: Expected ErrKeyNotFound after delete, got %v", err)
+	}
+}
+
+func TestExpiringWriteWithFailures(t *testing.T) {
+	testClient, err := CreateSharedRegisterClient(NewClientID(), _testServiceAddrs)
+	if err != nil {
+		t.Error(err)
+	}
+	testClient.replicaConns[0].GetPhaseMockFail = true
+	testClient.replicaConns[0].SetPhaseMockFail = true
+
+	if err := testClient.WriteWithTTL("EK", "temporary", time.Second); err != nil {
+		t.Errorf("Failed write: key=EK")
+	}
+	if result, err := testClient.Read("EK"); err != nil || result != "temporary" {
+		t.Errorf("Incorrect read before expiry: key=EK, result=%s, err=%v", result, err)
+	}
+	entries, _, err := testClient.ScanPrefix("EK", 0, "")
+	if err != nil || len(entries) != 1 {
+		t.Errorf("expected EK to be listed before expiry, got %v %v", entries, err)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if _, err := testClient.Read("EK"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("TEST FAILED: Expected ErrKeyNotFound after expiry, got %v", err)
+	}
+	entries, _, err = testClient.ScanPrefix("EK", 0, "")
+	if err != nil || len(entries) != 0 {
+		t.Errorf("expected no entries after expiry, got %v %v", entries, err)
+	}
+
+	// a write without TTL brings the key back for good
+	if err := testClient.Write("EK", "permanent"); err != nil {
+		t.Errorf("Failed write: key=EK")
+	}
+	time.Sleep(1500 * time.Millisecond)
+	if result, err := testClient.Read("EK"); err != nil || result != "permanent" {
+		t.Errorf("Incorrect read: key=EK, result=%s, err=%v", result, err)
+	}
+}
+
+func TestCompareAndSwapWithFailures(t *testing.T) {
+	clients := make([]*SharedRegisterClient, 3)
+	for i := range clients {
+		client, err := CreateSharedRegisterClient(NewClientID(), _testServiceAddrs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.replicaConns[0].GetPhaseMockFail = true
+		client.replicaConns[0].SetPhaseMockFail = true
+		clients[i] = client
+	}
+	key := "CAS" + NewClientID()
+	if ok, err := clients[0].CompareAndSwap(key, "nope", "1"); ok || err != nil {
+		t.Fatalf("swap of a missing key with a wrong expectation succeeded: %v %v", ok, err)
+	}
+
+	// every client increments the counter 5 times, a lost update would show in the total
+	var wg sync.WaitGroup
+	for _, client := range clients {
+		wg.Add(1)
+		go func(client *SharedRegisterClient) {
+			defer wg.Done()
+			for done := 0; done < 5; {
+				current, err := client.Read(key)
+				if errors.Is(err, ErrKeyNotFound) {
+					current = ""
+				} else if err != nil {
+					t.Error(err)
+					return
+				}
+				n, _ := strconv.Atoi(current)
+				ok, err := client.CompareAndSwap(key, current, strconv.Itoa(n+1))
+				if err != nil && err != ErrSwapContention {
+					t.Error(err)
+					return
+				}
+				if ok {
+					done++
+				}
+			}
+		}(client)
+	}
+	wg.Wait()
+	if result, err := clients[0].Read(key); err != nil || result != "15" {
+		t.Errorf("TEST FAILED: expected 15 increments, got %s %v", result, err)
+	}
+}
+
+func TestScanWithFailures(t *testing.T) {
+	commandNum := 10
+	testClient, err := CreateSharedRegisterClient(NewClientID(), _testServiceAddrs)
+	if err != nil {
+		t.Error(err)
+	}
+	prefix := testClient.ClientID + "/"
+	// every other key misses two replicas, the scan has to find and write them back
+	for i := 0; i < commandNum; i++ {
+		testClient.replicaConns[0].SetPhaseMockFail = i%2 == 0
+		testClient.replicaConns[1].SetPhaseMockFail = i%2 == 0
+		key, value := prefix+"SK"+strconv.Itoa(i), "SV"+strconv.Itoa(i)
+		if err := testClient.Write(key, value); err != nil {
+			t.Errorf("Failed write: key=%s", key)
+		}
+	}
+	testClient.replicaConns[0].SetPhaseMockFail = false
+	testClient.replicaConns[1].SetPhaseMockFail = false
+	testClient.replicaConns[4].GetPhaseMockFail = true
+
+	found := make(map[string]string)
+	pageToken := ""
+	for {
+		entries, next, err := testClient.ScanPrefix(prefix, 3, pageToken)
+		if err != nil {
+			t.Fatalf("Failed scan: %v", err)
+		}
+		if len(entries) > 3 {
+			t.Errorf("TEST FAILED: page of %d entries exceeds the limit", len(entries))
+		}
+		for _, e := range entries {
+			found[e.GetKey()] = string(e.GetValue().GetVal())
+		}
+		if next == "" {
+			break
+		}
+		pageToken = next
+	}
+	for i := 0; i < commandNum; i++ {
+		key, value := prefix+"SK"+strconv.Itoa(i), "SV"+strconv.Itoa(i)
+		if found[key] != value {
+			t.Errorf("Incorrect scan: key=%s, actualValue=%s, expectedValue=%s", key, found[key], value)
+		}
+	}
+}
+
+func TestWatchWithFailures(t *testing.T) {
+	commandNum := 10
+	writer, err := CreateSharedRegisterClient(NewClientID(), _testServiceAddrs)
+	if err != nil {
+		t.Error(err)
+	}
+	watcher, err := CreateSharedRegisterClient(NewClientID(), _testServiceAddrs)
+	if err != nil {
+		t.Error(err)
+	}
+	// one replica refuses the watch stream, the others still form a quorum
+	watcher.replicaConns[0].GetPhaseMockFail = true
+	key := writer.ClientID + "/WK"
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	events, err := watcher.Watch(ctx, key, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for i := 0; i < commandNum; i++ {
+			if err := writer.Write(key, "WV"+strconv.Itoa(i)); err != nil {
+				t.Errorf("Failed write: key=%s", key)
+			}
+		}
+	}()
+	var lastTs *proto.TimeStamp
+	for event := range events {
+		if common.CompareTimeStamps(event.GetValue().GetTs(), lastTs) <= 0 {
+			t.Errorf("TEST FAILED: watch delivered %v after %v", event.GetValue().GetTs(), lastTs)
+		}
+		lastTs = event.GetValue().GetTs()
+		if string(event.GetValue().GetVal()) == "WV"+strconv.Itoa(commandNum-1) {
+			return
+		}
+	}
+	t.Errorf("TEST FAILED: the last write wasn't delivered")
+}
+
 // multiple clients test
 
 func TestMultipleClientsWithFailures(t *testing.T) {
@@ -299,7 +555,7 @@ func TestMultipleClientsWithFailures(t *testing.T) {
 
 				// simulate less than quorumSize replicas fail to process request between Read GetPhase and SetPhase of client number 5
 				if clientId == 5 {
-					for i := 0; i < client.quorumSize-1; i++ {
+					for i := 0; i < quorumSize(client)-1; i++ {
 						client.replicaConns[i].GetPhaseMockFail = false
 						client.replicaConns[i].SetPhaseMockFail = true
 					}
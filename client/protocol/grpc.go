@@ -2,13 +2,20 @@ package protocol
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
 	"log"
 	"shared-registers/client/util"
 	"shared-registers/common/proto"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,22 +23,81 @@ type grpcClient struct {
 	conn                                             *grpc.ClientConn
 	c                                                proto.SharedRegistersClient
 	requestTimeOut                                   time.Duration
+	index                                            int // position of the replica in the address list
 	DebugMode                                        bool
 	SetPhaseMockFail, GetPhaseMockFail, RespMockFail bool
+	compress                                         int32 // 1 to gzip requests, accessed atomically
 }
 
-func createGrpcClient(addr string) (*grpcClient, error) {
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// ConnOptions configures the connections to the replicas
+type ConnOptions struct {
+	TLS *tls.Config // connect over TLS with this config, in cleartext if nil
+	// Token is sent as bearer token with every request, replicas with an ACL authenticate the client
+	// with it. It is only sent over TLS.
+	Token string
+}
+
+// bearerToken sends the token in the metadata replicas authenticate callers with
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t bearerToken) RequireTransportSecurity() bool {
+	return true
+}
+
+// dialOptions returns the credentials of the options
+func dialOptions(options ConnOptions) []grpc.DialOption {
+	creds := insecure.NewCredentials()
+	if options.TLS != nil {
+		creds = credentials.NewTLS(options.TLS)
+	}
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if options.Token != "" {
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(bearerToken(options.Token)))
+	}
+	return dialOptions
+}
+
+// Dial connects to a single replica like the clients do, e.g. to call its Admin service
+func Dial(addr string, options ConnOptions) (*grpc.ClientConn, error) {
+	return grpc.Dial(addr, dialOptions(options)...)
+}
+
+// createGrpcClient connects to the replica over TLS if options.TLS is set, in cleartext otherwise
+func createGrpcClient(addr string, index int, options ConnOptions) (*grpcClient, error) {
+	g := &grpcClient{
+		requestTimeOut: 500 * time.Millisecond,
+		index:          index,
+	}
+	conn, err := grpc.Dial(addr, append(dialOptions(options), grpc.WithUnaryInterceptor(g.compressionInterceptor))...)
 	if err != nil || conn == nil {
 		log.Printf("did not connect to %s: %v", addr, err)
 		return nil, err
 	}
+	g.conn = conn
+	g.c = proto.NewSharedRegistersClient(conn)
+	return g, nil
+}
 
-	return &grpcClient{
-		conn:           conn,
-		c:              proto.NewSharedRegistersClient(conn),
-		requestTimeOut: 500 * time.Millisecond,
-	}, nil
+// compressionInterceptor
+// gzip the requests while compression is on, the replica answers with the same compressor. A replica
+// without the gzip compressor rejects the request as Unimplemented, the connection then falls back
+// to uncompressed requests and retries, which is safe since every phase is idempotent.
+func (g *grpcClient) compressionInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if atomic.LoadInt32(&g.compress) == 0 {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	err := invoker(ctx, method, req, reply, cc, append(opts, grpc.UseCompressor(gzip.Name))...)
+	if status.Code(err) == codes.Unimplemented && strings.Contains(status.Convert(err).Message(), "grpc-encoding") {
+		log.Printf("%s doesn't support %s compression, sending uncompressed requests", cc.Target(), gzip.Name)
+		atomic.StoreInt32(&g.compress, 0)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	return err
 }
 
 func (g *grpcClient) SetPhase(req *proto.SetPhaseReq) error {
@@ -79,3 +145,152 @@ func (g *grpcClient) GetPhase(req *proto.GetPhaseReq) (*proto.GetPhaseRsp, error
 
 	return rsp, nil
 }
+
+func (g *grpcClient) CodedQuery(req *proto.CodedQueryReq) (*proto.CodedQueryRsp, error) {
+	if g.DebugMode {
+		defer util.PrintFuncExeTime("CodedQuery", time.Now())
+	}
+	if g.GetPhaseMockFail {
+		log.Printf("%s GetPhaseMockFail\n", g.conn.Target())
+		return nil, errors.New(fmt.Sprintf("%s CodedQuery failed: MockError", g.conn.Target()))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), g.requestTimeOut)
+	defer cancel()
+	rsp, err := g.c.CodedQuery(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if g.RespMockFail {
+		log.Printf("%s RespMockFail\n", g.conn.Target())
+		return nil, errors.New(fmt.Sprintf("%s CodedQuery failed: MockError", g.conn.Target()))
+	}
+	return rsp, nil
+}
+
+func (g *grpcClient) CodedPreWrite(req *proto.CodedPreWriteReq) error {
+	if g.DebugMode {
+		defer util.PrintFuncExeTime("CodedPreWrite", time.Now())
+	}
+	if g.SetPhaseMockFail {
+		log.Printf("%s SetPhaseMockFail\n", g.conn.Target())
+		return errors.New(fmt.Sprintf("%s CodedPreWrite failed: MockError", g.conn.Target()))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), g.requestTimeOut)
+	defer cancel()
+	_, err := g.c.CodedPreWrite(ctx, req)
+	if err != nil {
+		return err
+	}
+	if g.RespMockFail {
+		log.Printf("%s RespMockFail\n", g.conn.Target())
+		return errors.New(fmt.Sprintf("%s CodedPreWrite failed: MockError", g.conn.Target()))
+	}
+	return nil
+}
+
+func (g *grpcClient) CodedFinalize(req *proto.CodedFinalizeReq) (*proto.CodedFinalizeRsp, error) {
+	if g.DebugMode {
+		defer util.PrintFuncExeTime("CodedFinalize", time.Now())
+	}
+	if g.SetPhaseMockFail {
+		log.Printf("%s SetPhaseMockFail\n", g.conn.Target())
+		return nil, errors.New(fmt.Sprintf("%s CodedFinalize failed: MockError", g.conn.Target()))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), g.requestTimeOut)
+	defer cancel()
+	rsp, err := g.c.CodedFinalize(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if g.RespMockFail {
+		log.Printf("%s RespMockFail\n", g.conn.Target())
+		return nil, errors.New(fmt.Sprintf("%s CodedFinalize failed: MockError", g.conn.Target()))
+	}
+	return rsp, nil
+}
+
+func (g *grpcClient) RegisterClient(req *proto.RegisterClientReq) error {
+	if g.DebugMode {
+		defer util.PrintFuncExeTime("RegisterClient", time.Now())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), g.requestTimeOut)
+	defer cancel()
+	_, err := g.c.RegisterClient(ctx, req)
+	return err
+}
+
+func (g *grpcClient) Scan(req *proto.ScanReq) (*proto.ScanRsp, error) {
+	if g.DebugMode {
+		defer util.PrintFuncExeTime("Scan", time.Now())
+	}
+	if g.GetPhaseMockFail {
+		log.Printf("%s GetPhaseMockFail\n", g.conn.Target())
+		return nil, errors.New(fmt.Sprintf("%s Scan failed: MockError", g.conn.Target()))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), g.requestTimeOut)
+	defer cancel()
+	rsp, err := g.c.Scan(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if g.RespMockFail {
+		log.Printf("%s RespMockFail\n", g.conn.Target())
+		return nil, errors.New(fmt.Sprintf("%s Scan failed: MockError", g.conn.Target()))
+	}
+	return rsp, nil
+}
+
+func (g *grpcClient) PaxosPrepare(req *proto.PaxosPrepareReq) (*proto.PaxosPrepareRsp, error) {
+	if g.DebugMode {
+		defer util.PrintFuncExeTime("PaxosPrepare", time.Now())
+	}
+	if g.GetPhaseMockFail {
+		log.Printf("%s GetPhaseMockFail\n", g.conn.Target())
+		return nil, errors.New(fmt.Sprintf("%s PaxosPrepare failed: MockError", g.conn.Target()))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), g.requestTimeOut)
+	defer cancel()
+	rsp, err := g.c.PaxosPrepare(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if g.RespMockFail {
+		log.Printf("%s RespMockFail\n", g.conn.Target())
+		return nil, errors.New(fmt.Sprintf("%s PaxosPrepare failed: MockError", g.conn.Target()))
+	}
+	return rsp, nil
+}
+
+func (g *grpcClient) PaxosAccept(req *proto.PaxosAcceptReq) (*proto.PaxosAcceptRsp, error) {
+	if g.DebugMode {
+		defer util.PrintFuncExeTime("PaxosAccept", time.Now())
+	}
+	if g.SetPhaseMockFail {
+		log.Printf("%s SetPhaseMockFail\n", g.conn.Target())
+		return nil, errors.New(fmt.Sprintf("%s PaxosAccept failed: MockError", g.conn.Target()))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), g.requestTimeOut)
+	defer cancel()
+	rsp, err := g.c.PaxosAccept(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if g.RespMockFail {
+		log.Printf("%s RespMockFail\n", g.conn.Target())
+		return nil, errors.New(fmt.Sprintf("%s PaxosAccept failed: MockError", g.conn.Target()))
+	}
+	return rsp, nil
+}
+
+// Watch opens the stream of the replica, it stays open until ctx is canceled or the replica fails
+func (g *grpcClient) Watch(ctx context.Context, req *proto.WatchReq) (proto.SharedRegisters_WatchClient, error) {
+	if g.GetPhaseMockFail {
+		log.Printf("%s GetPhaseMockFail\n", g.conn.Target())
+		return nil, errors.New(fmt.Sprintf("%s Watch failed: MockError", g.conn.Target()))
+	}
+	return g.c.Watch(ctx, req)
+}
+
+func (g *grpcClient) Close() error {
+	return g.conn.Close()
+}
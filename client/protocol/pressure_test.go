@@ -7,6 +7,7 @@ import (
 	"math/rand"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -242,6 +243,69 @@ func BenchmarkReadAndWrite(b *testing.B) {
 	log.Println("Finish Benchmark Read and Write")
 }
 
+// compare text-heavy values with and without compression, the output has the format of
+// test_results/PartA.txt so the rows can be compared with the baseline
+// go test -run BenchmarkCompression -v -bench=BenchmarkCompression &> out_compression.log
+func BenchmarkCompression(b *testing.B) {
+	value := strings.Repeat("2023-04-01T12:00:00Z INFO request served path=/api/v1/registers status=200\n", 50)
+	configs := []struct {
+		name      string
+		configure func(*SharedRegisterClient)
+	}{
+		{"Uncompressed", func(*SharedRegisterClient) {}},
+		{"Wire gzip", func(c *SharedRegisterClient) { c.EnableWireCompression() }},
+		{"Stored gzip", func(c *SharedRegisterClient) { c.CompressionThreshold = 512 }},
+	}
+	for numClients := 1; numClients <= 64; numClients *= 2 {
+		for _, config := range configs {
+			throughPutPerSec, avgLatency := testTextValues(numClients, value, config.configure, b)
+			b.Logf("%s\t numClient=%d\t totalThroughput=%f averageLatency=%f\n", config.name, numClients, throughPutPerSec, avgLatency)
+		}
+	}
+}
+
+// testTextValues runs the R And W workload of testReadAndWrite with the given value
+func testTextValues(numClients int, value string, configure func(*SharedRegisterClient), t *testing.B) (float64, float64) {
+	var wg sync.WaitGroup
+	var totalCommandCount uint32 = 0
+	avgLatencyChannel := make(chan uint64, numClients)
+	wg.Add(numClients)
+	startTime := time.Now()
+	for clientId := 1; clientId <= numClients; clientId++ {
+		go func(clientId int) {
+			defer wg.Done()
+			var avgLatency uint64 = 0
+			client, err := CreateSharedRegisterClient("clientText"+strconv.Itoa(clientId), _testServiceAddrs)
+			if err != nil {
+				log.Fatalf("CreateSharedRegisterClient err: %v %d", err, clientId)
+			}
+			configure(client)
+
+			var commandCount uint64 = 0
+			for start := time.Now(); time.Since(start) < time.Second*10; {
+				operationStart := time.Now()
+				key := "t" + generateRandomIntString()
+				if err := client.Write(key, value); err != nil {
+					t.Errorf("Failed write: key=%s", key)
+				}
+				result, err := client.Read(key)
+				if err == nil && result != value {
+					t.Errorf("Incorrect read: key=%s", key)
+				}
+				avgLatency = (uint64(time.Since(operationStart).Microseconds()) + avgLatency*commandCount) / (commandCount + 2)
+				commandCount += 2
+				atomic.AddUint32(&totalCommandCount, 2)
+			}
+			avgLatencyChannel <- avgLatency
+		}(clientId)
+	}
+	wg.Wait()
+	throughPutPerSec := float64(totalCommandCount) / (float64(time.Since(startTime)) / float64(time.Second))
+	close(avgLatencyChannel)
+	avgLatency := averageChannel(avgLatencyChannel)
+	return throughPutPerSec, float64(avgLatency) / 1000 // convert to milliseconds
+}
+
 func averageChannel(c chan uint64) uint64 {
 	var sum uint64
 	var count uint64 = 0
@@ -1,12 +1,18 @@
 package protocol
 
 import (
+	"crypto/tls"
 	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"log"
+	"shared-registers/client/erasure"
 	"shared-registers/client/util"
 	"shared-registers/common"
 	"shared-registers/common/proto"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,12 +20,74 @@ type SharedRegisterClient struct {
 	ClientID     string
 	PhaseTimeout time.Duration // the max waiting time from all the replicas each phase, default 1s
 	replicaConns []*grpcClient
-	quorumSize   int        // len(replicaConns) / 2 + 1
-	opsLock      sync.Mutex // each SharedRegisterClient should only execute operations sequentially
+	replicaNum   int          // len(serverAddrs), including the replicas failed to connect
+	quorum       atomic.Value // quorumHolder, SetQuorumSystem replaces it while leases renew in the background
+	opsLock      sync.Mutex   // each SharedRegisterClient should only execute operations sequentially
 	DebugMode    bool
+
+	coder           *erasure.Encoder // nil if registers are fully replicated
+	codedQuorumSize int              // ceil((n + k) / 2) in erasure coding mode
+
+	clock *common.HybridClock // nil if timestamps only use request numbers
+
+	registration *clientRegistration // nil until RegisterClientID
+
+	ChunkThreshold int // values larger than this are split into chunks, 0 disables chunking, default 1MiB
+	ChunkSize      int // size of each chunk, default 256KiB
+
+	CompressionThreshold int // values at least this large are stored gzip-compressed, 0 disables compression
+	DecompressionLimit   int // compressed values may inflate to at most this many bytes, default 64MiB
+
+	encryption      *valueKeys // nil unless EnableEncryption
+	AcceptPlaintext bool       // read plaintext values although encryption is on
+}
+
+// ErrKeyNotFound is matched by errors.Is when the key was never written or was deleted
+var ErrKeyNotFound = errors.New("key doesn't exist")
+
+type KeyNotFoundError struct {
+	Key string
+}
+
+func (e *KeyNotFoundError) Error() string {
+	return "key " + e.Key + " doesn't exist"
+}
+
+func (e *KeyNotFoundError) Is(target error) bool {
+	return target == ErrKeyNotFound
+}
+
+// ErrQuorumUnavailable is matched by errors.Is when too few replicas answered a phase within PhaseTimeout
+var ErrQuorumUnavailable = errors.New("quorum unavailable")
+
+type PhaseTimeoutError struct {
+	Phase string
+}
+
+func (e *PhaseTimeoutError) Error() string {
+	return e.Phase + " timeout"
+}
+
+func (e *PhaseTimeoutError) Is(target error) bool {
+	return target == ErrQuorumUnavailable
 }
 
 func CreateSharedRegisterClient(clientID string, serverAddrs []string) (*SharedRegisterClient, error) {
+	return CreateSharedRegisterClientWithTLS(clientID, serverAddrs, nil)
+}
+
+// CreateSharedRegisterClientWithTLS
+// same as CreateSharedRegisterClient, but every replica connection uses TLS with the given config,
+// see common.ClientTLSConfig for one that verifies the replicas and presents a client certificate
+func CreateSharedRegisterClientWithTLS(clientID string, serverAddrs []string, tlsConfig *tls.Config) (*SharedRegisterClient, error) {
+	return CreateSharedRegisterClientWithOptions(clientID, serverAddrs, ConnOptions{TLS: tlsConfig})
+}
+
+// CreateSharedRegisterClientWithOptions
+// same as CreateSharedRegisterClient, with the replica connections configured by options, e.g. to
+// authenticate with a bearer token to replicas with an ACL, which then only accept writes stamped
+// with the client IDs of the token's principal
+func CreateSharedRegisterClientWithOptions(clientID string, serverAddrs []string, options ConnOptions) (*SharedRegisterClient, error) {
 	// could add dedup logic in server as well
 	if clientID == "" {
 		return nil, errors.New("invalid client ID")
@@ -28,11 +96,14 @@ func CreateSharedRegisterClient(clientID string, serverAddrs []string) (*SharedR
 		return nil, errors.New("empty server addresses")
 	}
 	s := &SharedRegisterClient{
-		ClientID:     clientID,
-		PhaseTimeout: time.Second,
+		ClientID:       clientID,
+		PhaseTimeout:   time.Second,
+		replicaNum:     len(serverAddrs),
+		ChunkThreshold: DefaultChunkThreshold,
+		ChunkSize:      DefaultChunkSize,
 	}
-	for _, addr := range serverAddrs {
-		c, err := createGrpcClient(addr)
+	for i, addr := range serverAddrs {
+		c, err := createGrpcClient(addr, i, options)
 		if err != nil || c == nil {
 			log.Printf("did not connect to %s: %v", addr, err)
 			continue
@@ -43,50 +114,209 @@ func CreateSharedRegisterClient(clientID string, serverAddrs []string) (*SharedR
 		return nil, errors.New("have to connect to at least 3 replicas to continue")
 	}
 	//log.Printf("connected to %d servers\n", len(s.replicaConns))
-	s.quorumSize = len(s.replicaConns)/2 + 1
+	// majorities of all the addresses, majorities of the connected replicas alone don't intersect
+	quorum := MajorityQuorum{N: s.replicaNum}
+	if err := s.checkQuorumSystem(quorum); err != nil {
+		return nil, err
+	}
+	s.quorum.Store(quorumHolder{quorum})
 	return s, nil
 }
 
 func (s *SharedRegisterClient) Write(key string, value string) error {
+	return s.WriteBytes(key, []byte(value), "")
+}
+
+// WriteWithTTL writes a value that reads as deleted once ttl has passed since the write
+func (s *SharedRegisterClient) WriteWithTTL(key string, value string, ttl time.Duration) error {
+	return s.WriteBytesWithTTL(key, []byte(value), "", ttl)
+}
+
+// WriteBytes
+// write an arbitrary binary value, contentType optionally describes its format (e.g. application/json)
+// and is returned by ReadBytes along with the value
+func (s *SharedRegisterClient) WriteBytes(key string, value []byte, contentType string) error {
+	return s.WriteBytesWithTTL(key, value, contentType, 0)
+}
+
+// WriteBytesWithTTL
+// same as WriteBytes, but the value expires ttl after the wall time of its timestamp, 0 for never.
+// Every replica hides the value from then on and eventually reclaims it, reads return ErrKeyNotFound.
+func (s *SharedRegisterClient) WriteBytesWithTTL(key string, value []byte, contentType string, ttl time.Duration) error {
+	_, err := s.WriteBytesWithTimeStamp(key, value, contentType, ttl)
+	return err
+}
+
+// WriteBytesWithTimeStamp
+// same as WriteBytesWithTTL, but also returns the timestamp the value was written with, which later
+// reads of the value return along with it until it is overwritten
+func (s *SharedRegisterClient) WriteBytesWithTimeStamp(key string, value []byte, contentType string, ttl time.Duration) (*proto.TimeStamp, error) {
 	s.opsLock.Lock()
 	defer s.opsLock.Unlock()
 	if s.DebugMode {
 		defer util.PrintFuncExeTime("Write", time.Now())
 	}
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	return s.writeValue(key, &proto.StoredValue{Val: value, ContentType: contentType}, ttl)
+}
 
-	latestValue, err := s.completeGetPhase(key)
-	if err != nil {
-		return err
+// Delete
+// remove the key by writing a tombstone, reads of the key fail with ErrKeyNotFound until it is
+// written again
+func (s *SharedRegisterClient) Delete(key string) error {
+	s.opsLock.Lock()
+	defer s.opsLock.Unlock()
+	if s.DebugMode {
+		defer util.PrintFuncExeTime("Delete", time.Now())
 	}
-	newTs := &proto.TimeStamp{
-		RequestNumber: latestValue.GetTs().GetRequestNumber() + 1,
-		ClientID:      s.ClientID,
+	if err := checkKey(key); err != nil {
+		return err
 	}
-	return s.completeSetPhase(key, value, newTs)
+	_, err := s.writeValue(key, &proto.StoredValue{Deleted: true}, 0)
+	return err
 }
 
 func (s *SharedRegisterClient) Read(key string) (string, error) {
+	value, _, err := s.ReadWithTimeStamp(key)
+	return value, err
+}
+
+// ReadBytes returns the binary value of the key and its content type
+func (s *SharedRegisterClient) ReadBytes(key string) ([]byte, string, error) {
+	value, err := s.ReadValue(key)
+	if err != nil {
+		return nil, "", err
+	}
+	return value.GetVal(), value.GetContentType(), nil
+}
+
+// ReadWithTimeStamp
+// same as Read, but also returns the timestamp of the value, common.WallTime tells approximately
+// when the value was written if the writer used the HLC timestamp scheme
+func (s *SharedRegisterClient) ReadWithTimeStamp(key string) (string, *proto.TimeStamp, error) {
+	value, err := s.ReadValue(key)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(value.GetVal()), value.GetTs(), nil
+}
+
+// ReadValue returns the whole <value, ts> pair of the key as stored by the replicas
+func (s *SharedRegisterClient) ReadValue(key string) (*proto.StoredValue, error) {
 	s.opsLock.Lock()
 	defer s.opsLock.Unlock()
 	if s.DebugMode {
 		defer util.PrintFuncExeTime("Read", time.Now())
 	}
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	return s.readValue(key)
+}
+
+func checkKey(key string) error {
+	if common.IsInternalKey(key) {
+		return errors.New("keys starting with " + strconv.Quote(common.InternalKeyPrefix) + " are reserved")
+	}
+	return nil
+}
 
+// writeRaw
+// choose the timestamp of the new value and store it on a quorum as is, returns the latest value
+// the get phase found (for erasure-coded registers without its val, see completeCodedWrite).
+// The caller holds opsLock.
+func (s *SharedRegisterClient) writeRaw(key string, value *proto.StoredValue, ttl time.Duration) (*proto.StoredValue, error) {
+	if s.coder != nil {
+		return s.completeCodedWrite(key, value, ttl)
+	}
 	latestValue, err := s.completeGetPhase(key)
-	if latestValue == nil {
-		return "", errors.New("key " + key + " doesn't exist")
+	if err != nil {
+		return nil, err
 	}
+	value.Ts, err = s.nextTimeStamp(latestValue.GetTs())
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	stampExpiry(value, value.Ts, ttl)
+	if err := s.sealValue(key, value, value.Ts); err != nil {
+		return nil, err
+	}
+	return latestValue, s.completeSetPhase(key, value)
+}
+
+// stampExpiry
+// derive the expiry of a TTL write from its timestamp, so all the replicas hide the value at the
+// same time no matter when they received it. Without the HLC scheme the timestamp gets the current
+// wall time, which doesn't change its order since request numbers compare first. A preset ExpiresAt
+// is an upper bound, it keeps chunks from expiring before their manifest.
+func stampExpiry(value *proto.StoredValue, ts *proto.TimeStamp, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	if ts.GetWallTime() == 0 {
+		ts.WallTime = uint64(time.Now().UnixNano())
 	}
-	err = s.completeSetPhase(key, latestValue.GetVal(), latestValue.GetTs())
+	expiresAt := ts.GetWallTime() + uint64(ttl)
+	if value.GetExpiresAt() == 0 || expiresAt < value.GetExpiresAt() {
+		value.ExpiresAt = expiresAt
+	}
+}
+
+// readRaw
+// find the latest value as stored, e.g. a chunk manifest, and write it back to a quorum, tombstones
+// and keys that were never written return KeyNotFoundError. The value is returned decrypted, but
+// otherwise as stored. The caller holds opsLock.
+func (s *SharedRegisterClient) readRaw(key string) (*proto.StoredValue, error) {
+	if s.coder != nil {
+		value, err := s.completeCodedRead(key)
+		if err != nil {
+			return nil, err
+		}
+		// tombstones are opened too, a replica may not fake them
+		if value, err = s.openValue(key, value); err != nil {
+			return nil, err
+		}
+		// coded replicas don't look into the values, expiry is up to the readers
+		if value.GetDeleted() || common.Expired(value, time.Now()) {
+			return nil, &KeyNotFoundError{Key: key}
+		}
+		if err := s.observeTimeStamp(value.GetTs()); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+
+	latestValue, err := s.completeGetPhase(key)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	// every write carries a client ID, none of the quorum has the key
+	if latestValue.GetTs().GetClientID() == "" {
+		return nil, &KeyNotFoundError{Key: key}
 	}
-	return latestValue.GetVal(), nil
+	// the replicas hide expired values, but their clocks may lag behind ours
+	latestValue = common.Visible(latestValue, time.Now())
+	// only authentic values are written back, a replica may not spread a forged one to a quorum
+	value, err := s.openValue(key, latestValue)
+	if err != nil {
+		return nil, err
+	}
+	err = s.completeSetPhase(key, latestValue)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.observeTimeStamp(latestValue.GetTs()); err != nil {
+		return nil, err
+	}
+	if value.GetDeleted() {
+		return nil, &KeyNotFoundError{Key: key}
+	}
+	return value, nil
 }
 
-// client waits for a majority of responses from replicas for current <v, timestamp> pairs
+// client waits for a read quorum (a majority by default) of responses from replicas for current <v, timestamp> pairs
 // client finds largest received timestamp, and then chooses a higher unique timestamp ts-new (max-ts,client-id)
 func (s *SharedRegisterClient) completeGetPhase(key string) (*proto.StoredValue, error) {
 	// use a channel with size 1 to compare and store the value with the largest TS among concurrent
@@ -119,9 +349,9 @@ func (s *SharedRegisterClient) completeGetPhase(key string) (*proto.StoredValue,
 		requests = append(requests, getFromReplica)
 	}
 	currMaxChan <- &proto.StoredValue{Ts: &proto.TimeStamp{}}
-	timedOut := util.WaitForMajoritySuccessFromJobs(s.quorumSize, s.PhaseTimeout, requests)
+	timedOut := s.waitForQuorum(s.quorumSystem().IsReadQuorum, requests)
 	if timedOut {
-		return nil, errors.New("completeGetPhase timeout")
+		return nil, &PhaseTimeoutError{Phase: "completeGetPhase"}
 	}
 	largestVal := <-currMaxChan
 	// have to manually the channel to let the unfinished request goroutine detect and return
@@ -133,18 +363,29 @@ func (s *SharedRegisterClient) completeGetPhase(key string) (*proto.StoredValue,
 // Each replica checks if this ts-new is larger than the one it stores
 // If yes, replica stores v, ts-new.
 // In either case, the storage nodes sends an acknowledgement to the client.
-// client then waits for a majority of acknowledgements
-func (s *SharedRegisterClient) completeSetPhase(key, value string, timestamp *proto.TimeStamp) error {
+// client then waits for a write quorum (a majority by default) of acknowledgements
+func (s *SharedRegisterClient) completeSetPhase(key string, value *proto.StoredValue) error {
+	var conflict, inUse int32
+	var token string
+	if s.registration != nil {
+		token = s.registration.token
+	}
 	requests := make([]func() bool, 0)
 	for _, conn := range s.replicaConns {
 		conn := conn
 		setToReplica := func() bool {
 			err := conn.SetPhase(&proto.SetPhaseReq{
-				Key: key,
-				Value: &proto.StoredValue{
-					Val: value,
-					Ts:  timestamp,
-				}})
+				Key:      key,
+				Value:    value,
+				ClientID: s.ClientID,
+				Token:    token,
+			})
+			switch status.Code(err) {
+			case codes.FailedPrecondition:
+				atomic.StoreInt32(&conflict, 1)
+			case codes.AlreadyExists:
+				atomic.StoreInt32(&inUse, 1)
+			}
 			if err != nil {
 				return false
 			}
@@ -152,9 +393,15 @@ func (s *SharedRegisterClient) completeSetPhase(key, value string, timestamp *pr
 		}
 		requests = append(requests, setToReplica)
 	}
-	timedOut := util.WaitForMajoritySuccessFromJobs(s.quorumSize, s.PhaseTimeout, requests)
+	timedOut := s.waitForQuorum(s.quorumSystem().IsWriteQuorum, requests)
+	if atomic.LoadInt32(&conflict) == 1 {
+		return ErrConflictingTimeStamp
+	}
+	if atomic.LoadInt32(&inUse) == 1 {
+		return ErrClientIDInUse
+	}
 	if timedOut {
-		return errors.New("completeGetPhase timeout")
+		return &PhaseTimeoutError{Phase: "completeSetPhase"}
 	}
 	return nil
 }
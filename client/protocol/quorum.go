@@ -0,0 +1,221 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"shared-registers/client/util"
+)
+
+// maxValidatedReplicas bounds the exhaustive intersection check of ValidateQuorumSystem
+const maxValidatedReplicas = 20
+
+// QuorumSystem
+// decides whether the replicas that answered a phase form a quorum, replicas are identified by their
+// position in the server address list. Read quorums are used by the get phase and write quorums by
+// the set phase of both Read and Write, so atomicity only needs every read quorum to intersect every
+// write quorum. Both predicates have to be monotone: a superset of a quorum is a quorum.
+type QuorumSystem interface {
+	IsReadQuorum(replicas []int) bool
+	IsWriteQuorum(replicas []int) bool
+}
+
+// MajorityQuorum is the default quorum system, any N/2+1 of the N replicas
+type MajorityQuorum struct {
+	N int
+}
+
+func (q MajorityQuorum) IsReadQuorum(replicas []int) bool {
+	return len(replicas) >= q.N/2+1
+}
+
+func (q MajorityQuorum) IsWriteQuorum(replicas []int) bool {
+	return q.IsReadQuorum(replicas)
+}
+
+// WeightedQuorum
+// weighted majority: replica i counts Weights[i] votes and a quorum needs more than half of all the
+// votes, so stronger or better connected nodes can form a quorum with fewer peers
+type WeightedQuorum struct {
+	Weights []int
+}
+
+func (q WeightedQuorum) votes(replicas []int) (got, total int) {
+	for _, w := range q.Weights {
+		total += w
+	}
+	for _, r := range replicas {
+		if r >= 0 && r < len(q.Weights) {
+			got += q.Weights[r]
+		}
+	}
+	return got, total
+}
+
+func (q WeightedQuorum) IsReadQuorum(replicas []int) bool {
+	got, total := q.votes(replicas)
+	return 2*got > total
+}
+
+func (q WeightedQuorum) IsWriteQuorum(replicas []int) bool {
+	return q.IsReadQuorum(replicas)
+}
+
+// GridQuorum
+// replicas are laid out row by row in a Rows x Cols grid, a read quorum holds one replica of every
+// column and a write quorum holds a full column plus one replica of every column, so reads only
+// need Cols replicas instead of a majority
+type GridQuorum struct {
+	Rows, Cols int
+}
+
+// columns counts, per column, how many of the replicas are in it
+func (q GridQuorum) columns(replicas []int) []int {
+	perColumn := make([]int, q.Cols)
+	seen := make(map[int]bool)
+	for _, r := range replicas {
+		if r < 0 || r >= q.Rows*q.Cols || seen[r] {
+			continue
+		}
+		seen[r] = true
+		perColumn[r%q.Cols]++
+	}
+	return perColumn
+}
+
+func (q GridQuorum) IsReadQuorum(replicas []int) bool {
+	if q.Cols <= 0 {
+		return false
+	}
+	for _, cnt := range q.columns(replicas) {
+		if cnt == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (q GridQuorum) IsWriteQuorum(replicas []int) bool {
+	if !q.IsReadQuorum(replicas) {
+		return false
+	}
+	for _, cnt := range q.columns(replicas) {
+		if cnt == q.Rows {
+			return true
+		}
+	}
+	return false
+}
+
+// FlexibleQuorum
+// any ReadSize replicas form a read quorum and any WriteSize replicas a write quorum, they
+// intersect as long as ReadSize + WriteSize > n, e.g. read from 2 and write to 4 of 5 replicas
+type FlexibleQuorum struct {
+	ReadSize, WriteSize int
+}
+
+func (q FlexibleQuorum) IsReadQuorum(replicas []int) bool {
+	return len(replicas) >= q.ReadSize
+}
+
+func (q FlexibleQuorum) IsWriteQuorum(replicas []int) bool {
+	return len(replicas) >= q.WriteSize
+}
+
+// ValidateQuorumSystem
+// check the quorum system over n replicas: all the replicas together have to form both quorums,
+// the quorums have to be monotone, and no read quorum may be disjoint from a write quorum. Since
+// quorums are monotone, a disjoint pair exists iff the complement of some write quorum is a read
+// quorum, which is checked exhaustively over the 2^n subsets.
+func ValidateQuorumSystem(q QuorumSystem, n int) error {
+	if q == nil {
+		return errors.New("nil quorum system")
+	}
+	if n <= 0 || n > maxValidatedReplicas {
+		return fmt.Errorf("can only validate quorum systems of 1 to %d replicas, got %d", maxValidatedReplicas, n)
+	}
+	full := uint32(1)<<n - 1
+	if !q.IsReadQuorum(subsetMembers(full, n)) || !q.IsWriteQuorum(subsetMembers(full, n)) {
+		return errors.New("all the replicas together don't form a read and a write quorum")
+	}
+	for set := uint32(0); set <= full; set++ {
+		members := subsetMembers(set, n)
+		isRead, isWrite := q.IsReadQuorum(members), q.IsWriteQuorum(members)
+		if isWrite && q.IsReadQuorum(subsetMembers(full&^set, n)) {
+			return fmt.Errorf("write quorum %v doesn't intersect read quorum %v", members, subsetMembers(full&^set, n))
+		}
+		// adding a replica must keep a quorum a quorum
+		for i := 0; i < n && (isRead || isWrite); i++ {
+			if set&(1<<i) != 0 {
+				continue
+			}
+			bigger := subsetMembers(set|1<<i, n)
+			if (isRead && !q.IsReadQuorum(bigger)) || (isWrite && !q.IsWriteQuorum(bigger)) {
+				return fmt.Errorf("quorum system is not monotone: %v is a quorum but %v is not", members, bigger)
+			}
+		}
+	}
+	return nil
+}
+
+func subsetMembers(set uint32, n int) []int {
+	members := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		if set&(1<<i) != 0 {
+			members = append(members, i)
+		}
+	}
+	return members
+}
+
+// SetQuorumSystem
+// replace the default majority quorums of the get and set phases, the quorum system is validated
+// against the number of server addresses and has to be reachable with the connected replicas.
+// Erasure-coded registers keep using their own ceil((n+k)/2) quorums.
+func (s *SharedRegisterClient) SetQuorumSystem(q QuorumSystem) error {
+	if err := s.checkQuorumSystem(q); err != nil {
+		return err
+	}
+	// operations read the quorum system once per phase, don't switch it between their phases
+	s.opsLock.Lock()
+	defer s.opsLock.Unlock()
+	s.quorum.Store(quorumHolder{q})
+	return nil
+}
+
+// quorumHolder wraps the quorum system of the client, atomic.Value only stores values of one type
+type quorumHolder struct {
+	QuorumSystem
+}
+
+// quorumSystem returns the quorum system of the get and set phases
+func (s *SharedRegisterClient) quorumSystem() QuorumSystem {
+	return s.quorum.Load().(quorumHolder).QuorumSystem
+}
+
+// checkQuorumSystem validates q against the number of server addresses and the connected replicas
+func (s *SharedRegisterClient) checkQuorumSystem(q QuorumSystem) error {
+	if err := ValidateQuorumSystem(q, s.replicaNum); err != nil {
+		return err
+	}
+	connected := make([]int, 0, len(s.replicaConns))
+	for _, conn := range s.replicaConns {
+		connected = append(connected, conn.index)
+	}
+	if !q.IsReadQuorum(connected) || !q.IsWriteQuorum(connected) {
+		return errors.New("the connected replicas don't form a quorum")
+	}
+	return nil
+}
+
+// waitForQuorum runs one job per connected replica, in the order of replicaConns, until the
+// replicas whose job succeeded satisfy isQuorum, return true on timeout
+func (s *SharedRegisterClient) waitForQuorum(isQuorum func([]int) bool, jobs []func() bool) bool {
+	isReplicaQuorum := func(succeeded []int) bool {
+		replicas := make([]int, len(succeeded))
+		for i, job := range succeeded {
+			replicas[i] = s.replicaConns[job].index
+		}
+		return isQuorum(replicas)
+	}
+	return util.WaitForQuorumFromJobs(isReplicaQuorum, s.PhaseTimeout, jobs)
+}
@@ -0,0 +1,65 @@
+package protocol
+
+import "testing"
+
+func TestValidateQuorumSystem(t *testing.T) {
+	valid := map[string]QuorumSystem{
+		"majority":         MajorityQuorum{N: 5},
+		"weighted":         WeightedQuorum{Weights: []int{3, 1, 1, 1, 1}},
+		"grid":             GridQuorum{Rows: 2, Cols: 3},
+		"flexible read 2":  FlexibleQuorum{ReadSize: 2, WriteSize: 4},
+		"flexible write 1": FlexibleQuorum{ReadSize: 5, WriteSize: 1},
+	}
+	for name, q := range valid {
+		n := 5
+		if g, ok := q.(GridQuorum); ok {
+			n = g.Rows * g.Cols
+		}
+		if err := ValidateQuorumSystem(q, n); err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+	}
+
+	invalid := map[string]QuorumSystem{
+		"minority":           MajorityQuorum{N: 3},
+		"flexible too small": FlexibleQuorum{ReadSize: 2, WriteSize: 3},
+		"unreachable":        FlexibleQuorum{ReadSize: 6, WriteSize: 6},
+	}
+	for name, q := range invalid {
+		if err := ValidateQuorumSystem(q, 5); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestGridQuorum(t *testing.T) {
+	// 0 1 2
+	// 3 4 5
+	q := GridQuorum{Rows: 2, Cols: 3}
+	if !q.IsReadQuorum([]int{0, 4, 2}) || q.IsReadQuorum([]int{0, 3, 1}) {
+		t.Error("a read quorum needs one replica of every column")
+	}
+	if !q.IsWriteQuorum([]int{0, 3, 1, 5}) || q.IsWriteQuorum([]int{0, 4, 2}) {
+		t.Error("a write quorum needs a full column and one replica of every column")
+	}
+}
+
+func TestDefaultQuorumOfAllAddresses(t *testing.T) {
+	// "dns:///" fails to dial, the other addresses connect lazily
+	s, err := CreateSharedRegisterClient(NewClientID(), []string{"localhost:1", "localhost:2", "localhost:3", "dns:///", "dns:///"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if s.quorumSystem().IsReadQuorum([]int{0, 1}) || s.quorumSystem().IsWriteQuorum([]int{0, 1}) {
+		t.Error("2 of the 3 connected replicas form a quorum of 5 addresses")
+	}
+	if !s.quorumSystem().IsReadQuorum([]int{0, 1, 2}) || !s.quorumSystem().IsWriteQuorum([]int{0, 1, 2}) {
+		t.Error("3 of 5 replicas don't form a quorum")
+	}
+
+	minority := []string{"localhost:1", "localhost:2", "localhost:3", "dns:///", "dns:///", "dns:///"}
+	if _, err := CreateSharedRegisterClient(NewClientID(), minority); err == nil {
+		t.Error("expected an error connecting to 3 of 6 replicas")
+	}
+}
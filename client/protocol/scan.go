@@ -0,0 +1,181 @@
+package protocol
+
+import (
+	"encoding/base64"
+	"errors"
+	"shared-registers/client/util"
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// Scan
+// list the keys in [start, end) in key order along with their latest values, end "" means no upper
+// bound. At most limit entries are returned (0 for as many as the replicas send at once), possibly
+// fewer even though the range has more keys; pass the returned page token to get the next page of
+// the same range, the token is empty once the range is exhausted. Deleted and expired keys are skipped.
+// Each returned value is an atomic read of its key, just like Read, but a page is not a snapshot of
+// the range: a key written concurrently with the scan may or may not be listed.
+func (s *SharedRegisterClient) Scan(start, end string, limit int, pageToken string) ([]*proto.KeyValue, string, error) {
+	s.opsLock.Lock()
+	defer s.opsLock.Unlock()
+	if s.DebugMode {
+		defer util.PrintFuncExeTime("Scan", time.Now())
+	}
+	if s.coder != nil {
+		return nil, "", errors.New("Scan only lists fully replicated registers")
+	}
+	if pageToken != "" {
+		next, err := base64.RawURLEncoding.DecodeString(pageToken)
+		if err != nil || string(next) < start {
+			return nil, "", ErrInvalidPageToken
+		}
+		start = string(next)
+	}
+	return s.scan(start, end, limit)
+}
+
+// ScanPrefix lists the keys starting with prefix, see Scan
+func (s *SharedRegisterClient) ScanPrefix(prefix string, limit int, pageToken string) ([]*proto.KeyValue, string, error) {
+	return s.Scan(prefix, common.PrefixEnd(prefix), limit, pageToken)
+}
+
+// scanEntry is the latest value found for a key and the replicas that sent that very value
+type scanEntry struct {
+	value   *proto.StoredValue
+	holders []int
+}
+
+// scan
+// 1. ask every replica for the range and wait for a read quorum of answers
+// 2. a replica that hit the limit may miss keys after its last entry, so only the keys up to the
+// smallest such last key are complete, later ones are left to the next page
+// 3. merge the answers by key and timestamp, and write a value back to a quorum unless a write
+// quorum already sent it, which makes every listed value an atomic read
+func (s *SharedRegisterClient) scan(start, end string, limit int) ([]*proto.KeyValue, string, error) {
+	responses, err := s.completeScanPhase(&proto.ScanReq{Start: start, End: end, Limit: uint32(limit)})
+	if err != nil {
+		return nil, "", err
+	}
+	cutoff, truncated := "", false
+	for _, rsp := range responses {
+		if entries := rsp.GetEntries(); rsp.GetMore() && len(entries) > 0 {
+			last := entries[len(entries)-1].GetKey()
+			if !truncated || last < cutoff {
+				cutoff = last
+			}
+			truncated = true
+		}
+	}
+
+	merged := make(map[string]*scanEntry)
+	for replica, rsp := range responses {
+		for _, e := range rsp.GetEntries() {
+			if truncated && e.GetKey() > cutoff {
+				break
+			}
+			m := merged[e.GetKey()]
+			if m == nil {
+				merged[e.GetKey()] = &scanEntry{value: e.GetValue(), holders: []int{replica}}
+				continue
+			}
+			switch cmp := common.CompareTimeStamps(e.GetValue().GetTs(), m.value.GetTs()); {
+			case cmp > 0:
+				m.value, m.holders = e.GetValue(), []int{replica}
+			case cmp == 0:
+				m.holders = append(m.holders, replica)
+			}
+		}
+	}
+	keys := make([]string, 0, len(merged))
+	for key := range merged {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	// the replicas may have sent up to limit different keys each
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+		cutoff, truncated = keys[limit-1], true
+	}
+
+	entries := make([]*proto.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		m := merged[key]
+		// only authentic values are written back, a replica may not spread a forged one to a quorum
+		value, err := s.openValue(key, common.Visible(m.value, time.Now()))
+		if err != nil {
+			return nil, "", err
+		}
+		if !s.quorumSystem().IsWriteQuorum(m.holders) {
+			if err := s.completeSetPhase(key, m.value); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := s.observeTimeStamp(m.value.GetTs()); err != nil {
+			return nil, "", err
+		}
+		if value.GetDeleted() {
+			continue
+		}
+		if value.GetManifest() != nil {
+			// a full read fetches the chunks, and retries if the manifest was replaced meanwhile
+			value, err = s.readValue(key)
+			if errors.Is(err, ErrKeyNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, "", err
+			}
+		} else if value.GetCompression() != proto.Compression_NONE {
+			value = &proto.StoredValue{Val: value.GetVal(), Ts: value.GetTs(), ContentType: value.GetContentType(),
+				Compression: value.GetCompression(), ExpiresAt: value.GetExpiresAt()}
+			if err := s.decompressValue(value); err != nil {
+				return nil, "", err
+			}
+		}
+		entries = append(entries, &proto.KeyValue{Key: key, Value: value})
+	}
+
+	nextPageToken := ""
+	if truncated {
+		// the smallest key after the cutoff
+		nextPageToken = base64.RawURLEncoding.EncodeToString([]byte(cutoff + "\x00"))
+	}
+	return entries, nextPageToken, nil
+}
+
+// completeScanPhase returns the answers of at least a read quorum, by replica index
+func (s *SharedRegisterClient) completeScanPhase(req *proto.ScanReq) (map[int]*proto.ScanRsp, error) {
+	var mu sync.Mutex
+	responses := make(map[int]*proto.ScanRsp)
+	requests := make([]func() bool, 0)
+	for _, conn := range s.replicaConns {
+		conn := conn
+		scanReplica := func() bool {
+			rsp, err := conn.Scan(req)
+			if err != nil {
+				return false
+			}
+			mu.Lock()
+			responses[conn.index] = rsp
+			mu.Unlock()
+			return true
+		}
+		requests = append(requests, scanReplica)
+	}
+	if s.waitForQuorum(s.quorumSystem().IsReadQuorum, requests) {
+		return nil, &PhaseTimeoutError{Phase: "completeScanPhase"}
+	}
+	// replicas answering after the quorum may still add their answers
+	mu.Lock()
+	defer mu.Unlock()
+	result := make(map[int]*proto.ScanRsp, len(responses))
+	for replica, rsp := range responses {
+		result[replica] = rsp
+	}
+	return result, nil
+}
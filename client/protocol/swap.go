@@ -0,0 +1,259 @@
+package protocol
+
+import (
+	"errors"
+	"math/rand"
+	"shared-registers/client/util"
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"sync"
+	"time"
+)
+
+const (
+	// swapAttempts bounds how often CompareAndSwap starts over after losing a ballot or finding the
+	// version it swaps replaced
+	swapAttempts   = 10
+	swapMinBackoff = 10 * time.Millisecond
+)
+
+var (
+	ErrSwapContention = errors.New("compare-and-swap kept conflicting with concurrent swaps")
+	// ErrSwapUnknown means the swap lost track of a value it proposed, which may or may not be stored
+	ErrSwapUnknown = errors.New("compare-and-swap outcome unknown")
+)
+
+// errSwapStale means the replicas moved past the version of the instance, start over from a read
+var errSwapStale = errors.New("swapped version was replaced")
+
+// CompareAndSwap
+// set the key to newValue only if its current value is expected, and report whether it did; a key
+// that doesn't exist or was deleted holds "". Every version of a key is replaced through its own
+// single-decree Paxos instance run by the replicas, so of several swaps of the same version exactly
+// one wins, and a proposer that finds a value already accepted completes that swap instead of its
+// own. Swaps are linearizable with each other and with reads. Plain writes bypass the instances, so
+// a key that is swapped shouldn't be written concurrently with Write. Only fully replicated
+// registers support it, and the new value isn't chunked, so it can't exceed ChunkThreshold.
+func (s *SharedRegisterClient) CompareAndSwap(key, expected, newValue string) (bool, error) {
+	if err := checkKey(key); err != nil {
+		return false, err
+	}
+	s.opsLock.Lock()
+	defer s.opsLock.Unlock()
+	if s.DebugMode {
+		defer util.PrintFuncExeTime("CompareAndSwap", time.Now())
+	}
+	if s.coder != nil {
+		return false, errors.New("CompareAndSwap only supports fully replicated registers")
+	}
+	if s.ChunkThreshold > 0 && len(newValue) > s.ChunkThreshold {
+		return false, errors.New("CompareAndSwap values can't be larger than ChunkThreshold")
+	}
+
+	ballot := &proto.TimeStamp{RequestNumber: 1, ClientID: s.ClientID}
+	backoff := swapMinBackoff
+	var current, proposal *proto.StoredValue
+	// pending is set once a replica may have accepted the proposal, from then on the swap sticks to
+	// the instance until it learns the chosen value, which may be the proposal even if it lost
+	pending := false
+	for attempt := 0; attempt < swapAttempts; attempt++ {
+		if attempt > 0 {
+			// randomized so that dueling proposers don't keep preempting each other
+			time.Sleep(backoff + time.Duration(rand.Int63n(int64(backoff))))
+			backoff *= 2
+		}
+		if !pending {
+			var data []byte
+			var err error
+			current, data, err = s.readForSwap(key)
+			if err == errChunkGone {
+				continue
+			}
+			if err != nil {
+				return false, err
+			}
+			if string(data) != expected {
+				return false, nil
+			}
+			proposal = &proto.StoredValue{Val: []byte(newValue)}
+			if err := s.compressValue(proposal); err != nil {
+				return false, err
+			}
+			if proposal.Ts, err = s.nextTimeStamp(current.GetTs()); err != nil {
+				return false, err
+			}
+			if err := s.sealValue(key, proposal, proposal.Ts); err != nil {
+				return false, err
+			}
+		}
+
+		chosen, promised, accepted, err := s.proposeSwap(key, current.GetTs(), ballot, proposal)
+		pending = pending || accepted
+		if err == errSwapStale && pending {
+			// the replicas moved on, the proposal was chosen if it is stored
+			latest, _, err := s.readForSwap(key)
+			if err == nil && common.CompareTimeStamps(latest.GetTs(), proposal.GetTs()) == 0 {
+				s.deleteChunks(key, current.GetManifest())
+				return true, nil
+			}
+			return false, ErrSwapUnknown
+		}
+		if err == errSwapStale {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if chosen == nil {
+			ballot = &proto.TimeStamp{RequestNumber: promised.GetRequestNumber() + 1, ClientID: s.ClientID}
+			continue
+		}
+		pending = false
+		if err := s.completeSetPhase(key, chosen); err != nil {
+			return false, err
+		}
+		if err := s.observeTimeStamp(chosen.GetTs()); err != nil {
+			return false, err
+		}
+		if common.CompareTimeStamps(chosen.GetTs(), proposal.GetTs()) != 0 {
+			continue // completed the swap of another proposer, compare against its value
+		}
+		s.deleteChunks(key, current.GetManifest())
+		return true, nil
+	}
+	if pending {
+		return false, ErrSwapUnknown
+	}
+	return false, ErrSwapContention
+}
+
+// readForSwap
+// read the key like readRaw, but also return the latest value as stored when it is a tombstone or
+// missing (with nil data), since its timestamp is the version a swap replaces
+func (s *SharedRegisterClient) readForSwap(key string) (*proto.StoredValue, []byte, error) {
+	latest, err := s.completeGetPhase(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if latest.GetTs().GetClientID() == "" {
+		return latest, nil, nil
+	}
+	latest = common.Visible(latest, time.Now())
+	// only authentic values are written back, a replica may not spread a forged one to a quorum
+	value, err := s.openValue(key, latest)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.completeSetPhase(key, latest); err != nil {
+		return nil, nil, err
+	}
+	if err := s.observeTimeStamp(latest.GetTs()); err != nil {
+		return nil, nil, err
+	}
+	if value.GetDeleted() {
+		return latest, nil, nil
+	}
+	if value.GetManifest() != nil {
+		data, err := s.readChunks(key, value.GetManifest())
+		if err != nil {
+			return nil, nil, err
+		}
+		value = &proto.StoredValue{Val: data, Compression: value.GetCompression()}
+	} else {
+		value = &proto.StoredValue{Val: value.GetVal(), Compression: value.GetCompression()}
+	}
+	if err := s.decompressValue(value); err != nil {
+		return nil, nil, err
+	}
+	return latest, value.GetVal(), nil
+}
+
+// proposeSwap
+// run one ballot of the instance replacing version: prepare it on a read quorum, adopt the value
+// accepted with the largest ballot if there is one, and get the value accepted by a write quorum,
+// which chooses it. Returns the chosen value, or nil and the largest ballot promised when another
+// proposer got ahead, and whether a replica may have accepted the proposal itself.
+func (s *SharedRegisterClient) proposeSwap(key string, version, ballot *proto.TimeStamp,
+	proposal *proto.StoredValue) (*proto.StoredValue, *proto.TimeStamp, bool, error) {
+	var mu sync.Mutex
+	var stale, accepted bool
+	var promised, acceptedBallot *proto.TimeStamp
+	value := proposal
+	// note rejections and stale instances, only promises and acceptances count towards the quorum
+	observe := func(ok, isStale bool, p *proto.TimeStamp) bool {
+		stale = stale || isStale
+		if !ok && common.CompareTimeStamps(p, promised) > 0 {
+			promised = p
+		}
+		return ok
+	}
+
+	requests := make([]func() bool, 0)
+	for _, conn := range s.replicaConns {
+		conn := conn
+		prepareOnReplica := func() bool {
+			rsp, err := conn.PaxosPrepare(&proto.PaxosPrepareReq{Key: key, Version: version, Ballot: ballot})
+			if err != nil {
+				return false
+			}
+			// a replica answering with a forged accepted value doesn't count, it would be chosen
+			if rsp.GetAcceptedValue() != nil {
+				if _, err := s.openValue(key, rsp.GetAcceptedValue()); err != nil {
+					return false
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if rsp.GetOk() && rsp.GetAcceptedValue() != nil && common.CompareTimeStamps(rsp.GetAcceptedBallot(), acceptedBallot) > 0 {
+				acceptedBallot, value = rsp.GetAcceptedBallot(), rsp.GetAcceptedValue()
+			}
+			return observe(rsp.GetOk(), rsp.GetStale(), rsp.GetPromised())
+		}
+		requests = append(requests, prepareOnReplica)
+	}
+	timedOut := s.waitForQuorum(s.quorumSystem().IsReadQuorum, requests)
+	mu.Lock()
+	if timedOut {
+		defer mu.Unlock()
+		return nil, promised, false, swapFailure(stale, promised)
+	}
+	proposed := value
+	mu.Unlock()
+	ours := common.CompareTimeStamps(proposed.GetTs(), proposal.GetTs()) == 0
+
+	requests = make([]func() bool, 0)
+	for _, conn := range s.replicaConns {
+		conn := conn
+		acceptOnReplica := func() bool {
+			rsp, err := conn.PaxosAccept(&proto.PaxosAcceptReq{Key: key, Version: version, Ballot: ballot, Value: proposed})
+			mu.Lock()
+			defer mu.Unlock()
+			// a failed request may still have reached the replica
+			accepted = accepted || (ours && (err != nil || rsp.GetOk()))
+			if err != nil {
+				return false
+			}
+			return observe(rsp.GetOk(), rsp.GetStale(), rsp.GetPromised())
+		}
+		requests = append(requests, acceptOnReplica)
+	}
+	timedOut = s.waitForQuorum(s.quorumSystem().IsWriteQuorum, requests)
+	mu.Lock()
+	defer mu.Unlock()
+	if timedOut {
+		return nil, promised, accepted, swapFailure(stale, promised)
+	}
+	return proposed, nil, accepted, nil
+}
+
+// swapFailure tells why a phase missed its quorum: nil if a larger ballot was promised, so the
+// caller retries with a larger one, errSwapStale, or the replicas didn't answer in time
+func swapFailure(stale bool, promised *proto.TimeStamp) error {
+	switch {
+	case promised != nil:
+		return nil
+	case stale:
+		return errSwapStale
+	}
+	return &PhaseTimeoutError{Phase: "proposeSwap"}
+}
@@ -0,0 +1,65 @@
+package protocol
+
+import (
+	"errors"
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"time"
+)
+
+var ErrNotWrittenSince = errors.New("the latest value was written before the requested time")
+
+// EnableHybridClock
+// switch new writes of this client to the HLC timestamp scheme: instead of bumping the request number,
+// a write keeps the largest request number it found and orders itself with a hybrid logical clock
+// reading (wall time + logical counter) larger than the largest timestamp of the quorum. Timestamps
+// of writes issued by clients with and without the scheme still compare consistently, and the wall
+// time of the latest write becomes visible through ReadWithTimeStamp. maxOffset rejects timestamps
+// from clocks that are more than maxOffset ahead of ours, 0 disables the check.
+func (s *SharedRegisterClient) EnableHybridClock(maxOffset time.Duration) {
+	s.opsLock.Lock()
+	defer s.opsLock.Unlock()
+	s.clock = common.NewHybridClock()
+	s.clock.MaxOffset = maxOffset
+}
+
+// ReadIfWrittenSince
+// time-bounded read, returns ErrNotWrittenSince if the latest value was written before since,
+// values written without the HLC timestamp scheme have no wall time and are always too old
+func (s *SharedRegisterClient) ReadIfWrittenSince(key string, since time.Time) (string, error) {
+	value, ts, err := s.ReadWithTimeStamp(key)
+	if err != nil {
+		return "", err
+	}
+	if common.WallTime(ts).Before(since) {
+		return "", ErrNotWrittenSince
+	}
+	return value, nil
+}
+
+// nextTimeStamp chooses the timestamp of a new write given the largest timestamp of the quorum
+func (s *SharedRegisterClient) nextTimeStamp(latest *proto.TimeStamp) (*proto.TimeStamp, error) {
+	if s.clock == nil {
+		return &proto.TimeStamp{
+			RequestNumber: latest.GetRequestNumber() + 1,
+			ClientID:      s.ClientID,
+		}, nil
+	}
+	wallTime, logical, err := s.clock.Next(latest)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.TimeStamp{
+		RequestNumber: latest.GetRequestNumber(),
+		ClientID:      s.ClientID,
+		WallTime:      wallTime,
+		Logical:       logical,
+	}, nil
+}
+
+func (s *SharedRegisterClient) observeTimeStamp(ts *proto.TimeStamp) error {
+	if s.clock == nil {
+		return nil
+	}
+	return s.clock.Observe(ts)
+}
@@ -0,0 +1,215 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"log"
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"time"
+)
+
+// delay between reconnects of a replica stream, doubled after each failure
+const (
+	watchMinBackoff = 100 * time.Millisecond
+	watchMaxBackoff = 5 * time.Second
+)
+
+// watchRetention is how long the merge remembers a key after its last update once nothing of it is
+// pending, so that a prefix watch doesn't hold every key it ever saw
+const watchRetention = time.Minute
+
+// replicaUpdate is a value a replica sent on its watch stream
+type replicaUpdate struct {
+	replica int
+	kv      *proto.KeyValue
+}
+
+// keyWatch is the merge state of one watched key
+type keyWatch struct {
+	delivered *proto.TimeStamp
+	reported  map[int]*proto.TimeStamp // largest timestamp each replica sent
+	pending   []*proto.StoredValue     // values newer than delivered
+	updated   time.Time                // when a replica last sent a value of the key
+}
+
+// Watch
+// deliver the values of the key, or of every key starting with it if prefix is set, that are newer
+// than fromTs (nil for the current value and everything after it). Every replica streams its
+// changes, and a value is delivered once the replicas that sent it or a newer one form a write
+// quorum, so any Read after the delivery returns it or a newer value. Per key, values are delivered
+// in timestamp order without duplicates, values overwritten before reaching a quorum may be
+// skipped, and deletes are delivered as tombstones (Value.Deleted). A replica stream that fails is
+// reopened with backoff and catches up from the replica's current values; a prefix watch forgets the
+// keys idle for a minute, so it delivers their current value again if a write quorum of streams
+// reopens. The channel is closed after ctx is canceled. Only fully replicated registers can be watched.
+func (s *SharedRegisterClient) Watch(ctx context.Context, key string, prefix bool, fromTs *proto.TimeStamp) (<-chan *proto.KeyValue, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	s.opsLock.Lock()
+	defer s.opsLock.Unlock()
+	if s.coder != nil {
+		return nil, errors.New("Watch only supports fully replicated registers")
+	}
+	updates := make(chan replicaUpdate)
+	events := make(chan *proto.KeyValue)
+	for _, conn := range s.replicaConns {
+		go s.watchReplica(ctx, conn, key, prefix, fromTs, updates)
+	}
+	go s.mergeWatch(ctx, s.quorumSystem(), fromTs, updates, events)
+	return events, nil
+}
+
+// watchReplica
+// keep a watch stream open to the replica and forward what it sends. On reconnect, a key watch
+// resumes after the largest timestamp the replica already sent; timestamps of different keys don't
+// compare, so a prefix watch starts over from fromTs and the merge drops what it has seen.
+func (s *SharedRegisterClient) watchReplica(ctx context.Context, conn *grpcClient, key string, prefix bool,
+	fromTs *proto.TimeStamp, updates chan<- replicaUpdate) {
+	backoff := watchMinBackoff
+	for ctx.Err() == nil {
+		stream, err := conn.Watch(ctx, &proto.WatchReq{Key: key, Prefix: prefix, FromTs: fromTs})
+		for err == nil {
+			var kv *proto.KeyValue
+			kv, err = stream.Recv()
+			if err != nil {
+				break
+			}
+			backoff = watchMinBackoff
+			if !prefix {
+				fromTs = kv.GetValue().GetTs()
+			}
+			select {
+			case updates <- replicaUpdate{replica: conn.index, kv: kv}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if s.DebugMode && ctx.Err() == nil {
+			log.Printf("watch stream of replica %d failed: %v", conn.index, err)
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		if backoff *= 2; backoff > watchMaxBackoff {
+			backoff = watchMaxBackoff
+		}
+	}
+}
+
+// mergeWatch merges the replica streams into events, see Watch
+func (s *SharedRegisterClient) mergeWatch(ctx context.Context, quorum QuorumSystem, fromTs *proto.TimeStamp,
+	updates <-chan replicaUpdate, events chan<- *proto.KeyValue) {
+	defer close(events)
+	keys := make(map[string]*keyWatch)
+	forget := time.NewTicker(watchRetention)
+	defer forget.Stop()
+	for {
+		var u replicaUpdate
+		select {
+		case u = <-updates:
+		case now := <-forget.C:
+			// a replica that reconnects replays a forgotten key's value, which is only delivered
+			// again if the replicas that replay it form a write quorum
+			for key, kw := range keys {
+				if len(kw.pending) == 0 && now.Sub(kw.updated) > watchRetention {
+					delete(keys, key)
+				}
+			}
+			continue
+		case <-ctx.Done():
+			return
+		}
+		key, value := u.kv.GetKey(), u.kv.GetValue()
+		kw := keys[key]
+		if kw == nil {
+			kw = &keyWatch{delivered: fromTs, reported: make(map[int]*proto.TimeStamp)}
+			keys[key] = kw
+		}
+		kw.updated = time.Now()
+		if common.CompareTimeStamps(value.GetTs(), kw.reported[u.replica]) > 0 {
+			kw.reported[u.replica] = value.GetTs()
+		}
+		if common.CompareTimeStamps(value.GetTs(), kw.delivered) <= 0 {
+			continue
+		}
+		if !kw.isPending(value.GetTs()) {
+			kw.pending = append(kw.pending, value)
+		}
+
+		// the newest pending value that a write quorum holds, or holds something newer than
+		var newest *proto.StoredValue
+		for _, v := range kw.pending {
+			holders := make([]int, 0, len(kw.reported))
+			for replica, ts := range kw.reported {
+				if common.CompareTimeStamps(ts, v.GetTs()) >= 0 {
+					holders = append(holders, replica)
+				}
+			}
+			if quorum.IsWriteQuorum(holders) && common.CompareTimeStamps(v.GetTs(), newest.GetTs()) > 0 {
+				newest = v
+			}
+		}
+		if newest == nil {
+			continue
+		}
+		event, err := s.resolveWatchEvent(key, newest)
+		if err != nil {
+			if s.DebugMode {
+				log.Printf("watch of key %s: %v", key, err)
+			}
+			continue // a newer value will come
+		}
+		kw.delivered = event.GetValue().GetTs()
+		remaining := kw.pending[:0]
+		for _, v := range kw.pending {
+			if common.CompareTimeStamps(v.GetTs(), kw.delivered) > 0 {
+				remaining = append(remaining, v)
+			}
+		}
+		kw.pending = remaining
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (kw *keyWatch) isPending(ts *proto.TimeStamp) bool {
+	for _, v := range kw.pending {
+		if common.CompareTimeStamps(v.GetTs(), ts) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveWatchEvent
+// turn a stored value into what Read would return, fetching chunks if needed. It doesn't take
+// opsLock, so the watch doesn't wait for the operations of the client: the chunks are read with
+// get phases only, and if a newer value replaced them already, the watch delivers that one next.
+func (s *SharedRegisterClient) resolveWatchEvent(key string, value *proto.StoredValue) (*proto.KeyValue, error) {
+	value, err := s.openValue(key, value)
+	if err != nil {
+		return nil, err
+	}
+	if m := value.GetManifest(); m != nil {
+		data, err := s.readChunks(key, m)
+		if err != nil {
+			return nil, err
+		}
+		value = &proto.StoredValue{Val: data, Ts: value.GetTs(), ContentType: value.GetContentType(),
+			Compression: value.GetCompression()}
+	} else if value.GetCompression() != proto.Compression_NONE {
+		value = &proto.StoredValue{Val: value.GetVal(), Ts: value.GetTs(), ContentType: value.GetContentType(),
+			Compression: value.GetCompression()}
+	}
+	if err := s.decompressValue(value); err != nil {
+		return nil, err
+	}
+	return &proto.KeyValue{Key: key, Value: value}, nil
+}
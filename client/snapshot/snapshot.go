@@ -0,0 +1,151 @@
+package snapshot
+
+import (
+	"errors"
+	"shared-registers/client/protocol"
+	"shared-registers/client/typed"
+	"strings"
+	"sync"
+)
+
+// keyPrefix is where the component registers of the snapshot objects live
+const keyPrefix = "snapshot/"
+
+var ErrNotComponent = errors.New("handle doesn't own a component")
+
+// component is the content of a component register: its value, the number of updates so far and
+// the view the last update scanned before writing
+type component struct {
+	Value []byte   `json:"value"`
+	Seq   uint64   `json:"seq"`
+	View  [][]byte `json:"view,omitempty"`
+}
+
+// Snapshot
+// atomic snapshot object of Afek et al. over single-writer registers: a named object with a fixed
+// list of components, each written only through the handle that owns it. Scan returns the values of
+// all the components as they were at a single point between its start and its end, even though
+// every register is read on its own. Both operations are wait-free, a Scan takes at most n+1
+// collects of n registers no matter how many Updates run concurrently.
+type Snapshot struct {
+	registers []*typed.Register[component]
+	self      int
+
+	lock   sync.Mutex // serializes the Updates of the owned component
+	seq    uint64
+	loaded bool // seq was recovered from the register
+}
+
+// New
+// create a handle on the snapshot object, which owns the component self or none if self is not one
+// of the components (such a handle can only Scan). Every handle has to list the components in the
+// same order, Scan returns the values in that order.
+func New(client typed.Client, name string, components []string, self string) (*Snapshot, error) {
+	if name == "" || strings.Contains(name, "/") {
+		return nil, errors.New("snapshot name must be non-empty and can't contain /")
+	}
+	s := &Snapshot{self: -1}
+	seen := make(map[string]bool)
+	for i, c := range components {
+		if c == "" || seen[c] {
+			return nil, errors.New("components must be unique and non-empty")
+		}
+		seen[c] = true
+		if c == self {
+			s.self = i
+		}
+		key := keyPrefix + name + "/" + c
+		s.registers = append(s.registers, typed.NewRegister[component](client, key, typed.JSONCodec[component]{}))
+	}
+	return s, nil
+}
+
+// Update
+// set the value of the owned component. The update first scans and stores the view along with the
+// value, scanners that see the component change twice borrow that view instead of retrying forever.
+func (s *Snapshot) Update(value []byte) error {
+	if s.self < 0 {
+		return ErrNotComponent
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.loaded {
+		// a previous handle of the component may have written it already
+		current, err := s.read(s.self)
+		if err != nil {
+			return err
+		}
+		s.seq, s.loaded = current.Seq, true
+	}
+	view, err := s.Scan()
+	if err != nil {
+		return err
+	}
+	if err := s.registers[s.self].Set(component{Value: value, Seq: s.seq + 1, View: view}); err != nil {
+		return err
+	}
+	s.seq++
+	return nil
+}
+
+// Scan
+// collect the components until two collects in a row are equal, which means nothing changed in
+// between and that collect is a snapshot. A component that changed twice during the scan was
+// updated by an Update whose own scan started after this one, so its view is a snapshot too.
+// Components never updated are nil.
+func (s *Snapshot) Scan() ([][]byte, error) {
+	moved := make([]int, len(s.registers))
+	last, err := s.collect()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		next, err := s.collect()
+		if err != nil {
+			return nil, err
+		}
+		same := true
+		for j := range next {
+			if next[j].Seq == last[j].Seq {
+				continue
+			}
+			same = false
+			if moved[j]++; moved[j] == 2 {
+				if len(next[j].View) != len(s.registers) {
+					return nil, errors.New("component " + s.registers[j].Key() + " embeds a view of another component list")
+				}
+				return next[j].View, nil
+			}
+		}
+		if same {
+			values := make([][]byte, len(next))
+			for j := range next {
+				values[j] = next[j].Value
+			}
+			return values, nil
+		}
+		last = next
+	}
+}
+
+// collect reads every component once
+func (s *Snapshot) collect() ([]component, error) {
+	components := make([]component, len(s.registers))
+	for j := range s.registers {
+		c, err := s.read(j)
+		if err != nil {
+			return nil, err
+		}
+		components[j] = c
+	}
+	return components, nil
+}
+
+// read reads a component register, one that was never written is empty
+func (s *Snapshot) read(j int) (component, error) {
+	c, err := s.registers[j].Get()
+	if errors.Is(err, protocol.ErrKeyNotFound) {
+		return component{}, nil
+	}
+	return c, err
+}
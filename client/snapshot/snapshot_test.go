@@ -0,0 +1,183 @@
+package snapshot
+
+import (
+	"shared-registers/client/protocol"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// memoryClient keeps the registers in a map, enough to test the snapshot without replicas. onRead,
+// if set, runs before every read, e.g. to update a component while a scan is collecting.
+type memoryClient struct {
+	lock   sync.Mutex
+	values map[string][]byte
+	onRead func()
+}
+
+func (m *memoryClient) WriteBytes(key string, value []byte, contentType string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.values[key] = value
+	return nil
+}
+
+func (m *memoryClient) ReadBytes(key string) ([]byte, string, error) {
+	if m.onRead != nil {
+		m.onRead()
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	v, ok := m.values[key]
+	if !ok {
+		return nil, "", &protocol.KeyNotFoundError{Key: key}
+	}
+	return v, "application/json", nil
+}
+
+func number(t *testing.T, value []byte) int {
+	if value == nil {
+		return 0
+	}
+	n, err := strconv.Atoi(string(value))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestScanEmpty(t *testing.T) {
+	client := &memoryClient{values: map[string][]byte{}}
+	s, err := New(client, "progress", []string{"a", "b"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := s.Scan()
+	if err != nil || len(values) != 2 || values[0] != nil || values[1] != nil {
+		t.Errorf("expected two empty components, got %q %v", values, err)
+	}
+	if err := s.Update([]byte("x")); err != ErrNotComponent {
+		t.Errorf("expected ErrNotComponent, got %v", err)
+	}
+}
+
+// a single goroutine sets a to k and then b to k, for k = 1, 2, ..., so at any point in time
+// a-1 <= b <= a, while other writers keep c and d changing to force scans to borrow views
+func TestScanIsAtomic(t *testing.T) {
+	client := &memoryClient{values: map[string][]byte{}}
+	components := []string{"a", "b", "c", "d"}
+	handles := make([]*Snapshot, len(components))
+	for i, c := range components {
+		s, err := New(client, "progress", components, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handles[i] = s
+	}
+
+	const rounds = 200
+	var writers sync.WaitGroup
+	writers.Add(3)
+	go func() {
+		defer writers.Done()
+		for k := 1; k <= rounds; k++ {
+			if err := handles[0].Update([]byte(strconv.Itoa(k))); err != nil {
+				t.Error(err)
+				return
+			}
+			if err := handles[1].Update([]byte(strconv.Itoa(k))); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for _, h := range handles[2:] {
+		go func(h *Snapshot) {
+			defer writers.Done()
+			for k := 1; k <= rounds; k++ {
+				if err := h.Update([]byte(strconv.Itoa(k))); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(h)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		writers.Wait()
+		close(done)
+	}()
+	scanner, _ := New(client, "progress", components, "")
+	previous := make([]int, len(components))
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+		values, err := scanner.Scan()
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, b := number(t, values[0]), number(t, values[1])
+		if b > a || b < a-1 {
+			t.Fatalf("inconsistent snapshot: a=%d b=%d", a, b)
+		}
+		for j := range values {
+			// successive scans never go back in time
+			if n := number(t, values[j]); n < previous[j] {
+				t.Fatalf("component %s went back from %d to %d", components[j], previous[j], n)
+			} else {
+				previous[j] = n
+			}
+		}
+	}
+	for j := range components {
+		if previous[j] != rounds {
+			t.Errorf("last scan missed updates of %s: %d", components[j], previous[j])
+		}
+	}
+}
+
+func TestUpdateResumesSequence(t *testing.T) {
+	client := &memoryClient{values: map[string][]byte{}}
+	first, _ := New(client, "progress", []string{"a"}, "a")
+	if err := first.Update([]byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	// a new handle of the same component continues the sequence, otherwise scans would miss its update
+	second, _ := New(client, "progress", []string{"a"}, "a")
+	if err := second.Update([]byte("2")); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := second.read(0); err != nil || c.Seq != 2 {
+		t.Errorf("expected seq 2, got %v %v", c, err)
+	}
+}
+
+func TestScanBorrowsView(t *testing.T) {
+	values := map[string][]byte{}
+	writer, _ := New(&memoryClient{values: values}, "progress", []string{"a", "b"}, "b")
+	if err := writer.Update([]byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	// b changes before every read of the scanner, so two collects are never equal
+	updates := 1
+	scanClient := &memoryClient{values: values}
+	scanClient.onRead = func() {
+		updates++
+		if err := writer.Update([]byte(strconv.Itoa(updates))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	scanner, _ := New(scanClient, "progress", []string{"a", "b"}, "a")
+	view, err := scanner.Scan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the borrowed view was taken by an update that started after the scan did
+	if b := number(t, view[1]); view[0] != nil || b < 2 || b >= updates {
+		t.Errorf("unexpected view %q after %d updates", view, updates)
+	}
+}
@@ -0,0 +1,90 @@
+package typed
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec converts register values of type T from and to the bytes stored in the registers, the
+// content type is stored along with the value and checked when decoding
+type Codec[T any] interface {
+	Encode(v T) ([]byte, error)
+	Decode(data []byte) (T, error)
+	ContentType() string
+}
+
+// JSONCodec stores values with encoding/json
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Encode(v T) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec[T]) Decode(data []byte) (T, error) {
+	var v T
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+func (JSONCodec[T]) ContentType() string {
+	return "application/json"
+}
+
+// GobCodec stores values with encoding/gob, only readable by Go clients
+type GobCodec[T any] struct{}
+
+func (GobCodec[T]) Encode(v T) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (GobCodec[T]) Decode(data []byte) (T, error) {
+	var v T
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v)
+	return v, err
+}
+
+func (GobCodec[T]) ContentType() string {
+	return "application/x-gob"
+}
+
+// ProtoCodec stores protobuf messages in their binary wire format, T is the message struct and
+// PT its pointer type, e.g. ProtoCodec[pb.Config, *pb.Config]
+type ProtoCodec[T any, PT interface {
+	*T
+	proto.Message
+}] struct{}
+
+func (ProtoCodec[T, PT]) Encode(v PT) ([]byte, error) {
+	return proto.Marshal(v)
+}
+
+func (ProtoCodec[T, PT]) Decode(data []byte) (PT, error) {
+	v := PT(new(T))
+	if err := proto.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (ProtoCodec[T, PT]) ContentType() string {
+	return "application/x-protobuf"
+}
+
+// StringCodec stores strings as they are, which is what SharedRegisterClient.Write does
+type StringCodec struct{}
+
+func (StringCodec) Encode(v string) ([]byte, error) {
+	return []byte(v), nil
+}
+
+func (StringCodec) Decode(data []byte) (string, error) {
+	return string(data), nil
+}
+
+func (StringCodec) ContentType() string {
+	return ""
+}
@@ -0,0 +1,91 @@
+package typed
+
+import (
+	"fmt"
+	"shared-registers/client/protocol"
+)
+
+// Client is the part of protocol.SharedRegisterClient a typed register needs
+type Client interface {
+	WriteBytes(key string, value []byte, contentType string) error
+	ReadBytes(key string) ([]byte, string, error)
+}
+
+var _ Client = (*protocol.SharedRegisterClient)(nil)
+
+// DecodeError
+// the register was read successfully but its value can't be decoded into T, either because it was
+// written with another codec or because it is corrupted, protocol errors are returned as they are
+type DecodeError struct {
+	Key         string
+	ContentType string // content type stored with the value
+	Err         error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decode value of key %s (content type %q): %v", e.Key, e.ContentType, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// EncodeError means the value couldn't be encoded, nothing was written
+type EncodeError struct {
+	Key string
+	Err error
+}
+
+func (e *EncodeError) Error() string {
+	return fmt.Sprintf("encode value of key %s: %v", e.Key, e.Err)
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+// Register is a handle on one key whose values are of type T
+type Register[T any] struct {
+	client Client
+	key    string
+	codec  Codec[T]
+}
+
+func NewRegister[T any](client Client, key string, codec Codec[T]) *Register[T] {
+	return &Register[T]{client: client, key: key, codec: codec}
+}
+
+func (r *Register[T]) Key() string {
+	return r.key
+}
+
+// Get reads the register and decodes its value, a *DecodeError tells a codec mismatch apart from
+// a failed read
+func (r *Register[T]) Get() (T, error) {
+	var zero T
+	data, contentType, err := r.client.ReadBytes(r.key)
+	if err != nil {
+		return zero, err
+	}
+	if contentType != "" && r.codec.ContentType() != "" && contentType != r.codec.ContentType() {
+		return zero, &DecodeError{
+			Key:         r.key,
+			ContentType: contentType,
+			Err:         fmt.Errorf("codec expects content type %q", r.codec.ContentType()),
+		}
+	}
+	v, err := r.codec.Decode(data)
+	if err != nil {
+		return zero, &DecodeError{Key: r.key, ContentType: contentType, Err: err}
+	}
+	return v, nil
+}
+
+// Set encodes the value and writes it along with the content type of the codec
+func (r *Register[T]) Set(v T) error {
+	data, err := r.codec.Encode(v)
+	if err != nil {
+		return &EncodeError{Key: r.key, Err: err}
+	}
+	return r.client.WriteBytes(r.key, data, r.codec.ContentType())
+}
@@ -0,0 +1,92 @@
+package typed
+
+import (
+	"errors"
+	"shared-registers/common/proto"
+	"testing"
+)
+
+// memoryClient keeps the registers in a map, enough to test the codecs without replicas
+type memoryClient struct {
+	values       map[string][]byte
+	contentTypes map[string]string
+}
+
+func newMemoryClient() *memoryClient {
+	return &memoryClient{values: map[string][]byte{}, contentTypes: map[string]string{}}
+}
+
+func (m *memoryClient) WriteBytes(key string, value []byte, contentType string) error {
+	m.values[key], m.contentTypes[key] = value, contentType
+	return nil
+}
+
+func (m *memoryClient) ReadBytes(key string) ([]byte, string, error) {
+	v, ok := m.values[key]
+	if !ok {
+		return nil, "", errors.New("key " + key + " doesn't exist")
+	}
+	return v, m.contentTypes[key], nil
+}
+
+type progress struct {
+	Worker string
+	Done   int
+}
+
+func TestCodecsRoundTrip(t *testing.T) {
+	client := newMemoryClient()
+
+	jsonReg := NewRegister[progress](client, "json", JSONCodec[progress]{})
+	if err := jsonReg.Set(progress{Worker: "w1", Done: 42}); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := jsonReg.Get(); err != nil || v.Worker != "w1" || v.Done != 42 {
+		t.Errorf("json: got %v, %v", v, err)
+	}
+
+	gobReg := NewRegister[map[string]int](client, "gob", GobCodec[map[string]int]{})
+	if err := gobReg.Set(map[string]int{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := gobReg.Get(); err != nil || v["a"] != 1 {
+		t.Errorf("gob: got %v, %v", v, err)
+	}
+
+	protoReg := NewRegister[*proto.TimeStamp](client, "proto", ProtoCodec[proto.TimeStamp, *proto.TimeStamp]{})
+	if err := protoReg.Set(&proto.TimeStamp{RequestNumber: 7, ClientID: "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := protoReg.Get(); err != nil || v.GetRequestNumber() != 7 || v.GetClientID() != "c" {
+		t.Errorf("proto: got %v, %v", v, err)
+	}
+
+	strReg := NewRegister[string](client, "str", StringCodec{})
+	if err := strReg.Set("plain"); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := strReg.Get(); err != nil || v != "plain" {
+		t.Errorf("string: got %v, %v", v, err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	client := newMemoryClient()
+	// a value written by another codec is reported as a DecodeError
+	NewRegister[int](client, "k", GobCodec[int]{}).Set(1)
+	_, err := NewRegister[int](client, "k", JSONCodec[int]{}).Get()
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.ContentType != "application/x-gob" {
+		t.Errorf("expected DecodeError for content type mismatch, got %v", err)
+	}
+
+	client.WriteBytes("corrupted", []byte("{"), "application/json")
+	if _, err := NewRegister[int](client, "corrupted", JSONCodec[int]{}).Get(); !errors.As(err, &decodeErr) {
+		t.Errorf("expected DecodeError for malformed json, got %v", err)
+	}
+
+	// protocol errors are passed through untouched
+	if _, err := NewRegister[int](client, "missing", JSONCodec[int]{}).Get(); err == nil || errors.As(err, &decodeErr) {
+		t.Errorf("expected the read error, got %v", err)
+	}
+}
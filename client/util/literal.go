@@ -0,0 +1,124 @@
+package util
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token is one field of a command line, quoted tokens are always taken literally
+type Token struct {
+	Text   string
+	Quoted bool
+}
+
+// SplitCommand
+// split a command line on whitespace like strings.Fields, but a field may be quoted: "..." accepts the
+// escapes of Go string literals (\n, \t, \x00, \", ...) and '...' is taken as is, so values can
+// contain spaces and arbitrary bytes
+func SplitCommand(line string) ([]Token, error) {
+	tokens := make([]Token, 0)
+	for i := 0; i < len(line); {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		if unicode.IsSpace(r) {
+			i += size
+			continue
+		}
+		start := i
+		switch line[i] {
+		case '"':
+			// find the closing quote, skipping escaped characters
+			i++
+			for i < len(line) && line[i] != '"' {
+				if line[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(line) {
+				return nil, errors.New("unterminated double quote")
+			}
+			i++
+			text, err := strconv.Unquote(line[start:i])
+			if err != nil {
+				return nil, errors.New("invalid quoted string " + line[start:i])
+			}
+			tokens = append(tokens, Token{Text: text, Quoted: true})
+		case '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated single quote")
+			}
+			tokens = append(tokens, Token{Text: line[i+1 : i+1+end], Quoted: true})
+			i += end + 2
+		default:
+			for i < len(line) {
+				r, size := utf8.DecodeRuneInString(line[i:])
+				if unicode.IsSpace(r) {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, Token{Text: line[start:i]})
+			continue
+		}
+		// a quoted field has to be followed by whitespace
+		if i < len(line) {
+			if r, _ := utf8.DecodeRuneInString(line[i:]); !unicode.IsSpace(r) {
+				return nil, errors.New("missing space after quoted string")
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// Value
+// decode the token as a register value: unquoted tokens may be written as hex:<hex digits>,
+// base64:<standard base64> or @<path> to read the value from a file
+func (t Token) Value() ([]byte, error) {
+	if t.Quoted {
+		return []byte(t.Text), nil
+	}
+	switch {
+	case strings.HasPrefix(t.Text, "hex:"):
+		return hex.DecodeString(strings.TrimPrefix(t.Text, "hex:"))
+	case strings.HasPrefix(t.Text, "base64:"):
+		return base64.StdEncoding.DecodeString(strings.TrimPrefix(t.Text, "base64:"))
+	case strings.HasPrefix(t.Text, "@"):
+		return os.ReadFile(strings.TrimPrefix(t.Text, "@"))
+	}
+	return []byte(t.Text), nil
+}
+
+// FormatValue
+// print a value so that reading the output back with SplitCommand and Token.Value gives the same
+// bytes: plain words stay as they are, printable text is quoted and anything else becomes base64
+func FormatValue(value []byte) string {
+	if !utf8.Valid(value) {
+		return "base64:" + base64.StdEncoding.EncodeToString(value)
+	}
+	text := string(value)
+	plain := text != "" && !strings.HasPrefix(text, "hex:") && !strings.HasPrefix(text, "base64:") &&
+		!strings.HasPrefix(text, "@")
+	printable := true
+	for _, r := range text {
+		if unicode.IsSpace(r) || r == '"' || r == '\'' || r == '\\' || !unicode.IsPrint(r) {
+			plain = false
+		}
+		if r != ' ' && !unicode.IsPrint(r) {
+			printable = false
+		}
+	}
+	switch {
+	case plain:
+		return text
+	case printable:
+		return strconv.Quote(text)
+	}
+	return "base64:" + base64.StdEncoding.EncodeToString(value)
+}
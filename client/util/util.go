@@ -50,6 +50,43 @@ func WaitForMajoritySuccessFromJobs(majorityNum int, timeout time.Duration, jobs
 	// don't need to close the channel
 }
 
+// WaitForQuorumFromJobs
+// same mechanism as WaitForMajoritySuccessFromJobs, but instead of counting successes it asks
+// isQuorum whether the indices of the jobs succeeded so far form a quorum, so the jobs can carry
+// different weights. Return true if no quorum was reached before the timeout or all jobs finished.
+func WaitForQuorumFromJobs(isQuorum func(succeeded []int) bool, timeout time.Duration, jobs []func() bool) bool {
+	type jobResult struct {
+		idx int
+		ok  bool
+	}
+	resChan := make(chan jobResult, len(jobs)) // prevent sender blocking
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for i, j := range jobs {
+		i, j := i, j
+		go func() {
+			resChan <- jobResult{idx: i, ok: j()}
+		}()
+	}
+
+	succeeded := make([]int, 0, len(jobs))
+	for finished := 0; finished < len(jobs); finished++ {
+		select {
+		case res := <-resChan:
+			if !res.ok {
+				continue
+			}
+			succeeded = append(succeeded, res.idx)
+			if isQuorum(succeeded) {
+				return false
+			}
+		case <-timer.C:
+			return true
+		}
+	}
+	return true
+}
+
 func PrintFuncExeTime(funcName string, startTime time.Time) {
 	log.Printf("%s took %v to finish\n", funcName, time.Since(startTime))
 }
@@ -44,3 +44,78 @@ func TestPrintFuncExeTime(t *testing.T) {
 	defer PrintFuncExeTime("test1", time.Now())
 	time.Sleep(time.Second)
 }
+
+func TestWaitForQuorumFromJobs(t *testing.T) {
+	jobs := []func() bool{
+		func() bool { return true },
+		func() bool { return false },
+		func() bool { time.Sleep(5 * time.Second); return true },
+		func() bool { return true },
+	}
+	// job 0 and job 3 together reach the quorum
+	isQuorum := func(succeeded []int) bool { return len(succeeded) >= 2 }
+	start := time.Now()
+	if timedOut := WaitForQuorumFromJobs(isQuorum, time.Second, jobs); timedOut {
+		t.Error("expected quorum to be reached")
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("should return as soon as the quorum is reached")
+	}
+	// job 2 is too slow for a quorum of 3
+	isQuorum = func(succeeded []int) bool { return len(succeeded) >= 3 }
+	if timedOut := WaitForQuorumFromJobs(isQuorum, 100*time.Millisecond, jobs); !timedOut {
+		t.Error("expected timeout")
+	}
+	// every job failed, no need to wait for the timeout
+	start = time.Now()
+	failed := []func() bool{func() bool { return false }, func() bool { return false }}
+	if timedOut := WaitForQuorumFromJobs(isQuorum, time.Second, failed); !timedOut || time.Since(start) > 500*time.Millisecond {
+		t.Error("expected early failure")
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tokens, err := SplitCommand(`W  key "a value\twith \"escapes\"" 'single quoted' hex:00ff`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Token{
+		{Text: "W"}, {Text: "key"},
+		{Text: "a value\twith \"escapes\"", Quoted: true},
+		{Text: "single quoted", Quoted: true},
+		{Text: "hex:00ff"},
+	}
+	if len(tokens) != len(expected) {
+		t.Fatalf("expected %d tokens, got %v", len(expected), tokens)
+	}
+	for i := range tokens {
+		if tokens[i] != expected[i] {
+			t.Errorf("token %d: expected %v, got %v", i, expected[i], tokens[i])
+		}
+	}
+	for _, invalid := range []string{`W k "unterminated`, `W k 'unterminated`, `W k "a"b`} {
+		if _, err := SplitCommand(invalid); err == nil {
+			t.Errorf("expected error for %s", invalid)
+		}
+	}
+}
+
+func TestFormatValueRoundTrip(t *testing.T) {
+	values := [][]byte{
+		[]byte("plain"),
+		[]byte(`{"json": "with spaces"}`),
+		[]byte("hex:not really hex"),
+		{0x00, 0xff, 0xfe, '\n'},
+		[]byte("line\nbreak"),
+	}
+	for _, v := range values {
+		tokens, err := SplitCommand("W k " + FormatValue(v))
+		if err != nil || len(tokens) != 3 {
+			t.Fatalf("%q: can't split formatted value %s: %v", v, FormatValue(v), err)
+		}
+		decoded, err := tokens[2].Value()
+		if err != nil || string(decoded) != string(v) {
+			t.Errorf("%q: round trip through %s gave %q, %v", v, FormatValue(v), decoded, err)
+		}
+	}
+}
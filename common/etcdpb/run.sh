@@ -0,0 +1 @@
+protoc --go_out=../ --go-grpc_out=../ ./kv.proto
\ No newline at end of file
@@ -0,0 +1,81 @@
+package common
+
+import (
+	"fmt"
+	"shared-registers/common/proto"
+	"sync"
+	"time"
+)
+
+// HybridClock
+// hybrid logical clock (Kulkarni et al.), the wall time follows the physical clock while the logical
+// counter orders events that happen within the same wall time, so every timestamp it issues is larger
+// than any timestamp it has seen while staying close to the real time of the write
+type HybridClock struct {
+	// MaxOffset rejects timestamps whose wall time is more than MaxOffset ahead of the local clock,
+	// so a replica holding a timestamp from a broken clock can't drag every writer into the future.
+	// 0 disables the check.
+	MaxOffset time.Duration
+
+	mu       sync.Mutex
+	wallTime uint64
+	logical  uint32
+	now      func() time.Time
+}
+
+func NewHybridClock() *HybridClock {
+	return &HybridClock{now: time.Now}
+}
+
+// Next
+// issue a new clock reading larger than the local clock and than seen, the largest timestamp the
+// writer got from the quorum, a nil seen only advances the local clock
+func (c *HybridClock) Next(seen *proto.TimeStamp) (wallTime uint64, logical uint32, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.update(seen); err != nil {
+		return 0, 0, err
+	}
+	physical := uint64(c.now().UnixNano())
+	if physical > c.wallTime {
+		c.wallTime, c.logical = physical, 0
+	} else {
+		c.logical++
+	}
+	return c.wallTime, c.logical, nil
+}
+
+// Observe
+// merge a timestamp received from the replicas into the clock without issuing a new reading, so
+// writes that causally follow a read get larger clock values even on different keys
+func (c *HybridClock) Observe(seen *proto.TimeStamp) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.update(seen)
+}
+
+func (c *HybridClock) update(seen *proto.TimeStamp) error {
+	if seen.GetWallTime() == 0 {
+		return nil
+	}
+	if c.MaxOffset > 0 {
+		limit := uint64(c.now().Add(c.MaxOffset).UnixNano())
+		if seen.GetWallTime() > limit {
+			return fmt.Errorf("timestamp wall time %v is more than %v ahead of the local clock",
+				WallTime(seen), c.MaxOffset)
+		}
+	}
+	if seen.GetWallTime() > c.wallTime || (seen.GetWallTime() == c.wallTime && seen.GetLogical() > c.logical) {
+		c.wallTime, c.logical = seen.GetWallTime(), seen.GetLogical()
+	}
+	return nil
+}
+
+// WallTime returns the approximate time the timestamp was issued at, the zero time if the writer
+// didn't use the HLC timestamp scheme
+func WallTime(ts *proto.TimeStamp) time.Time {
+	if ts.GetWallTime() == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(ts.GetWallTime()))
+}
@@ -0,0 +1,55 @@
+package common
+
+import (
+	"shared-registers/common/proto"
+	"testing"
+	"time"
+)
+
+func TestHybridClockNext(t *testing.T) {
+	physical := time.Unix(100, 0)
+	c := NewHybridClock()
+	c.now = func() time.Time { return physical }
+
+	w1, l1, _ := c.Next(nil)
+	w2, l2, _ := c.Next(nil)
+	if w1 != w2 || l2 != l1+1 {
+		t.Errorf("readings within the same physical time should bump the logical counter: %d.%d %d.%d", w1, l1, w2, l2)
+	}
+
+	// a timestamp from a clock ahead of ours is respected
+	ahead := &proto.TimeStamp{WallTime: uint64(physical.Add(time.Second).UnixNano()), Logical: 7}
+	w3, l3, _ := c.Next(ahead)
+	if CompareTimeStamps(&proto.TimeStamp{WallTime: w3, Logical: l3}, ahead) <= 0 {
+		t.Error("next reading must be larger than the seen timestamp")
+	}
+
+	physical = physical.Add(time.Hour)
+	if w4, l4, _ := c.Next(nil); w4 != uint64(physical.UnixNano()) || l4 != 0 {
+		t.Error("the wall time should catch up with the physical clock")
+	}
+
+	c.MaxOffset = time.Second
+	if _, _, err := c.Next(&proto.TimeStamp{WallTime: uint64(physical.Add(time.Minute).UnixNano())}); err == nil {
+		t.Error("expected error for a timestamp too far in the future")
+	}
+}
+
+func TestCompareTimeStamps(t *testing.T) {
+	ordered := []*proto.TimeStamp{
+		nil,
+		{RequestNumber: 1, ClientID: "b"},
+		{RequestNumber: 1, ClientID: "a", WallTime: 10},
+		{RequestNumber: 1, ClientID: "a", WallTime: 10, Logical: 1},
+		{RequestNumber: 1, ClientID: "b", WallTime: 10, Logical: 1},
+		{RequestNumber: 2, ClientID: "a"},
+	}
+	for i := 1; i < len(ordered); i++ {
+		if CompareTimeStamps(ordered[i-1], ordered[i]) != -1 || CompareTimeStamps(ordered[i], ordered[i-1]) != 1 {
+			t.Errorf("expected %v < %v", ordered[i-1], ordered[i])
+		}
+		if FindLargestTimeStamp(ordered[:i+1]...) != ordered[i] {
+			t.Errorf("FindLargestTimeStamp should return %v", ordered[i])
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// KeySize is the size of the AES-256 keys in key files
+const KeySize = 32
+
+// ParseKeyFile
+// parse lines of a key ID and a base64 encoded 32 byte key separated by whitespace, returning the ID
+// of the first key, the primary one that encrypts new data, and all the keys by ID. Empty lines and
+// lines starting with # are skipped.
+func ParseKeyFile(data []byte) (string, map[string][]byte, error) {
+	primary, keys := "", make(map[string][]byte)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return "", nil, errors.New("key file lines need a key ID and a key")
+		}
+		key, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil || len(key) != KeySize {
+			return "", nil, errors.New("key " + fields[0] + " isn't a base64 encoded 32 byte key")
+		}
+		if keys[fields[0]] != nil {
+			return "", nil, errors.New("duplicate key ID " + fields[0])
+		}
+		keys[fields[0]] = key
+		if primary == "" {
+			primary = fields[0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, err
+	}
+	if primary == "" {
+		return "", nil, errors.New("key file has no keys")
+	}
+	return primary, keys, nil
+}
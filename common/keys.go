@@ -0,0 +1,24 @@
+package common
+
+import "strings"
+
+// InternalKeyPrefix starts every key the client derives for its own bookkeeping (e.g. the chunks of
+// large values), user keys can't start with it and key listings skip them
+const InternalKeyPrefix = "\x00"
+
+func IsInternalKey(key string) bool {
+	return strings.HasPrefix(key, InternalKeyPrefix)
+}
+
+// PrefixEnd returns the smallest key larger than all the keys starting with prefix, or "" if there
+// is none, so [prefix, PrefixEnd(prefix)) is the key range of the prefix
+func PrefixEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+	return ""
+}
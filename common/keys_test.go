@@ -0,0 +1,17 @@
+package common
+
+import "testing"
+
+func TestPrefixEnd(t *testing.T) {
+	cases := map[string]string{
+		"tenant/":  "tenant0",
+		"a\xff":    "b",
+		"\xff\xff": "",
+		"":         "",
+	}
+	for prefix, want := range cases {
+		if got := PrefixEnd(prefix); got != want {
+			t.Errorf("PrefixEnd(%q) = %q, want %q", prefix, got, want)
+		}
+	}
+}
@@ -20,6 +20,148 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type Compression int32
+
+const (
+	Compression_NONE Compression = 0
+	Compression_GZIP Compression = 1
+)
+
+// Enum value maps for Compression.
+var (
+	Compression_name = map[int32]string{
+		0: "NONE",
+		1: "GZIP",
+	}
+	Compression_value = map[string]int32{
+		"NONE": 0,
+		"GZIP": 1,
+	}
+)
+
+func (x Compression) Enum() *Compression {
+	p := new(Compression)
+	*p = x
+	return p
+}
+
+func (x Compression) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Compression) Descriptor() protoreflect.EnumDescriptor {
+	return file_request_proto_enumTypes[0].Descriptor()
+}
+
+func (Compression) Type() protoreflect.EnumType {
+	return &file_request_proto_enumTypes[0]
+}
+
+func (x Compression) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Compression.Descriptor instead.
+func (Compression) EnumDescriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{0}
+}
+
+type LogLevel int32
+
+const (
+	LogLevel_INFO  LogLevel = 0
+	LogLevel_DEBUG LogLevel = 1 // also log every request, with its caller, duration and error
+)
+
+// Enum value maps for LogLevel.
+var (
+	LogLevel_name = map[int32]string{
+		0: "INFO",
+		1: "DEBUG",
+	}
+	LogLevel_value = map[string]int32{
+		"INFO":  0,
+		"DEBUG": 1,
+	}
+)
+
+func (x LogLevel) Enum() *LogLevel {
+	p := new(LogLevel)
+	*p = x
+	return p
+}
+
+func (x LogLevel) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LogLevel) Descriptor() protoreflect.EnumDescriptor {
+	return file_request_proto_enumTypes[1].Descriptor()
+}
+
+func (LogLevel) Type() protoreflect.EnumType {
+	return &file_request_proto_enumTypes[1]
+}
+
+func (x LogLevel) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LogLevel.Descriptor instead.
+func (LogLevel) EnumDescriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{1}
+}
+
+// ReplicaMode is what a replica serves, it starts SERVING and forgets the mode when it restarts
+type ReplicaMode int32
+
+const (
+	ReplicaMode_SERVING   ReplicaMode = 0
+	ReplicaMode_READ_ONLY ReplicaMode = 1 // requests storing anything fail with Unavailable, reads are served
+	ReplicaMode_DRAINING  ReplicaMode = 2 // every SharedRegisters request fails with Unavailable and watches end, so clients
+)
+
+// Enum value maps for ReplicaMode.
+var (
+	ReplicaMode_name = map[int32]string{
+		0: "SERVING",
+		1: "READ_ONLY",
+		2: "DRAINING",
+	}
+	ReplicaMode_value = map[string]int32{
+		"SERVING":   0,
+		"READ_ONLY": 1,
+		"DRAINING":  2,
+	}
+)
+
+func (x ReplicaMode) Enum() *ReplicaMode {
+	p := new(ReplicaMode)
+	*p = x
+	return p
+}
+
+func (x ReplicaMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReplicaMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_request_proto_enumTypes[2].Descriptor()
+}
+
+func (ReplicaMode) Type() protoreflect.EnumType {
+	return &file_request_proto_enumTypes[2]
+}
+
+func (x ReplicaMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReplicaMode.Descriptor instead.
+func (ReplicaMode) EnumDescriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{2}
+}
+
 type GetPhaseReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -119,8 +261,17 @@ type StoredValue struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Val string     `protobuf:"bytes,1,opt,name=val,proto3" json:"val,omitempty"`
-	Ts  *TimeStamp `protobuf:"bytes,2,opt,name=ts,proto3" json:"ts,omitempty"`
+	Val         []byte         `protobuf:"bytes,1,opt,name=val,proto3" json:"val,omitempty"`
+	Ts          *TimeStamp     `protobuf:"bytes,2,opt,name=ts,proto3" json:"ts,omitempty"`
+	ContentType string         `protobuf:"bytes,3,opt,name=contentType,proto3" json:"contentType,omitempty"`                   // optional MIME type of val, e.g. application/json
+	Deleted     bool           `protobuf:"varint,4,opt,name=deleted,proto3" json:"deleted,omitempty"`                          // tombstone, the key was deleted at ts
+	Manifest    *ChunkManifest `protobuf:"bytes,5,opt,name=manifest,proto3" json:"manifest,omitempty"`                         // set if the value is split into chunks stored under derived keys
+	Compression Compression    `protobuf:"varint,6,opt,name=compression,proto3,enum=Compression" json:"compression,omitempty"` // codec val (or the chunks of the manifest) is compressed with
+	ExpiresAt   uint64         `protobuf:"varint,7,opt,name=expiresAt,proto3" json:"expiresAt,omitempty"`                      // unix nanoseconds after which the value reads as deleted, 0 for never
+	KeyID       string         `protobuf:"bytes,8,opt,name=keyID,proto3" json:"keyID,omitempty"`                               // client-side key val is encrypted with, empty if replicas see it in plaintext
+	// with keyID, authenticates the tombstone: the tombstone itself, or the one the value turns into
+	// once it expires, which keeps the seal
+	Seal []byte `protobuf:"bytes,9,opt,name=seal,proto3" json:"seal,omitempty"`
 }
 
 func (x *StoredValue) Reset() {
@@ -155,11 +306,11 @@ func (*StoredValue) Descriptor() ([]byte, []int) {
 	return file_request_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *StoredValue) GetVal() string {
+func (x *StoredValue) GetVal() []byte {
 	if x != nil {
 		return x.Val
 	}
-	return ""
+	return nil
 }
 
 func (x *StoredValue) GetTs() *TimeStamp {
@@ -169,6 +320,120 @@ func (x *StoredValue) GetTs() *TimeStamp {
 	return nil
 }
 
+func (x *StoredValue) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *StoredValue) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *StoredValue) GetManifest() *ChunkManifest {
+	if x != nil {
+		return x.Manifest
+	}
+	return nil
+}
+
+func (x *StoredValue) GetCompression() Compression {
+	if x != nil {
+		return x.Compression
+	}
+	return Compression_NONE
+}
+
+func (x *StoredValue) GetExpiresAt() uint64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *StoredValue) GetKeyID() string {
+	if x != nil {
+		return x.KeyID
+	}
+	return ""
+}
+
+func (x *StoredValue) GetSeal() []byte {
+	if x != nil {
+		return x.Seal
+	}
+	return nil
+}
+
+// ChunkManifest lists the chunks of a large value, chunk i is stored under the key derived from the
+// register key, writeID and chunks[i]
+type ChunkManifest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	WriteID string   `protobuf:"bytes,1,opt,name=writeID,proto3" json:"writeID,omitempty"` // random per write, keeps the chunks of different writes apart
+	Chunks  [][]byte `protobuf:"bytes,2,rep,name=chunks,proto3" json:"chunks,omitempty"`   // sha256 of every chunk, in order
+	Size    uint64   `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`      // length of the whole value
+}
+
+func (x *ChunkManifest) Reset() {
+	*x = ChunkManifest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChunkManifest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkManifest) ProtoMessage() {}
+
+func (x *ChunkManifest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkManifest.ProtoReflect.Descriptor instead.
+func (*ChunkManifest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ChunkManifest) GetWriteID() string {
+	if x != nil {
+		return x.WriteID
+	}
+	return ""
+}
+
+func (x *ChunkManifest) GetChunks() [][]byte {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+func (x *ChunkManifest) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
 type SetPhaseReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -176,12 +441,16 @@ type SetPhaseReq struct {
 
 	Key   string       `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	Value *StoredValue `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// ClientID and registration token of the sender, replicas refuse values stamped with the sender's
+	// own ClientID while another client holds its lease (see RegisterClientReq)
+	ClientID string `protobuf:"bytes,3,opt,name=clientID,proto3" json:"clientID,omitempty"`
+	Token    string `protobuf:"bytes,4,opt,name=token,proto3" json:"token,omitempty"`
 }
 
 func (x *SetPhaseReq) Reset() {
 	*x = SetPhaseReq{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_request_proto_msgTypes[3]
+		mi := &file_request_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -194,7 +463,7 @@ func (x *SetPhaseReq) String() string {
 func (*SetPhaseReq) ProtoMessage() {}
 
 func (x *SetPhaseReq) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[3]
+	mi := &file_request_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -207,7 +476,7 @@ func (x *SetPhaseReq) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetPhaseReq.ProtoReflect.Descriptor instead.
 func (*SetPhaseReq) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{3}
+	return file_request_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *SetPhaseReq) GetKey() string {
@@ -224,6 +493,20 @@ func (x *SetPhaseReq) GetValue() *StoredValue {
 	return nil
 }
 
+func (x *SetPhaseReq) GetClientID() string {
+	if x != nil {
+		return x.ClientID
+	}
+	return ""
+}
+
+func (x *SetPhaseReq) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
 type SetPhaseRsp struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -233,7 +516,7 @@ type SetPhaseRsp struct {
 func (x *SetPhaseRsp) Reset() {
 	*x = SetPhaseRsp{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_request_proto_msgTypes[4]
+		mi := &file_request_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -246,7 +529,7 @@ func (x *SetPhaseRsp) String() string {
 func (*SetPhaseRsp) ProtoMessage() {}
 
 func (x *SetPhaseRsp) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[4]
+	mi := &file_request_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -259,7 +542,7 @@ func (x *SetPhaseRsp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetPhaseRsp.ProtoReflect.Descriptor instead.
 func (*SetPhaseRsp) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{4}
+	return file_request_proto_rawDescGZIP(), []int{5}
 }
 
 type TimeStamp struct {
@@ -269,12 +552,15 @@ type TimeStamp struct {
 
 	RequestNumber uint64 `protobuf:"varint,1,opt,name=requestNumber,proto3" json:"requestNumber,omitempty"`
 	ClientID      string `protobuf:"bytes,2,opt,name=clientID,proto3" json:"clientID,omitempty"`
+	// hybrid logical clock of the writer, both are 0 unless it uses the HLC timestamp scheme
+	WallTime uint64 `protobuf:"varint,3,opt,name=wallTime,proto3" json:"wallTime,omitempty"` // physical part, unix nanoseconds
+	Logical  uint32 `protobuf:"varint,4,opt,name=logical,proto3" json:"logical,omitempty"`   // logical counter ordering events within the same wallTime
 }
 
 func (x *TimeStamp) Reset() {
 	*x = TimeStamp{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_request_proto_msgTypes[5]
+		mi := &file_request_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -287,7 +573,7 @@ func (x *TimeStamp) String() string {
 func (*TimeStamp) ProtoMessage() {}
 
 func (x *TimeStamp) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[5]
+	mi := &file_request_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -300,7 +586,7 @@ func (x *TimeStamp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TimeStamp.ProtoReflect.Descriptor instead.
 func (*TimeStamp) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{5}
+	return file_request_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *TimeStamp) GetRequestNumber() uint64 {
@@ -317,120 +603,2768 @@ func (x *TimeStamp) GetClientID() string {
 	return ""
 }
 
-var File_request_proto protoreflect.FileDescriptor
+func (x *TimeStamp) GetWallTime() uint64 {
+	if x != nil {
+		return x.WallTime
+	}
+	return 0
+}
 
-var file_request_proto_rawDesc = []byte{
-	0x0a, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
-	0x1f, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x12, 0x10,
-	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
-	0x22, 0x31, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x52, 0x73, 0x70, 0x12,
-	0x22, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c,
-	0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x05, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x22, 0x3b, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x56, 0x61, 0x6c,
-	0x75, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x03, 0x76, 0x61, 0x6c, 0x12, 0x1a, 0x0a, 0x02, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x0a, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x74, 0x73,
-	0x22, 0x43, 0x0a, 0x0b, 0x53, 0x65, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x12,
-	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
-	0x79, 0x12, 0x22, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x0c, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x05,
-	0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x0d, 0x0a, 0x0b, 0x53, 0x65, 0x74, 0x50, 0x68, 0x61, 0x73,
-	0x65, 0x52, 0x73, 0x70, 0x22, 0x4d, 0x0a, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d,
-	0x70, 0x12, 0x24, 0x0a, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x4e, 0x75, 0x6d, 0x62,
-	0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e,
-	0x74, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e,
-	0x74, 0x49, 0x44, 0x32, 0x65, 0x0a, 0x0f, 0x53, 0x68, 0x61, 0x72, 0x65, 0x64, 0x52, 0x65, 0x67,
-	0x69, 0x73, 0x74, 0x65, 0x72, 0x73, 0x12, 0x28, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x50, 0x68, 0x61,
-	0x73, 0x65, 0x12, 0x0c, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71,
-	0x1a, 0x0c, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x52, 0x73, 0x70, 0x22, 0x00,
-	0x12, 0x28, 0x0a, 0x08, 0x53, 0x65, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x12, 0x0c, 0x2e, 0x53,
-	0x65, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x0c, 0x2e, 0x53, 0x65, 0x74,
-	0x50, 0x68, 0x61, 0x73, 0x65, 0x52, 0x73, 0x70, 0x22, 0x00, 0x42, 0x11, 0x5a, 0x0f, 0x2e, 0x2e,
-	0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *TimeStamp) GetLogical() uint32 {
+	if x != nil {
+		return x.Logical
+	}
+	return 0
 }
 
-var (
-	file_request_proto_rawDescOnce sync.Once
-	file_request_proto_rawDescData = file_request_proto_rawDesc
-)
+type CodedFragment struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-func file_request_proto_rawDescGZIP() []byte {
-	file_request_proto_rawDescOnce.Do(func() {
-		file_request_proto_rawDescData = protoimpl.X.CompressGZIP(file_request_proto_rawDescData)
-	})
-	return file_request_proto_rawDescData
+	Data        []byte       `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Index       uint32       `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`             // position of this fragment in the code, equals the replica's position
+	DataShards  uint32       `protobuf:"varint,3,opt,name=dataShards,proto3" json:"dataShards,omitempty"`   // k, number of fragments needed to reconstruct the value
+	TotalShards uint32       `protobuf:"varint,4,opt,name=totalShards,proto3" json:"totalShards,omitempty"` // n
+	ValueSize   uint64       `protobuf:"varint,5,opt,name=valueSize,proto3" json:"valueSize,omitempty"`     // length of the value before padding
+	Meta        *StoredValue `protobuf:"bytes,7,opt,name=meta,proto3" json:"meta,omitempty"`                // the rest of the stored value (content type, tombstone, ...) without val and ts
 }
 
-var file_request_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
-var file_request_proto_goTypes = []interface{}{
-	(*GetPhaseReq)(nil), // 0: GetPhaseReq
-	(*GetPhaseRsp)(nil), // 1: GetPhaseRsp
-	(*StoredValue)(nil), // 2: StoredValue
-	(*SetPhaseReq)(nil), // 3: SetPhaseReq
-	(*SetPhaseRsp)(nil), // 4: SetPhaseRsp
-	(*TimeStamp)(nil),   // 5: TimeStamp
+func (x *CodedFragment) Reset() {
+	*x = CodedFragment{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
-var file_request_proto_depIdxs = []int32{
-	2, // 0: GetPhaseRsp.value:type_name -> StoredValue
-	5, // 1: StoredValue.ts:type_name -> TimeStamp
-	2, // 2: SetPhaseReq.value:type_name -> StoredValue
-	0, // 3: SharedRegisters.GetPhase:input_type -> GetPhaseReq
-	3, // 4: SharedRegisters.SetPhase:input_type -> SetPhaseReq
-	1, // 5: SharedRegisters.GetPhase:output_type -> GetPhaseRsp
-	4, // 6: SharedRegisters.SetPhase:output_type -> SetPhaseRsp
-	5, // [5:7] is the sub-list for method output_type
-	3, // [3:5] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+
+func (x *CodedFragment) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func init() { file_request_proto_init() }
-func file_request_proto_init() {
-	if File_request_proto != nil {
-		return
-	}
-	if !protoimpl.UnsafeEnabled {
-		file_request_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetPhaseReq); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_request_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetPhaseRsp); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_request_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StoredValue); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+func (*CodedFragment) ProtoMessage() {}
+
+func (x *CodedFragment) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_request_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetPhaseReq); i {
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CodedFragment.ProtoReflect.Descriptor instead.
+func (*CodedFragment) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CodedFragment) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *CodedFragment) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *CodedFragment) GetDataShards() uint32 {
+	if x != nil {
+		return x.DataShards
+	}
+	return 0
+}
+
+func (x *CodedFragment) GetTotalShards() uint32 {
+	if x != nil {
+		return x.TotalShards
+	}
+	return 0
+}
+
+func (x *CodedFragment) GetValueSize() uint64 {
+	if x != nil {
+		return x.ValueSize
+	}
+	return 0
+}
+
+func (x *CodedFragment) GetMeta() *StoredValue {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
+type CodedQueryReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (x *CodedQueryReq) Reset() {
+	*x = CodedQueryReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CodedQueryReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CodedQueryReq) ProtoMessage() {}
+
+func (x *CodedQueryReq) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CodedQueryReq.ProtoReflect.Descriptor instead.
+func (*CodedQueryReq) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CodedQueryReq) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type CodedQueryRsp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ts   *TimeStamp   `protobuf:"bytes,1,opt,name=ts,proto3" json:"ts,omitempty"`     // the largest finalized timestamp
+	Meta *StoredValue `protobuf:"bytes,2,opt,name=meta,proto3" json:"meta,omitempty"` // meta of the replica's fragment of ts, empty if it doesn't hold the fragment
+}
+
+func (x *CodedQueryRsp) Reset() {
+	*x = CodedQueryRsp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CodedQueryRsp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CodedQueryRsp) ProtoMessage() {}
+
+func (x *CodedQueryRsp) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CodedQueryRsp.ProtoReflect.Descriptor instead.
+func (*CodedQueryRsp) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CodedQueryRsp) GetTs() *TimeStamp {
+	if x != nil {
+		return x.Ts
+	}
+	return nil
+}
+
+func (x *CodedQueryRsp) GetMeta() *StoredValue {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
+type CodedPreWriteReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key      string         `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Ts       *TimeStamp     `protobuf:"bytes,2,opt,name=ts,proto3" json:"ts,omitempty"`
+	Fragment *CodedFragment `protobuf:"bytes,3,opt,name=fragment,proto3" json:"fragment,omitempty"`
+}
+
+func (x *CodedPreWriteReq) Reset() {
+	*x = CodedPreWriteReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CodedPreWriteReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CodedPreWriteReq) ProtoMessage() {}
+
+func (x *CodedPreWriteReq) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CodedPreWriteReq.ProtoReflect.Descriptor instead.
+func (*CodedPreWriteReq) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CodedPreWriteReq) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *CodedPreWriteReq) GetTs() *TimeStamp {
+	if x != nil {
+		return x.Ts
+	}
+	return nil
+}
+
+func (x *CodedPreWriteReq) GetFragment() *CodedFragment {
+	if x != nil {
+		return x.Fragment
+	}
+	return nil
+}
+
+type CodedPreWriteRsp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *CodedPreWriteRsp) Reset() {
+	*x = CodedPreWriteRsp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CodedPreWriteRsp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CodedPreWriteRsp) ProtoMessage() {}
+
+func (x *CodedPreWriteRsp) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CodedPreWriteRsp.ProtoReflect.Descriptor instead.
+func (*CodedPreWriteRsp) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{11}
+}
+
+type CodedFinalizeReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key          string     `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Ts           *TimeStamp `protobuf:"bytes,2,opt,name=ts,proto3" json:"ts,omitempty"`
+	WantFragment bool       `protobuf:"varint,3,opt,name=wantFragment,proto3" json:"wantFragment,omitempty"` // readers ask for the fragment of ts, writers only finalize
+}
+
+func (x *CodedFinalizeReq) Reset() {
+	*x = CodedFinalizeReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CodedFinalizeReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CodedFinalizeReq) ProtoMessage() {}
+
+func (x *CodedFinalizeReq) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CodedFinalizeReq.ProtoReflect.Descriptor instead.
+func (*CodedFinalizeReq) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CodedFinalizeReq) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *CodedFinalizeReq) GetTs() *TimeStamp {
+	if x != nil {
+		return x.Ts
+	}
+	return nil
+}
+
+func (x *CodedFinalizeReq) GetWantFragment() bool {
+	if x != nil {
+		return x.WantFragment
+	}
+	return false
+}
+
+type CodedFinalizeRsp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Fragment *CodedFragment `protobuf:"bytes,1,opt,name=fragment,proto3" json:"fragment,omitempty"` // empty if the replica doesn't hold the fragment of ts
+}
+
+func (x *CodedFinalizeRsp) Reset() {
+	*x = CodedFinalizeRsp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CodedFinalizeRsp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CodedFinalizeRsp) ProtoMessage() {}
+
+func (x *CodedFinalizeRsp) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CodedFinalizeRsp.ProtoReflect.Descriptor instead.
+func (*CodedFinalizeRsp) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CodedFinalizeRsp) GetFragment() *CodedFragment {
+	if x != nil {
+		return x.Fragment
+	}
+	return nil
+}
+
+type RegisterClientReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ClientID    string `protobuf:"bytes,1,opt,name=clientID,proto3" json:"clientID,omitempty"`
+	Token       string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`              // random secret of the registering client, renewals have to present it again
+	LeaseMillis uint64 `protobuf:"varint,3,opt,name=leaseMillis,proto3" json:"leaseMillis,omitempty"` // 0 releases the registration
+}
+
+func (x *RegisterClientReq) Reset() {
+	*x = RegisterClientReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegisterClientReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterClientReq) ProtoMessage() {}
+
+func (x *RegisterClientReq) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterClientReq.ProtoReflect.Descriptor instead.
+func (*RegisterClientReq) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RegisterClientReq) GetClientID() string {
+	if x != nil {
+		return x.ClientID
+	}
+	return ""
+}
+
+func (x *RegisterClientReq) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RegisterClientReq) GetLeaseMillis() uint64 {
+	if x != nil {
+		return x.LeaseMillis
+	}
+	return 0
+}
+
+type RegisterClientRsp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *RegisterClientRsp) Reset() {
+	*x = RegisterClientRsp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegisterClientRsp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterClientRsp) ProtoMessage() {}
+
+func (x *RegisterClientRsp) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterClientRsp.ProtoReflect.Descriptor instead.
+func (*RegisterClientRsp) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{15}
+}
+
+type ScanReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Start string `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`  // first key, inclusive
+	End   string `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`      // last key, exclusive, empty for no upper bound
+	Limit uint32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"` // max number of entries, the replica caps it
+}
+
+func (x *ScanReq) Reset() {
+	*x = ScanReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScanReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanReq) ProtoMessage() {}
+
+func (x *ScanReq) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanReq.ProtoReflect.Descriptor instead.
+func (*ScanReq) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ScanReq) GetStart() string {
+	if x != nil {
+		return x.Start
+	}
+	return ""
+}
+
+func (x *ScanReq) GetEnd() string {
+	if x != nil {
+		return x.End
+	}
+	return ""
+}
+
+func (x *ScanReq) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ScanRsp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Entries []*KeyValue `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"` // in key order, including tombstones
+	More    bool        `protobuf:"varint,2,opt,name=more,proto3" json:"more,omitempty"`      // the range has keys after the last entry
+}
+
+func (x *ScanRsp) Reset() {
+	*x = ScanRsp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScanRsp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanRsp) ProtoMessage() {}
+
+func (x *ScanRsp) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanRsp.ProtoReflect.Descriptor instead.
+func (*ScanRsp) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ScanRsp) GetEntries() []*KeyValue {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *ScanRsp) GetMore() bool {
+	if x != nil {
+		return x.More
+	}
+	return false
+}
+
+type KeyValue struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key   string       `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value *StoredValue `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *KeyValue) Reset() {
+	*x = KeyValue{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KeyValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyValue) ProtoMessage() {}
+
+func (x *KeyValue) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyValue.ProtoReflect.Descriptor instead.
+func (*KeyValue) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *KeyValue) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *KeyValue) GetValue() *StoredValue {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type WatchReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key    string     `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Prefix bool       `protobuf:"varint,2,opt,name=prefix,proto3" json:"prefix,omitempty"` // watch every key starting with key
+	FromTs *TimeStamp `protobuf:"bytes,3,opt,name=fromTs,proto3" json:"fromTs,omitempty"`  // only values with a larger timestamp are sent, empty for all
+}
+
+func (x *WatchReq) Reset() {
+	*x = WatchReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchReq) ProtoMessage() {}
+
+func (x *WatchReq) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchReq.ProtoReflect.Descriptor instead.
+func (*WatchReq) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *WatchReq) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *WatchReq) GetPrefix() bool {
+	if x != nil {
+		return x.Prefix
+	}
+	return false
+}
+
+func (x *WatchReq) GetFromTs() *TimeStamp {
+	if x != nil {
+		return x.FromTs
+	}
+	return nil
+}
+
+// PaxosState is the acceptor state of a compare-and-swap instance, as a replica logs it
+type PaxosState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version        *TimeStamp   `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Promised       *TimeStamp   `protobuf:"bytes,2,opt,name=promised,proto3" json:"promised,omitempty"`
+	AcceptedBallot *TimeStamp   `protobuf:"bytes,3,opt,name=acceptedBallot,proto3" json:"acceptedBallot,omitempty"`
+	AcceptedValue  *StoredValue `protobuf:"bytes,4,opt,name=acceptedValue,proto3" json:"acceptedValue,omitempty"`
+	Floor          *TimeStamp   `protobuf:"bytes,5,opt,name=floor,proto3" json:"floor,omitempty"` // largest version of the key whose state the replica dropped
+}
+
+func (x *PaxosState) Reset() {
+	*x = PaxosState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PaxosState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaxosState) ProtoMessage() {}
+
+func (x *PaxosState) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaxosState.ProtoReflect.Descriptor instead.
+func (*PaxosState) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *PaxosState) GetVersion() *TimeStamp {
+	if x != nil {
+		return x.Version
+	}
+	return nil
+}
+
+func (x *PaxosState) GetPromised() *TimeStamp {
+	if x != nil {
+		return x.Promised
+	}
+	return nil
+}
+
+func (x *PaxosState) GetAcceptedBallot() *TimeStamp {
+	if x != nil {
+		return x.AcceptedBallot
+	}
+	return nil
+}
+
+func (x *PaxosState) GetAcceptedValue() *StoredValue {
+	if x != nil {
+		return x.AcceptedValue
+	}
+	return nil
+}
+
+func (x *PaxosState) GetFloor() *TimeStamp {
+	if x != nil {
+		return x.Floor
+	}
+	return nil
+}
+
+// LogRecord is a record of the snapshot and the log of a replica: a stored value, or the acceptor
+// state of an instance. It reads the records of KeyValue.
+type LogRecord struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key   string       `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value *StoredValue `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Paxos *PaxosState  `protobuf:"bytes,3,opt,name=paxos,proto3" json:"paxos,omitempty"`
+}
+
+func (x *LogRecord) Reset() {
+	*x = LogRecord{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogRecord) ProtoMessage() {}
+
+func (x *LogRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogRecord.ProtoReflect.Descriptor instead.
+func (*LogRecord) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *LogRecord) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *LogRecord) GetValue() *StoredValue {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *LogRecord) GetPaxos() *PaxosState {
+	if x != nil {
+		return x.Paxos
+	}
+	return nil
+}
+
+type PaxosPrepareReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key     string     `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Version *TimeStamp `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"` // timestamp of the value the instance replaces, empty if the key was never written
+	Ballot  *TimeStamp `protobuf:"bytes,3,opt,name=ballot,proto3" json:"ballot,omitempty"`   // <round, proposer clientID>
+}
+
+func (x *PaxosPrepareReq) Reset() {
+	*x = PaxosPrepareReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PaxosPrepareReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaxosPrepareReq) ProtoMessage() {}
+
+func (x *PaxosPrepareReq) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaxosPrepareReq.ProtoReflect.Descriptor instead.
+func (*PaxosPrepareReq) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *PaxosPrepareReq) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *PaxosPrepareReq) GetVersion() *TimeStamp {
+	if x != nil {
+		return x.Version
+	}
+	return nil
+}
+
+func (x *PaxosPrepareReq) GetBallot() *TimeStamp {
+	if x != nil {
+		return x.Ballot
+	}
+	return nil
+}
+
+type PaxosPrepareRsp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ok             bool         `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`                        // ballot promised
+	Stale          bool         `protobuf:"varint,2,opt,name=stale,proto3" json:"stale,omitempty"`                  // the replica dropped the instance, or stores a newer value than version
+	Promised       *TimeStamp   `protobuf:"bytes,3,opt,name=promised,proto3" json:"promised,omitempty"`             // largest ballot promised in the instance
+	AcceptedBallot *TimeStamp   `protobuf:"bytes,4,opt,name=acceptedBallot,proto3" json:"acceptedBallot,omitempty"` // ballot of acceptedValue, empty if nothing was accepted
+	AcceptedValue  *StoredValue `protobuf:"bytes,5,opt,name=acceptedValue,proto3" json:"acceptedValue,omitempty"`
+}
+
+func (x *PaxosPrepareRsp) Reset() {
+	*x = PaxosPrepareRsp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PaxosPrepareRsp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaxosPrepareRsp) ProtoMessage() {}
+
+func (x *PaxosPrepareRsp) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaxosPrepareRsp.ProtoReflect.Descriptor instead.
+func (*PaxosPrepareRsp) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *PaxosPrepareRsp) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *PaxosPrepareRsp) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+func (x *PaxosPrepareRsp) GetPromised() *TimeStamp {
+	if x != nil {
+		return x.Promised
+	}
+	return nil
+}
+
+func (x *PaxosPrepareRsp) GetAcceptedBallot() *TimeStamp {
+	if x != nil {
+		return x.AcceptedBallot
+	}
+	return nil
+}
+
+func (x *PaxosPrepareRsp) GetAcceptedValue() *StoredValue {
+	if x != nil {
+		return x.AcceptedValue
+	}
+	return nil
+}
+
+type PaxosAcceptReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key     string       `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Version *TimeStamp   `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Ballot  *TimeStamp   `protobuf:"bytes,3,opt,name=ballot,proto3" json:"ballot,omitempty"`
+	Value   *StoredValue `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"` // the new value, with the timestamp it is written with once chosen
+}
+
+func (x *PaxosAcceptReq) Reset() {
+	*x = PaxosAcceptReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PaxosAcceptReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaxosAcceptReq) ProtoMessage() {}
+
+func (x *PaxosAcceptReq) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaxosAcceptReq.ProtoReflect.Descriptor instead.
+func (*PaxosAcceptReq) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *PaxosAcceptReq) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *PaxosAcceptReq) GetVersion() *TimeStamp {
+	if x != nil {
+		return x.Version
+	}
+	return nil
+}
+
+func (x *PaxosAcceptReq) GetBallot() *TimeStamp {
+	if x != nil {
+		return x.Ballot
+	}
+	return nil
+}
+
+func (x *PaxosAcceptReq) GetValue() *StoredValue {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type PaxosAcceptRsp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ok       bool       `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Stale    bool       `protobuf:"varint,2,opt,name=stale,proto3" json:"stale,omitempty"`
+	Promised *TimeStamp `protobuf:"bytes,3,opt,name=promised,proto3" json:"promised,omitempty"`
+}
+
+func (x *PaxosAcceptRsp) Reset() {
+	*x = PaxosAcceptRsp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PaxosAcceptRsp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaxosAcceptRsp) ProtoMessage() {}
+
+func (x *PaxosAcceptRsp) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaxosAcceptRsp.ProtoReflect.Descriptor instead.
+func (*PaxosAcceptRsp) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *PaxosAcceptRsp) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *PaxosAcceptRsp) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+func (x *PaxosAcceptRsp) GetPromised() *TimeStamp {
+	if x != nil {
+		return x.Promised
+	}
+	return nil
+}
+
+type DumpValueReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (x *DumpValueReq) Reset() {
+	*x = DumpValueReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DumpValueReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DumpValueReq) ProtoMessage() {}
+
+func (x *DumpValueReq) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DumpValueReq.ProtoReflect.Descriptor instead.
+func (*DumpValueReq) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *DumpValueReq) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type DumpValueRsp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value *StoredValue `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"` // empty if the replica never stored the key
+}
+
+func (x *DumpValueRsp) Reset() {
+	*x = DumpValueRsp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DumpValueRsp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DumpValueRsp) ProtoMessage() {}
+
+func (x *DumpValueRsp) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DumpValueRsp.ProtoReflect.Descriptor instead.
+func (*DumpValueRsp) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DumpValueRsp) GetValue() *StoredValue {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type CountKeysReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Prefix string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+}
+
+func (x *CountKeysReq) Reset() {
+	*x = CountKeysReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CountKeysReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountKeysReq) ProtoMessage() {}
+
+func (x *CountKeysReq) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountKeysReq.ProtoReflect.Descriptor instead.
+func (*CountKeysReq) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *CountKeysReq) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type CountKeysRsp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Keys     uint64 `protobuf:"varint,1,opt,name=keys,proto3" json:"keys,omitempty"`         // keys starting with prefix, tombstones and internal keys included
+	Deleted  uint64 `protobuf:"varint,2,opt,name=deleted,proto3" json:"deleted,omitempty"`   // keys that are tombstones or expired
+	Internal uint64 `protobuf:"varint,3,opt,name=internal,proto3" json:"internal,omitempty"` // internal keys, e.g. chunks of large values
+}
+
+func (x *CountKeysRsp) Reset() {
+	*x = CountKeysRsp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CountKeysRsp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountKeysRsp) ProtoMessage() {}
+
+func (x *CountKeysRsp) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountKeysRsp.ProtoReflect.Descriptor instead.
+func (*CountKeysRsp) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *CountKeysRsp) GetKeys() uint64 {
+	if x != nil {
+		return x.Keys
+	}
+	return 0
+}
+
+func (x *CountKeysRsp) GetDeleted() uint64 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+func (x *CountKeysRsp) GetInternal() uint64 {
+	if x != nil {
+		return x.Internal
+	}
+	return 0
+}
+
+type ListKeysReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Prefix     string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	StartAfter string `protobuf:"bytes,2,opt,name=startAfter,proto3" json:"startAfter,omitempty"` // last key of the previous page, empty for the first page
+	Limit      uint32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`          // max number of keys, the replica caps it
+	Internal   bool   `protobuf:"varint,4,opt,name=internal,proto3" json:"internal,omitempty"`    // list internal keys too
+}
+
+func (x *ListKeysReq) Reset() {
+	*x = ListKeysReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListKeysReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListKeysReq) ProtoMessage() {}
+
+func (x *ListKeysReq) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListKeysReq.ProtoReflect.Descriptor instead.
+func (*ListKeysReq) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ListKeysReq) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *ListKeysReq) GetStartAfter() string {
+	if x != nil {
+		return x.StartAfter
+	}
+	return ""
+}
+
+func (x *ListKeysReq) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListKeysReq) GetInternal() bool {
+	if x != nil {
+		return x.Internal
+	}
+	return false
+}
+
+type ListKeysRsp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Keys []string `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`  // in key order
+	More bool     `protobuf:"varint,2,opt,name=more,proto3" json:"more,omitempty"` // the prefix has keys after the last one
+}
+
+func (x *ListKeysRsp) Reset() {
+	*x = ListKeysRsp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListKeysRsp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListKeysRsp) ProtoMessage() {}
+
+func (x *ListKeysRsp) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListKeysRsp.ProtoReflect.Descriptor instead.
+func (*ListKeysRsp) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ListKeysRsp) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *ListKeysRsp) GetMore() bool {
+	if x != nil {
+		return x.More
+	}
+	return false
+}
+
+type CompactReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *CompactReq) Reset() {
+	*x = CompactReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompactReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompactReq) ProtoMessage() {}
+
+func (x *CompactReq) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompactReq.ProtoReflect.Descriptor instead.
+func (*CompactReq) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{32}
+}
+
+type CompactRsp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Registers uint64 `protobuf:"varint,1,opt,name=registers,proto3" json:"registers,omitempty"` // number of registers in the new snapshot
+}
+
+func (x *CompactRsp) Reset() {
+	*x = CompactRsp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompactRsp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompactRsp) ProtoMessage() {}
+
+func (x *CompactRsp) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompactRsp.ProtoReflect.Descriptor instead.
+func (*CompactRsp) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *CompactRsp) GetRegisters() uint64 {
+	if x != nil {
+		return x.Registers
+	}
+	return 0
+}
+
+type SetLogLevelReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Level LogLevel `protobuf:"varint,1,opt,name=level,proto3,enum=LogLevel" json:"level,omitempty"`
+}
+
+func (x *SetLogLevelReq) Reset() {
+	*x = SetLogLevelReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetLogLevelReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLogLevelReq) ProtoMessage() {}
+
+func (x *SetLogLevelReq) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLogLevelReq.ProtoReflect.Descriptor instead.
+func (*SetLogLevelReq) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *SetLogLevelReq) GetLevel() LogLevel {
+	if x != nil {
+		return x.Level
+	}
+	return LogLevel_INFO
+}
+
+type SetLogLevelRsp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Previous LogLevel `protobuf:"varint,1,opt,name=previous,proto3,enum=LogLevel" json:"previous,omitempty"`
+}
+
+func (x *SetLogLevelRsp) Reset() {
+	*x = SetLogLevelRsp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetLogLevelRsp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLogLevelRsp) ProtoMessage() {}
+
+func (x *SetLogLevelRsp) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLogLevelRsp.ProtoReflect.Descriptor instead.
+func (*SetLogLevelRsp) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *SetLogLevelRsp) GetPrevious() LogLevel {
+	if x != nil {
+		return x.Previous
+	}
+	return LogLevel_INFO
+}
+
+type SetModeReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Mode ReplicaMode `protobuf:"varint,1,opt,name=mode,proto3,enum=ReplicaMode" json:"mode,omitempty"`
+}
+
+func (x *SetModeReq) Reset() {
+	*x = SetModeReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetModeReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetModeReq) ProtoMessage() {}
+
+func (x *SetModeReq) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetModeReq.ProtoReflect.Descriptor instead.
+func (*SetModeReq) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *SetModeReq) GetMode() ReplicaMode {
+	if x != nil {
+		return x.Mode
+	}
+	return ReplicaMode_SERVING
+}
+
+type SetModeRsp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Previous ReplicaMode `protobuf:"varint,1,opt,name=previous,proto3,enum=ReplicaMode" json:"previous,omitempty"`
+}
+
+func (x *SetModeRsp) Reset() {
+	*x = SetModeRsp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetModeRsp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetModeRsp) ProtoMessage() {}
+
+func (x *SetModeRsp) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetModeRsp.ProtoReflect.Descriptor instead.
+func (*SetModeRsp) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *SetModeRsp) GetPrevious() ReplicaMode {
+	if x != nil {
+		return x.Previous
+	}
+	return ReplicaMode_SERVING
+}
+
+type GetInfoReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetInfoReq) Reset() {
+	*x = GetInfoReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetInfoReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInfoReq) ProtoMessage() {}
+
+func (x *GetInfoReq) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInfoReq.ProtoReflect.Descriptor instead.
+func (*GetInfoReq) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{38}
+}
+
+type GetInfoRsp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name            string      `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // the name in the audit log
+	Build           *BuildInfo  `protobuf:"bytes,2,opt,name=build,proto3" json:"build,omitempty"`
+	StartedAt       uint64      `protobuf:"varint,3,opt,name=startedAt,proto3" json:"startedAt,omitempty"` // unix nanoseconds
+	UptimeMillis    uint64      `protobuf:"varint,4,opt,name=uptimeMillis,proto3" json:"uptimeMillis,omitempty"`
+	Mode            ReplicaMode `protobuf:"varint,5,opt,name=mode,proto3,enum=ReplicaMode" json:"mode,omitempty"`
+	LogLevel        LogLevel    `protobuf:"varint,6,opt,name=logLevel,proto3,enum=LogLevel" json:"logLevel,omitempty"`
+	DataDir         string      `protobuf:"bytes,7,opt,name=dataDir,proto3" json:"dataDir,omitempty"` // empty if the registers are only kept in memory
+	SnapshotRecords uint64      `protobuf:"varint,8,opt,name=snapshotRecords,proto3" json:"snapshotRecords,omitempty"`
+	LogRecords      uint64      `protobuf:"varint,9,opt,name=logRecords,proto3" json:"logRecords,omitempty"` // records logged since the snapshot
+}
+
+func (x *GetInfoRsp) Reset() {
+	*x = GetInfoRsp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetInfoRsp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInfoRsp) ProtoMessage() {}
+
+func (x *GetInfoRsp) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInfoRsp.ProtoReflect.Descriptor instead.
+func (*GetInfoRsp) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *GetInfoRsp) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GetInfoRsp) GetBuild() *BuildInfo {
+	if x != nil {
+		return x.Build
+	}
+	return nil
+}
+
+func (x *GetInfoRsp) GetStartedAt() uint64 {
+	if x != nil {
+		return x.StartedAt
+	}
+	return 0
+}
+
+func (x *GetInfoRsp) GetUptimeMillis() uint64 {
+	if x != nil {
+		return x.UptimeMillis
+	}
+	return 0
+}
+
+func (x *GetInfoRsp) GetMode() ReplicaMode {
+	if x != nil {
+		return x.Mode
+	}
+	return ReplicaMode_SERVING
+}
+
+func (x *GetInfoRsp) GetLogLevel() LogLevel {
+	if x != nil {
+		return x.LogLevel
+	}
+	return LogLevel_INFO
+}
+
+func (x *GetInfoRsp) GetDataDir() string {
+	if x != nil {
+		return x.DataDir
+	}
+	return ""
+}
+
+func (x *GetInfoRsp) GetSnapshotRecords() uint64 {
+	if x != nil {
+		return x.SnapshotRecords
+	}
+	return 0
+}
+
+func (x *GetInfoRsp) GetLogRecords() uint64 {
+	if x != nil {
+		return x.LogRecords
+	}
+	return 0
+}
+
+type RepairValueReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key   string       `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value *StoredValue `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *RepairValueReq) Reset() {
+	*x = RepairValueReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[40]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RepairValueReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RepairValueReq) ProtoMessage() {}
+
+func (x *RepairValueReq) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[40]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RepairValueReq.ProtoReflect.Descriptor instead.
+func (*RepairValueReq) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *RepairValueReq) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *RepairValueReq) GetValue() *StoredValue {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type RepairValueRsp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stored bool `protobuf:"varint,1,opt,name=stored,proto3" json:"stored,omitempty"` // false if the replica already had the value or a newer one
+}
+
+func (x *RepairValueRsp) Reset() {
+	*x = RepairValueRsp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[41]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RepairValueRsp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RepairValueRsp) ProtoMessage() {}
+
+func (x *RepairValueRsp) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[41]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RepairValueRsp.ProtoReflect.Descriptor instead.
+func (*RepairValueRsp) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *RepairValueRsp) GetStored() bool {
+	if x != nil {
+		return x.Stored
+	}
+	return false
+}
+
+type BuildInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GoVersion    string `protobuf:"bytes,1,opt,name=goVersion,proto3" json:"goVersion,omitempty"`
+	Path         string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`         // main package
+	Version      string `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`   // module version, (devel) unless built from a tagged module
+	Revision     string `protobuf:"bytes,4,opt,name=revision,proto3" json:"revision,omitempty"` // VCS revision the binary was built from, if known
+	RevisionTime string `protobuf:"bytes,5,opt,name=revisionTime,proto3" json:"revisionTime,omitempty"`
+	Modified     bool   `protobuf:"varint,6,opt,name=modified,proto3" json:"modified,omitempty"` // built from a working tree with local changes
+}
+
+func (x *BuildInfo) Reset() {
+	*x = BuildInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_request_proto_msgTypes[42]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BuildInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuildInfo) ProtoMessage() {}
+
+func (x *BuildInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[42]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuildInfo.ProtoReflect.Descriptor instead.
+func (*BuildInfo) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *BuildInfo) GetGoVersion() string {
+	if x != nil {
+		return x.GoVersion
+	}
+	return ""
+}
+
+func (x *BuildInfo) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *BuildInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *BuildInfo) GetRevision() string {
+	if x != nil {
+		return x.Revision
+	}
+	return ""
+}
+
+func (x *BuildInfo) GetRevisionTime() string {
+	if x != nil {
+		return x.RevisionTime
+	}
+	return ""
+}
+
+func (x *BuildInfo) GetModified() bool {
+	if x != nil {
+		return x.Modified
+	}
+	return false
+}
+
+var File_request_proto protoreflect.FileDescriptor
+
+var file_request_proto_rawDesc = []byte{
+	0x0a, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
+	0x1f, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x22, 0x31, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x52, 0x73, 0x70, 0x12,
+	0x22, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c,
+	0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x22, 0x9b, 0x02, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x03, 0x76, 0x61, 0x6c, 0x12, 0x1a, 0x0a, 0x02, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0a, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x74,
+	0x73, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12, 0x2a, 0x0a,
+	0x08, 0x6d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0e, 0x2e, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x52,
+	0x08, 0x6d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x0b, 0x63, 0x6f, 0x6d,
+	0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c,
+	0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x63, 0x6f,
+	0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x65, 0x78, 0x70,
+	0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x65, 0x78,
+	0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6b, 0x65, 0x79, 0x49, 0x44,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6b, 0x65, 0x79, 0x49, 0x44, 0x12, 0x12, 0x0a,
+	0x04, 0x73, 0x65, 0x61, 0x6c, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x73, 0x65, 0x61,
+	0x6c, 0x22, 0x55, 0x0a, 0x0d, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65,
+	0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x72, 0x69, 0x74, 0x65, 0x49, 0x44, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x77, 0x72, 0x69, 0x74, 0x65, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06,
+	0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x63, 0x68,
+	0x75, 0x6e, 0x6b, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x22, 0x75, 0x0a, 0x0b, 0x53, 0x65, 0x74, 0x50,
+	0x68, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x22, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65,
+	0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b,
+	0x65, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x22,
+	0x0d, 0x0a, 0x0b, 0x53, 0x65, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x52, 0x73, 0x70, 0x22, 0x83,
+	0x01, 0x0a, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x24, 0x0a, 0x0d,
+	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x4e, 0x75, 0x6d, 0x62,
+	0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x12, 0x1a,
+	0x0a, 0x08, 0x77, 0x61, 0x6c, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x08, 0x77, 0x61, 0x6c, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x6f,
+	0x67, 0x69, 0x63, 0x61, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x6c, 0x6f, 0x67,
+	0x69, 0x63, 0x61, 0x6c, 0x22, 0xc1, 0x01, 0x0a, 0x0d, 0x43, 0x6f, 0x64, 0x65, 0x64, 0x46, 0x72,
+	0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e,
+	0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x61, 0x74, 0x61, 0x53, 0x68, 0x61, 0x72, 0x64, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x64, 0x61, 0x74, 0x61, 0x53, 0x68, 0x61, 0x72, 0x64, 0x73,
+	0x12, 0x20, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x53, 0x68, 0x61, 0x72, 0x64, 0x73, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x53, 0x68, 0x61, 0x72,
+	0x64, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x53, 0x69, 0x7a, 0x65,
+	0x12, 0x20, 0x0a, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c,
+	0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x04, 0x6d, 0x65,
+	0x74, 0x61, 0x4a, 0x04, 0x08, 0x06, 0x10, 0x07, 0x22, 0x21, 0x0a, 0x0d, 0x43, 0x6f, 0x64, 0x65,
+	0x64, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x71, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x22, 0x4d, 0x0a, 0x0d, 0x43,
+	0x6f, 0x64, 0x65, 0x64, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x73, 0x70, 0x12, 0x1a, 0x0a, 0x02,
+	0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x74, 0x73, 0x12, 0x20, 0x0a, 0x04, 0x6d, 0x65, 0x74, 0x61,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x52, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x22, 0x6c, 0x0a, 0x10, 0x43, 0x6f,
+	0x64, 0x65, 0x64, 0x50, 0x72, 0x65, 0x57, 0x72, 0x69, 0x74, 0x65, 0x52, 0x65, 0x71, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x1a, 0x0a, 0x02, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x74, 0x73, 0x12, 0x2a, 0x0a, 0x08,
+	0x66, 0x72, 0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e,
+	0x2e, 0x43, 0x6f, 0x64, 0x65, 0x64, 0x46, 0x72, 0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x08,
+	0x66, 0x72, 0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x12, 0x0a, 0x10, 0x43, 0x6f, 0x64, 0x65,
+	0x64, 0x50, 0x72, 0x65, 0x57, 0x72, 0x69, 0x74, 0x65, 0x52, 0x73, 0x70, 0x22, 0x64, 0x0a, 0x10,
+	0x43, 0x6f, 0x64, 0x65, 0x64, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x52, 0x65, 0x71,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x1a, 0x0a, 0x02, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x74, 0x73, 0x12, 0x22,
+	0x0a, 0x0c, 0x77, 0x61, 0x6e, 0x74, 0x46, 0x72, 0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x77, 0x61, 0x6e, 0x74, 0x46, 0x72, 0x61, 0x67, 0x6d, 0x65,
+	0x6e, 0x74, 0x22, 0x3e, 0x0a, 0x10, 0x43, 0x6f, 0x64, 0x65, 0x64, 0x46, 0x69, 0x6e, 0x61, 0x6c,
+	0x69, 0x7a, 0x65, 0x52, 0x73, 0x70, 0x12, 0x2a, 0x0a, 0x08, 0x66, 0x72, 0x61, 0x67, 0x6d, 0x65,
+	0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x43, 0x6f, 0x64, 0x65, 0x64,
+	0x46, 0x72, 0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x08, 0x66, 0x72, 0x61, 0x67, 0x6d, 0x65,
+	0x6e, 0x74, 0x22, 0x67, 0x0a, 0x11, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x43, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e,
+	0x74, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e,
+	0x74, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x6c, 0x65, 0x61,
+	0x73, 0x65, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b,
+	0x6c, 0x65, 0x61, 0x73, 0x65, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x22, 0x13, 0x0a, 0x11, 0x52,
+	0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x52, 0x73, 0x70,
+	0x22, 0x47, 0x0a, 0x07, 0x53, 0x63, 0x61, 0x6e, 0x52, 0x65, 0x71, 0x12, 0x14, 0x0a, 0x05, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x65, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x42, 0x0a, 0x07, 0x53, 0x63, 0x61,
+	0x6e, 0x52, 0x73, 0x70, 0x12, 0x23, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x4b, 0x65, 0x79, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f, 0x72,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x6d, 0x6f, 0x72, 0x65, 0x22, 0x40, 0x0a,
+	0x08, 0x4b, 0x65, 0x79, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x22, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x53, 0x74, 0x6f,
+	0x72, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22,
+	0x58, 0x0a, 0x08, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x16, 0x0a,
+	0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x70,
+	0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x22, 0x0a, 0x06, 0x66, 0x72, 0x6f, 0x6d, 0x54, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x06, 0x66, 0x72, 0x6f, 0x6d, 0x54, 0x73, 0x22, 0xe4, 0x01, 0x0a, 0x0a, 0x50, 0x61,
+	0x78, 0x6f, 0x73, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x24, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x53, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x26,
+	0x0a, 0x08, 0x70, 0x72, 0x6f, 0x6d, 0x69, 0x73, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0a, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x70, 0x72,
+	0x6f, 0x6d, 0x69, 0x73, 0x65, 0x64, 0x12, 0x32, 0x0a, 0x0e, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74,
+	0x65, 0x64, 0x42, 0x61, 0x6c, 0x6c, 0x6f, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0e, 0x61, 0x63, 0x63, 0x65,
+	0x70, 0x74, 0x65, 0x64, 0x42, 0x61, 0x6c, 0x6c, 0x6f, 0x74, 0x12, 0x32, 0x0a, 0x0d, 0x61, 0x63,
+	0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0c, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52,
+	0x0d, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x20,
+	0x0a, 0x05, 0x66, 0x6c, 0x6f, 0x6f, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x66, 0x6c, 0x6f, 0x6f, 0x72,
+	0x22, 0x64, 0x0a, 0x09, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x22, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c,
+	0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x12, 0x21, 0x0a, 0x05, 0x70, 0x61, 0x78, 0x6f, 0x73, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x50, 0x61, 0x78, 0x6f, 0x73, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
+	0x05, 0x70, 0x61, 0x78, 0x6f, 0x73, 0x22, 0x6d, 0x0a, 0x0f, 0x50, 0x61, 0x78, 0x6f, 0x73, 0x50,
+	0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x52, 0x65, 0x71, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x24, 0x0a, 0x07, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x22, 0x0a, 0x06, 0x62, 0x61, 0x6c, 0x6c, 0x6f, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0a, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x06, 0x62,
+	0x61, 0x6c, 0x6c, 0x6f, 0x74, 0x22, 0xc7, 0x01, 0x0a, 0x0f, 0x50, 0x61, 0x78, 0x6f, 0x73, 0x50,
+	0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x52, 0x73, 0x70, 0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x6b, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x02, 0x6f, 0x6b, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61,
+	0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x73, 0x74, 0x61, 0x6c, 0x65, 0x12,
+	0x26, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x6d, 0x69, 0x73, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0a, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x70,
+	0x72, 0x6f, 0x6d, 0x69, 0x73, 0x65, 0x64, 0x12, 0x32, 0x0a, 0x0e, 0x61, 0x63, 0x63, 0x65, 0x70,
+	0x74, 0x65, 0x64, 0x42, 0x61, 0x6c, 0x6c, 0x6f, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0a, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0e, 0x61, 0x63, 0x63,
+	0x65, 0x70, 0x74, 0x65, 0x64, 0x42, 0x61, 0x6c, 0x6c, 0x6f, 0x74, 0x12, 0x32, 0x0a, 0x0d, 0x61,
+	0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x52, 0x0d, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x22,
+	0x90, 0x01, 0x0a, 0x0e, 0x50, 0x61, 0x78, 0x6f, 0x73, 0x41, 0x63, 0x63, 0x65, 0x70, 0x74, 0x52,
+	0x65, 0x71, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x24, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x22, 0x0a, 0x06, 0x62, 0x61,
+	0x6c, 0x6c, 0x6f, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x06, 0x62, 0x61, 0x6c, 0x6c, 0x6f, 0x74, 0x12, 0x22,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e,
+	0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x22, 0x5e, 0x0a, 0x0e, 0x50, 0x61, 0x78, 0x6f, 0x73, 0x41, 0x63, 0x63, 0x65, 0x70,
+	0x74, 0x52, 0x73, 0x70, 0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x02, 0x6f, 0x6b, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x6c, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x05, 0x73, 0x74, 0x61, 0x6c, 0x65, 0x12, 0x26, 0x0a, 0x08, 0x70, 0x72,
+	0x6f, 0x6d, 0x69, 0x73, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x6d, 0x69, 0x73,
+	0x65, 0x64, 0x22, 0x20, 0x0a, 0x0c, 0x44, 0x75, 0x6d, 0x70, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52,
+	0x65, 0x71, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x22, 0x32, 0x0a, 0x0c, 0x44, 0x75, 0x6d, 0x70, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x52, 0x73, 0x70, 0x12, 0x22, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x26, 0x0a, 0x0c, 0x43, 0x6f, 0x75, 0x6e,
+	0x74, 0x4b, 0x65, 0x79, 0x73, 0x52, 0x65, 0x71, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66,
+	0x69, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78,
+	0x22, 0x58, 0x0a, 0x0c, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4b, 0x65, 0x79, 0x73, 0x52, 0x73, 0x70,
+	0x12, 0x12, 0x0a, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04,
+	0x6b, 0x65, 0x79, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12, 0x1a,
+	0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x22, 0x77, 0x0a, 0x0b, 0x4c, 0x69,
+	0x73, 0x74, 0x4b, 0x65, 0x79, 0x73, 0x52, 0x65, 0x71, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x65,
+	0x66, 0x69, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69,
+	0x78, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x41, 0x66, 0x74, 0x65, 0x72, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x41, 0x66, 0x74, 0x65,
+	0x72, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x6e, 0x61, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x6e, 0x61, 0x6c, 0x22, 0x35, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x4b, 0x65, 0x79, 0x73, 0x52,
+	0x73, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f, 0x72, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x6d, 0x6f, 0x72, 0x65, 0x22, 0x0c, 0x0a, 0x0a, 0x43, 0x6f,
+	0x6d, 0x70, 0x61, 0x63, 0x74, 0x52, 0x65, 0x71, 0x22, 0x2a, 0x0a, 0x0a, 0x43, 0x6f, 0x6d, 0x70,
+	0x61, 0x63, 0x74, 0x52, 0x73, 0x70, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74,
+	0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x72, 0x65, 0x67, 0x69, 0x73,
+	0x74, 0x65, 0x72, 0x73, 0x22, 0x31, 0x0a, 0x0e, 0x53, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65,
+	0x76, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x12, 0x1f, 0x0a, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x09, 0x2e, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c,
+	0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x22, 0x37, 0x0a, 0x0e, 0x53, 0x65, 0x74, 0x4c, 0x6f,
+	0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x73, 0x70, 0x12, 0x25, 0x0a, 0x08, 0x70, 0x72, 0x65,
+	0x76, 0x69, 0x6f, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x09, 0x2e, 0x4c, 0x6f,
+	0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x08, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73,
+	0x22, 0x2e, 0x0a, 0x0a, 0x53, 0x65, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x12, 0x20,
+	0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x52,
+	0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65,
+	0x22, 0x36, 0x0a, 0x0a, 0x53, 0x65, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x73, 0x70, 0x12, 0x28,
+	0x0a, 0x08, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x0c, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x08,
+	0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x22, 0x0c, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x49,
+	0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x22, 0xb1, 0x02, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x49, 0x6e,
+	0x66, 0x6f, 0x52, 0x73, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x05, 0x62, 0x75, 0x69,
+	0x6c, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x42, 0x75, 0x69, 0x6c, 0x64,
+	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x75, 0x70, 0x74,
+	0x69, 0x6d, 0x65, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x0c, 0x75, 0x70, 0x74, 0x69, 0x6d, 0x65, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x12, 0x20, 0x0a,
+	0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x52, 0x65,
+	0x70, 0x6c, 0x69, 0x63, 0x61, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12,
+	0x25, 0x0a, 0x08, 0x6c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x09, 0x2e, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x08, 0x6c, 0x6f,
+	0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x61, 0x74, 0x61, 0x44, 0x69,
+	0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x64, 0x61, 0x74, 0x61, 0x44, 0x69, 0x72,
+	0x12, 0x28, 0x0a, 0x0f, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x73, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x6c, 0x6f,
+	0x67, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a,
+	0x6c, 0x6f, 0x67, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x22, 0x46, 0x0a, 0x0e, 0x52, 0x65,
+	0x70, 0x61, 0x69, 0x72, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x65, 0x71, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x22,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e,
+	0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x22, 0x28, 0x0a, 0x0e, 0x52, 0x65, 0x70, 0x61, 0x69, 0x72, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x52, 0x73, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x22, 0xb3, 0x01, 0x0a,
+	0x09, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1c, 0x0a, 0x09, 0x67, 0x6f,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x67,
+	0x6f, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x18, 0x0a, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x22, 0x0a, 0x0c, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x54, 0x69,
+	0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69,
+	0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69,
+	0x65, 0x64, 0x2a, 0x21, 0x0a, 0x0b, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x08, 0x0a, 0x04, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x47,
+	0x5a, 0x49, 0x50, 0x10, 0x01, 0x2a, 0x1f, 0x0a, 0x08, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65,
+	0x6c, 0x12, 0x08, 0x0a, 0x04, 0x49, 0x4e, 0x46, 0x4f, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x44,
+	0x45, 0x42, 0x55, 0x47, 0x10, 0x01, 0x2a, 0x37, 0x0a, 0x0b, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63,
+	0x61, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x53, 0x45, 0x52, 0x56, 0x49, 0x4e, 0x47,
+	0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x52, 0x45, 0x41, 0x44, 0x5f, 0x4f, 0x4e, 0x4c, 0x59, 0x10,
+	0x01, 0x12, 0x0c, 0x0a, 0x08, 0x44, 0x52, 0x41, 0x49, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x32,
+	0xed, 0x03, 0x0a, 0x0f, 0x53, 0x68, 0x61, 0x72, 0x65, 0x64, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74,
+	0x65, 0x72, 0x73, 0x12, 0x28, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x12,
+	0x0c, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x0c, 0x2e,
+	0x47, 0x65, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x52, 0x73, 0x70, 0x22, 0x00, 0x12, 0x28, 0x0a,
+	0x08, 0x53, 0x65, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x12, 0x0c, 0x2e, 0x53, 0x65, 0x74, 0x50,
+	0x68, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x0c, 0x2e, 0x53, 0x65, 0x74, 0x50, 0x68, 0x61,
+	0x73, 0x65, 0x52, 0x73, 0x70, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x0a, 0x43, 0x6f, 0x64, 0x65, 0x64,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x0e, 0x2e, 0x43, 0x6f, 0x64, 0x65, 0x64, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x52, 0x65, 0x71, 0x1a, 0x0e, 0x2e, 0x43, 0x6f, 0x64, 0x65, 0x64, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x52, 0x73, 0x70, 0x22, 0x00, 0x12, 0x37, 0x0a, 0x0d, 0x43, 0x6f, 0x64, 0x65, 0x64,
+	0x50, 0x72, 0x65, 0x57, 0x72, 0x69, 0x74, 0x65, 0x12, 0x11, 0x2e, 0x43, 0x6f, 0x64, 0x65, 0x64,
+	0x50, 0x72, 0x65, 0x57, 0x72, 0x69, 0x74, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x11, 0x2e, 0x43, 0x6f,
+	0x64, 0x65, 0x64, 0x50, 0x72, 0x65, 0x57, 0x72, 0x69, 0x74, 0x65, 0x52, 0x73, 0x70, 0x22, 0x00,
+	0x12, 0x37, 0x0a, 0x0d, 0x43, 0x6f, 0x64, 0x65, 0x64, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a,
+	0x65, 0x12, 0x11, 0x2e, 0x43, 0x6f, 0x64, 0x65, 0x64, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a,
+	0x65, 0x52, 0x65, 0x71, 0x1a, 0x11, 0x2e, 0x43, 0x6f, 0x64, 0x65, 0x64, 0x46, 0x69, 0x6e, 0x61,
+	0x6c, 0x69, 0x7a, 0x65, 0x52, 0x73, 0x70, 0x22, 0x00, 0x12, 0x3a, 0x0a, 0x0e, 0x52, 0x65, 0x67,
+	0x69, 0x73, 0x74, 0x65, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x2e, 0x52, 0x65,
+	0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x1a,
+	0x12, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x52, 0x73, 0x70, 0x22, 0x00, 0x12, 0x1c, 0x0a, 0x04, 0x53, 0x63, 0x61, 0x6e, 0x12, 0x08, 0x2e,
+	0x53, 0x63, 0x61, 0x6e, 0x52, 0x65, 0x71, 0x1a, 0x08, 0x2e, 0x53, 0x63, 0x61, 0x6e, 0x52, 0x73,
+	0x70, 0x22, 0x00, 0x12, 0x21, 0x0a, 0x05, 0x57, 0x61, 0x74, 0x63, 0x68, 0x12, 0x09, 0x2e, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x1a, 0x09, 0x2e, 0x4b, 0x65, 0x79, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x34, 0x0a, 0x0c, 0x50, 0x61, 0x78, 0x6f, 0x73, 0x50,
+	0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x12, 0x10, 0x2e, 0x50, 0x61, 0x78, 0x6f, 0x73, 0x50, 0x72,
+	0x65, 0x70, 0x61, 0x72, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x10, 0x2e, 0x50, 0x61, 0x78, 0x6f, 0x73,
+	0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x52, 0x73, 0x70, 0x22, 0x00, 0x12, 0x31, 0x0a, 0x0b,
+	0x50, 0x61, 0x78, 0x6f, 0x73, 0x41, 0x63, 0x63, 0x65, 0x70, 0x74, 0x12, 0x0f, 0x2e, 0x50, 0x61,
+	0x78, 0x6f, 0x73, 0x41, 0x63, 0x63, 0x65, 0x70, 0x74, 0x52, 0x65, 0x71, 0x1a, 0x0f, 0x2e, 0x50,
+	0x61, 0x78, 0x6f, 0x73, 0x41, 0x63, 0x63, 0x65, 0x70, 0x74, 0x52, 0x73, 0x70, 0x22, 0x00, 0x32,
+	0xe6, 0x02, 0x0a, 0x05, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x12, 0x2b, 0x0a, 0x09, 0x44, 0x75, 0x6d,
+	0x70, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x0d, 0x2e, 0x44, 0x75, 0x6d, 0x70, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x0d, 0x2e, 0x44, 0x75, 0x6d, 0x70, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x52, 0x73, 0x70, 0x22, 0x00, 0x12, 0x2b, 0x0a, 0x09, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4b,
+	0x65, 0x79, 0x73, 0x12, 0x0d, 0x2e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4b, 0x65, 0x79, 0x73, 0x52,
+	0x65, 0x71, 0x1a, 0x0d, 0x2e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4b, 0x65, 0x79, 0x73, 0x52, 0x73,
+	0x70, 0x22, 0x00, 0x12, 0x28, 0x0a, 0x08, 0x4c, 0x69, 0x73, 0x74, 0x4b, 0x65, 0x79, 0x73, 0x12,
+	0x0c, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4b, 0x65, 0x79, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x0c, 0x2e,
+	0x4c, 0x69, 0x73, 0x74, 0x4b, 0x65, 0x79, 0x73, 0x52, 0x73, 0x70, 0x22, 0x00, 0x12, 0x25, 0x0a,
+	0x07, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x12, 0x0b, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x61,
+	0x63, 0x74, 0x52, 0x65, 0x71, 0x1a, 0x0b, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x52,
+	0x73, 0x70, 0x22, 0x00, 0x12, 0x31, 0x0a, 0x0b, 0x53, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65,
+	0x76, 0x65, 0x6c, 0x12, 0x0f, 0x2e, 0x53, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65,
+	0x6c, 0x52, 0x65, 0x71, 0x1a, 0x0f, 0x2e, 0x53, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76,
+	0x65, 0x6c, 0x52, 0x73, 0x70, 0x22, 0x00, 0x12, 0x25, 0x0a, 0x07, 0x53, 0x65, 0x74, 0x4d, 0x6f,
+	0x64, 0x65, 0x12, 0x0b, 0x2e, 0x53, 0x65, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x1a,
+	0x0b, 0x2e, 0x53, 0x65, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x73, 0x70, 0x22, 0x00, 0x12, 0x25,
+	0x0a, 0x07, 0x47, 0x65, 0x74, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0b, 0x2e, 0x47, 0x65, 0x74, 0x49,
+	0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x1a, 0x0b, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x6e, 0x66, 0x6f,
+	0x52, 0x73, 0x70, 0x22, 0x00, 0x12, 0x31, 0x0a, 0x0b, 0x52, 0x65, 0x70, 0x61, 0x69, 0x72, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x12, 0x0f, 0x2e, 0x52, 0x65, 0x70, 0x61, 0x69, 0x72, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x0f, 0x2e, 0x52, 0x65, 0x70, 0x61, 0x69, 0x72, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x52, 0x73, 0x70, 0x22, 0x00, 0x42, 0x11, 0x5a, 0x0f, 0x2e, 0x2e, 0x2f, 0x63,
+	0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_request_proto_rawDescOnce sync.Once
+	file_request_proto_rawDescData = file_request_proto_rawDesc
+)
+
+func file_request_proto_rawDescGZIP() []byte {
+	file_request_proto_rawDescOnce.Do(func() {
+		file_request_proto_rawDescData = protoimpl.X.CompressGZIP(file_request_proto_rawDescData)
+	})
+	return file_request_proto_rawDescData
+}
+
+var file_request_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_request_proto_msgTypes = make([]protoimpl.MessageInfo, 43)
+var file_request_proto_goTypes = []interface{}{
+	(Compression)(0),          // 0: Compression
+	(LogLevel)(0),             // 1: LogLevel
+	(ReplicaMode)(0),          // 2: ReplicaMode
+	(*GetPhaseReq)(nil),       // 3: GetPhaseReq
+	(*GetPhaseRsp)(nil),       // 4: GetPhaseRsp
+	(*StoredValue)(nil),       // 5: StoredValue
+	(*ChunkManifest)(nil),     // 6: ChunkManifest
+	(*SetPhaseReq)(nil),       // 7: SetPhaseReq
+	(*SetPhaseRsp)(nil),       // 8: SetPhaseRsp
+	(*TimeStamp)(nil),         // 9: TimeStamp
+	(*CodedFragment)(nil),     // 10: CodedFragment
+	(*CodedQueryReq)(nil),     // 11: CodedQueryReq
+	(*CodedQueryRsp)(nil),     // 12: CodedQueryRsp
+	(*CodedPreWriteReq)(nil),  // 13: CodedPreWriteReq
+	(*CodedPreWriteRsp)(nil),  // 14: CodedPreWriteRsp
+	(*CodedFinalizeReq)(nil),  // 15: CodedFinalizeReq
+	(*CodedFinalizeRsp)(nil),  // 16: CodedFinalizeRsp
+	(*RegisterClientReq)(nil), // 17: RegisterClientReq
+	(*RegisterClientRsp)(nil), // 18: RegisterClientRsp
+	(*ScanReq)(nil),           // 19: ScanReq
+	(*ScanRsp)(nil),           // 20: ScanRsp
+	(*KeyValue)(nil),          // 21: KeyValue
+	(*WatchReq)(nil),          // 22: WatchReq
+	(*PaxosState)(nil),        // 23: PaxosState
+	(*LogRecord)(nil),         // 24: LogRecord
+	(*PaxosPrepareReq)(nil),   // 25: PaxosPrepareReq
+	(*PaxosPrepareRsp)(nil),   // 26: PaxosPrepareRsp
+	(*PaxosAcceptReq)(nil),    // 27: PaxosAcceptReq
+	(*PaxosAcceptRsp)(nil),    // 28: PaxosAcceptRsp
+	(*DumpValueReq)(nil),      // 29: DumpValueReq
+	(*DumpValueRsp)(nil),      // 30: DumpValueRsp
+	(*CountKeysReq)(nil),      // 31: CountKeysReq
+	(*CountKeysRsp)(nil),      // 32: CountKeysRsp
+	(*ListKeysReq)(nil),       // 33: ListKeysReq
+	(*ListKeysRsp)(nil),       // 34: ListKeysRsp
+	(*CompactReq)(nil),        // 35: CompactReq
+	(*CompactRsp)(nil),        // 36: CompactRsp
+	(*SetLogLevelReq)(nil),    // 37: SetLogLevelReq
+	(*SetLogLevelRsp)(nil),    // 38: SetLogLevelRsp
+	(*SetModeReq)(nil),        // 39: SetModeReq
+	(*SetModeRsp)(nil),        // 40: SetModeRsp
+	(*GetInfoReq)(nil),        // 41: GetInfoReq
+	(*GetInfoRsp)(nil),        // 42: GetInfoRsp
+	(*RepairValueReq)(nil),    // 43: RepairValueReq
+	(*RepairValueRsp)(nil),    // 44: RepairValueRsp
+	(*BuildInfo)(nil),         // 45: BuildInfo
+}
+var file_request_proto_depIdxs = []int32{
+	5,  // 0: GetPhaseRsp.value:type_name -> StoredValue
+	9,  // 1: StoredValue.ts:type_name -> TimeStamp
+	6,  // 2: StoredValue.manifest:type_name -> ChunkManifest
+	0,  // 3: StoredValue.compression:type_name -> Compression
+	5,  // 4: SetPhaseReq.value:type_name -> StoredValue
+	5,  // 5: CodedFragment.meta:type_name -> StoredValue
+	9,  // 6: CodedQueryRsp.ts:type_name -> TimeStamp
+	5,  // 7: CodedQueryRsp.meta:type_name -> StoredValue
+	9,  // 8: CodedPreWriteReq.ts:type_name -> TimeStamp
+	10, // 9: CodedPreWriteReq.fragment:type_name -> CodedFragment
+	9,  // 10: CodedFinalizeReq.ts:type_name -> TimeStamp
+	10, // 11: CodedFinalizeRsp.fragment:type_name -> CodedFragment
+	21, // 12: ScanRsp.entries:type_name -> KeyValue
+	5,  // 13: KeyValue.value:type_name -> StoredValue
+	9,  // 14: WatchReq.fromTs:type_name -> TimeStamp
+	9,  // 15: PaxosState.version:type_name -> TimeStamp
+	9,  // 16: PaxosState.promised:type_name -> TimeStamp
+	9,  // 17: PaxosState.acceptedBallot:type_name -> TimeStamp
+	5,  // 18: PaxosState.acceptedValue:type_name -> StoredValue
+	9,  // 19: PaxosState.floor:type_name -> TimeStamp
+	5,  // 20: LogRecord.value:type_name -> StoredValue
+	23, // 21: LogRecord.paxos:type_name -> PaxosState
+	9,  // 22: PaxosPrepareReq.version:type_name -> TimeStamp
+	9,  // 23: PaxosPrepareReq.ballot:type_name -> TimeStamp
+	9,  // 24: PaxosPrepareRsp.promised:type_name -> TimeStamp
+	9,  // 25: PaxosPrepareRsp.acceptedBallot:type_name -> TimeStamp
+	5,  // 26: PaxosPrepareRsp.acceptedValue:type_name -> StoredValue
+	9,  // 27: PaxosAcceptReq.version:type_name -> TimeStamp
+	9,  // 28: PaxosAcceptReq.ballot:type_name -> TimeStamp
+	5,  // 29: PaxosAcceptReq.value:type_name -> StoredValue
+	9,  // 30: PaxosAcceptRsp.promised:type_name -> TimeStamp
+	5,  // 31: DumpValueRsp.value:type_name -> StoredValue
+	1,  // 32: SetLogLevelReq.level:type_name -> LogLevel
+	1,  // 33: SetLogLevelRsp.previous:type_name -> LogLevel
+	2,  // 34: SetModeReq.mode:type_name -> ReplicaMode
+	2,  // 35: SetModeRsp.previous:type_name -> ReplicaMode
+	45, // 36: GetInfoRsp.build:type_name -> BuildInfo
+	2,  // 37: GetInfoRsp.mode:type_name -> ReplicaMode
+	1,  // 38: GetInfoRsp.logLevel:type_name -> LogLevel
+	5,  // 39: RepairValueReq.value:type_name -> StoredValue
+	3,  // 40: SharedRegisters.GetPhase:input_type -> GetPhaseReq
+	7,  // 41: SharedRegisters.SetPhase:input_type -> SetPhaseReq
+	11, // 42: SharedRegisters.CodedQuery:input_type -> CodedQueryReq
+	13, // 43: SharedRegisters.CodedPreWrite:input_type -> CodedPreWriteReq
+	15, // 44: SharedRegisters.CodedFinalize:input_type -> CodedFinalizeReq
+	17, // 45: SharedRegisters.RegisterClient:input_type -> RegisterClientReq
+	19, // 46: SharedRegisters.Scan:input_type -> ScanReq
+	22, // 47: SharedRegisters.Watch:input_type -> WatchReq
+	25, // 48: SharedRegisters.PaxosPrepare:input_type -> PaxosPrepareReq
+	27, // 49: SharedRegisters.PaxosAccept:input_type -> PaxosAcceptReq
+	29, // 50: Admin.DumpValue:input_type -> DumpValueReq
+	31, // 51: Admin.CountKeys:input_type -> CountKeysReq
+	33, // 52: Admin.ListKeys:input_type -> ListKeysReq
+	35, // 53: Admin.Compact:input_type -> CompactReq
+	37, // 54: Admin.SetLogLevel:input_type -> SetLogLevelReq
+	39, // 55: Admin.SetMode:input_type -> SetModeReq
+	41, // 56: Admin.GetInfo:input_type -> GetInfoReq
+	43, // 57: Admin.RepairValue:input_type -> RepairValueReq
+	4,  // 58: SharedRegisters.GetPhase:output_type -> GetPhaseRsp
+	8,  // 59: SharedRegisters.SetPhase:output_type -> SetPhaseRsp
+	12, // 60: SharedRegisters.CodedQuery:output_type -> CodedQueryRsp
+	14, // 61: SharedRegisters.CodedPreWrite:output_type -> CodedPreWriteRsp
+	16, // 62: SharedRegisters.CodedFinalize:output_type -> CodedFinalizeRsp
+	18, // 63: SharedRegisters.RegisterClient:output_type -> RegisterClientRsp
+	20, // 64: SharedRegisters.Scan:output_type -> ScanRsp
+	21, // 65: SharedRegisters.Watch:output_type -> KeyValue
+	26, // 66: SharedRegisters.PaxosPrepare:output_type -> PaxosPrepareRsp
+	28, // 67: SharedRegisters.PaxosAccept:output_type -> PaxosAcceptRsp
+	30, // 68: Admin.DumpValue:output_type -> DumpValueRsp
+	32, // 69: Admin.CountKeys:output_type -> CountKeysRsp
+	34, // 70: Admin.ListKeys:output_type -> ListKeysRsp
+	36, // 71: Admin.Compact:output_type -> CompactRsp
+	38, // 72: Admin.SetLogLevel:output_type -> SetLogLevelRsp
+	40, // 73: Admin.SetMode:output_type -> SetModeRsp
+	42, // 74: Admin.GetInfo:output_type -> GetInfoRsp
+	44, // 75: Admin.RepairValue:output_type -> RepairValueRsp
+	58, // [58:76] is the sub-list for method output_type
+	40, // [40:58] is the sub-list for method input_type
+	40, // [40:40] is the sub-list for extension type_name
+	40, // [40:40] is the sub-list for extension extendee
+	0,  // [0:40] is the sub-list for field type_name
+}
+
+func init() { file_request_proto_init() }
+func file_request_proto_init() {
+	if File_request_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_request_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPhaseReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPhaseRsp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StoredValue); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChunkManifest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetPhaseReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetPhaseRsp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TimeStamp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CodedFragment); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CodedQueryReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CodedQueryRsp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CodedPreWriteReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CodedPreWriteRsp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CodedFinalizeReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CodedFinalizeRsp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegisterClientReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegisterClientRsp); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -441,8 +3375,8 @@ func file_request_proto_init() {
 				return nil
 			}
 		}
-		file_request_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetPhaseRsp); i {
+		file_request_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScanReq); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -453,8 +3387,308 @@ func file_request_proto_init() {
 				return nil
 			}
 		}
-		file_request_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TimeStamp); i {
+		file_request_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScanRsp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KeyValue); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PaxosState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogRecord); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PaxosPrepareReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PaxosPrepareRsp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PaxosAcceptReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PaxosAcceptRsp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DumpValueReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DumpValueRsp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CountKeysReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CountKeysRsp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListKeysReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListKeysRsp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompactReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompactRsp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetLogLevelReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetLogLevelRsp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetModeReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetModeRsp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetInfoReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetInfoRsp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RepairValueReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RepairValueRsp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_request_proto_msgTypes[42].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BuildInfo); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -471,13 +3705,14 @@ func file_request_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_request_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   6,
+			NumEnums:      3,
+			NumMessages:   43,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_request_proto_goTypes,
 		DependencyIndexes: file_request_proto_depIdxs,
+		EnumInfos:         file_request_proto_enumTypes,
 		MessageInfos:      file_request_proto_msgTypes,
 	}.Build()
 	File_request_proto = out.File
@@ -24,6 +24,19 @@ const _ = grpc.SupportPackageIsVersion7
 type SharedRegistersClient interface {
 	GetPhase(ctx context.Context, in *GetPhaseReq, opts ...grpc.CallOption) (*GetPhaseRsp, error)
 	SetPhase(ctx context.Context, in *SetPhaseReq, opts ...grpc.CallOption) (*SetPhaseRsp, error)
+	// erasure-coded registers (CAS algorithm), stored apart from the replicated registers above
+	CodedQuery(ctx context.Context, in *CodedQueryReq, opts ...grpc.CallOption) (*CodedQueryRsp, error)
+	CodedPreWrite(ctx context.Context, in *CodedPreWriteReq, opts ...grpc.CallOption) (*CodedPreWriteRsp, error)
+	CodedFinalize(ctx context.Context, in *CodedFinalizeReq, opts ...grpc.CallOption) (*CodedFinalizeRsp, error)
+	// lease based registration making sure no two live clients share a clientID
+	RegisterClient(ctx context.Context, in *RegisterClientReq, opts ...grpc.CallOption) (*RegisterClientRsp, error)
+	// ordered listing of the replicated registers
+	Scan(ctx context.Context, in *ScanReq, opts ...grpc.CallOption) (*ScanRsp, error)
+	// current values newer than fromTs, then every newer value stored while the stream is open
+	Watch(ctx context.Context, in *WatchReq, opts ...grpc.CallOption) (SharedRegisters_WatchClient, error)
+	// Paxos acceptor of compare-and-swap, one single-decree instance per key and version replaced
+	PaxosPrepare(ctx context.Context, in *PaxosPrepareReq, opts ...grpc.CallOption) (*PaxosPrepareRsp, error)
+	PaxosAccept(ctx context.Context, in *PaxosAcceptReq, opts ...grpc.CallOption) (*PaxosAcceptRsp, error)
 }
 
 type sharedRegistersClient struct {
@@ -52,12 +65,120 @@ func (c *sharedRegistersClient) SetPhase(ctx context.Context, in *SetPhaseReq, o
 	return out, nil
 }
 
+func (c *sharedRegistersClient) CodedQuery(ctx context.Context, in *CodedQueryReq, opts ...grpc.CallOption) (*CodedQueryRsp, error) {
+	out := new(CodedQueryRsp)
+	err := c.cc.Invoke(ctx, "/SharedRegisters/CodedQuery", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sharedRegistersClient) CodedPreWrite(ctx context.Context, in *CodedPreWriteReq, opts ...grpc.CallOption) (*CodedPreWriteRsp, error) {
+	out := new(CodedPreWriteRsp)
+	err := c.cc.Invoke(ctx, "/SharedRegisters/CodedPreWrite", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sharedRegistersClient) CodedFinalize(ctx context.Context, in *CodedFinalizeReq, opts ...grpc.CallOption) (*CodedFinalizeRsp, error) {
+	out := new(CodedFinalizeRsp)
+	err := c.cc.Invoke(ctx, "/SharedRegisters/CodedFinalize", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sharedRegistersClient) RegisterClient(ctx context.Context, in *RegisterClientReq, opts ...grpc.CallOption) (*RegisterClientRsp, error) {
+	out := new(RegisterClientRsp)
+	err := c.cc.Invoke(ctx, "/SharedRegisters/RegisterClient", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sharedRegistersClient) Scan(ctx context.Context, in *ScanReq, opts ...grpc.CallOption) (*ScanRsp, error) {
+	out := new(ScanRsp)
+	err := c.cc.Invoke(ctx, "/SharedRegisters/Scan", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sharedRegistersClient) Watch(ctx context.Context, in *WatchReq, opts ...grpc.CallOption) (SharedRegisters_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SharedRegisters_ServiceDesc.Streams[0], "/SharedRegisters/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sharedRegistersWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SharedRegisters_WatchClient interface {
+	Recv() (*KeyValue, error)
+	grpc.ClientStream
+}
+
+type sharedRegistersWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *sharedRegistersWatchClient) Recv() (*KeyValue, error) {
+	m := new(KeyValue)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sharedRegistersClient) PaxosPrepare(ctx context.Context, in *PaxosPrepareReq, opts ...grpc.CallOption) (*PaxosPrepareRsp, error) {
+	out := new(PaxosPrepareRsp)
+	err := c.cc.Invoke(ctx, "/SharedRegisters/PaxosPrepare", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sharedRegistersClient) PaxosAccept(ctx context.Context, in *PaxosAcceptReq, opts ...grpc.CallOption) (*PaxosAcceptRsp, error) {
+	out := new(PaxosAcceptRsp)
+	err := c.cc.Invoke(ctx, "/SharedRegisters/PaxosAccept", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SharedRegistersServer is the server API for SharedRegisters service.
 // All implementations must embed UnimplementedSharedRegistersServer
 // for forward compatibility
 type SharedRegistersServer interface {
 	GetPhase(context.Context, *GetPhaseReq) (*GetPhaseRsp, error)
 	SetPhase(context.Context, *SetPhaseReq) (*SetPhaseRsp, error)
+	// erasure-coded registers (CAS algorithm), stored apart from the replicated registers above
+	CodedQuery(context.Context, *CodedQueryReq) (*CodedQueryRsp, error)
+	CodedPreWrite(context.Context, *CodedPreWriteReq) (*CodedPreWriteRsp, error)
+	CodedFinalize(context.Context, *CodedFinalizeReq) (*CodedFinalizeRsp, error)
+	// lease based registration making sure no two live clients share a clientID
+	RegisterClient(context.Context, *RegisterClientReq) (*RegisterClientRsp, error)
+	// ordered listing of the replicated registers
+	Scan(context.Context, *ScanReq) (*ScanRsp, error)
+	// current values newer than fromTs, then every newer value stored while the stream is open
+	Watch(*WatchReq, SharedRegisters_WatchServer) error
+	// Paxos acceptor of compare-and-swap, one single-decree instance per key and version replaced
+	PaxosPrepare(context.Context, *PaxosPrepareReq) (*PaxosPrepareRsp, error)
+	PaxosAccept(context.Context, *PaxosAcceptReq) (*PaxosAcceptRsp, error)
 	mustEmbedUnimplementedSharedRegistersServer()
 }
 
@@ -71,6 +192,30 @@ func (UnimplementedSharedRegistersServer) GetPhase(context.Context, *GetPhaseReq
 func (UnimplementedSharedRegistersServer) SetPhase(context.Context, *SetPhaseReq) (*SetPhaseRsp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetPhase not implemented")
 }
+func (UnimplementedSharedRegistersServer) CodedQuery(context.Context, *CodedQueryReq) (*CodedQueryRsp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CodedQuery not implemented")
+}
+func (UnimplementedSharedRegistersServer) CodedPreWrite(context.Context, *CodedPreWriteReq) (*CodedPreWriteRsp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CodedPreWrite not implemented")
+}
+func (UnimplementedSharedRegistersServer) CodedFinalize(context.Context, *CodedFinalizeReq) (*CodedFinalizeRsp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CodedFinalize not implemented")
+}
+func (UnimplementedSharedRegistersServer) RegisterClient(context.Context, *RegisterClientReq) (*RegisterClientRsp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterClient not implemented")
+}
+func (UnimplementedSharedRegistersServer) Scan(context.Context, *ScanReq) (*ScanRsp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Scan not implemented")
+}
+func (UnimplementedSharedRegistersServer) Watch(*WatchReq, SharedRegisters_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedSharedRegistersServer) PaxosPrepare(context.Context, *PaxosPrepareReq) (*PaxosPrepareRsp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PaxosPrepare not implemented")
+}
+func (UnimplementedSharedRegistersServer) PaxosAccept(context.Context, *PaxosAcceptReq) (*PaxosAcceptRsp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PaxosAccept not implemented")
+}
 func (UnimplementedSharedRegistersServer) mustEmbedUnimplementedSharedRegistersServer() {}
 
 // UnsafeSharedRegistersServer may be embedded to opt out of forward compatibility for this service.
@@ -120,6 +265,153 @@ func _SharedRegisters_SetPhase_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SharedRegisters_CodedQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CodedQueryReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SharedRegistersServer).CodedQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SharedRegisters/CodedQuery",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SharedRegistersServer).CodedQuery(ctx, req.(*CodedQueryReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SharedRegisters_CodedPreWrite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CodedPreWriteReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SharedRegistersServer).CodedPreWrite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SharedRegisters/CodedPreWrite",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SharedRegistersServer).CodedPreWrite(ctx, req.(*CodedPreWriteReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SharedRegisters_CodedFinalize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CodedFinalizeReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SharedRegistersServer).CodedFinalize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SharedRegisters/CodedFinalize",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SharedRegistersServer).CodedFinalize(ctx, req.(*CodedFinalizeReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SharedRegisters_RegisterClient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterClientReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SharedRegistersServer).RegisterClient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SharedRegisters/RegisterClient",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SharedRegistersServer).RegisterClient(ctx, req.(*RegisterClientReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SharedRegisters_Scan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScanReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SharedRegistersServer).Scan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SharedRegisters/Scan",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SharedRegistersServer).Scan(ctx, req.(*ScanReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SharedRegisters_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SharedRegistersServer).Watch(m, &sharedRegistersWatchServer{stream})
+}
+
+type SharedRegisters_WatchServer interface {
+	Send(*KeyValue) error
+	grpc.ServerStream
+}
+
+type sharedRegistersWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *sharedRegistersWatchServer) Send(m *KeyValue) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SharedRegisters_PaxosPrepare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PaxosPrepareReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SharedRegistersServer).PaxosPrepare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SharedRegisters/PaxosPrepare",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SharedRegistersServer).PaxosPrepare(ctx, req.(*PaxosPrepareReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SharedRegisters_PaxosAccept_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PaxosAcceptReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SharedRegistersServer).PaxosAccept(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SharedRegisters/PaxosAccept",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SharedRegistersServer).PaxosAccept(ctx, req.(*PaxosAcceptReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SharedRegisters_ServiceDesc is the grpc.ServiceDesc for SharedRegisters service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -135,6 +427,386 @@ var SharedRegisters_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SetPhase",
 			Handler:    _SharedRegisters_SetPhase_Handler,
 		},
+		{
+			MethodName: "CodedQuery",
+			Handler:    _SharedRegisters_CodedQuery_Handler,
+		},
+		{
+			MethodName: "CodedPreWrite",
+			Handler:    _SharedRegisters_CodedPreWrite_Handler,
+		},
+		{
+			MethodName: "CodedFinalize",
+			Handler:    _SharedRegisters_CodedFinalize_Handler,
+		},
+		{
+			MethodName: "RegisterClient",
+			Handler:    _SharedRegisters_RegisterClient_Handler,
+		},
+		{
+			MethodName: "Scan",
+			Handler:    _SharedRegisters_Scan_Handler,
+		},
+		{
+			MethodName: "PaxosPrepare",
+			Handler:    _SharedRegisters_PaxosPrepare_Handler,
+		},
+		{
+			MethodName: "PaxosAccept",
+			Handler:    _SharedRegisters_PaxosAccept_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _SharedRegisters_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "request.proto",
+}
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AdminClient interface {
+	// the <value, ts> the replica stores for a key as is, including tombstones and internal keys
+	DumpValue(ctx context.Context, in *DumpValueReq, opts ...grpc.CallOption) (*DumpValueRsp, error)
+	CountKeys(ctx context.Context, in *CountKeysReq, opts ...grpc.CallOption) (*CountKeysRsp, error)
+	ListKeys(ctx context.Context, in *ListKeysReq, opts ...grpc.CallOption) (*ListKeysRsp, error)
+	// write a snapshot of the registers and start a new log right away
+	Compact(ctx context.Context, in *CompactReq, opts ...grpc.CallOption) (*CompactRsp, error)
+	SetLogLevel(ctx context.Context, in *SetLogLevelReq, opts ...grpc.CallOption) (*SetLogLevelRsp, error)
+	SetMode(ctx context.Context, in *SetModeReq, opts ...grpc.CallOption) (*SetModeRsp, error)
+	// build info, uptime, mode and log level of the replica
+	GetInfo(ctx context.Context, in *GetInfoReq, opts ...grpc.CallOption) (*GetInfoRsp, error)
+	// store a value like a set phase writing it back, without the ACL checks of its key and client ID
+	RepairValue(ctx context.Context, in *RepairValueReq, opts ...grpc.CallOption) (*RepairValueRsp, error)
+}
+
+type adminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminClient(cc grpc.ClientConnInterface) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) DumpValue(ctx context.Context, in *DumpValueReq, opts ...grpc.CallOption) (*DumpValueRsp, error) {
+	out := new(DumpValueRsp)
+	err := c.cc.Invoke(ctx, "/Admin/DumpValue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) CountKeys(ctx context.Context, in *CountKeysReq, opts ...grpc.CallOption) (*CountKeysRsp, error) {
+	out := new(CountKeysRsp)
+	err := c.cc.Invoke(ctx, "/Admin/CountKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ListKeys(ctx context.Context, in *ListKeysReq, opts ...grpc.CallOption) (*ListKeysRsp, error) {
+	out := new(ListKeysRsp)
+	err := c.cc.Invoke(ctx, "/Admin/ListKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Compact(ctx context.Context, in *CompactReq, opts ...grpc.CallOption) (*CompactRsp, error) {
+	out := new(CompactRsp)
+	err := c.cc.Invoke(ctx, "/Admin/Compact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetLogLevel(ctx context.Context, in *SetLogLevelReq, opts ...grpc.CallOption) (*SetLogLevelRsp, error) {
+	out := new(SetLogLevelRsp)
+	err := c.cc.Invoke(ctx, "/Admin/SetLogLevel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetMode(ctx context.Context, in *SetModeReq, opts ...grpc.CallOption) (*SetModeRsp, error) {
+	out := new(SetModeRsp)
+	err := c.cc.Invoke(ctx, "/Admin/SetMode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetInfo(ctx context.Context, in *GetInfoReq, opts ...grpc.CallOption) (*GetInfoRsp, error) {
+	out := new(GetInfoRsp)
+	err := c.cc.Invoke(ctx, "/Admin/GetInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) RepairValue(ctx context.Context, in *RepairValueReq, opts ...grpc.CallOption) (*RepairValueRsp, error) {
+	out := new(RepairValueRsp)
+	err := c.cc.Invoke(ctx, "/Admin/RepairValue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServer is the server API for Admin service.
+// All implementations must embed UnimplementedAdminServer
+// for forward compatibility
+type AdminServer interface {
+	// the <value, ts> the replica stores for a key as is, including tombstones and internal keys
+	DumpValue(context.Context, *DumpValueReq) (*DumpValueRsp, error)
+	CountKeys(context.Context, *CountKeysReq) (*CountKeysRsp, error)
+	ListKeys(context.Context, *ListKeysReq) (*ListKeysRsp, error)
+	// write a snapshot of the registers and start a new log right away
+	Compact(context.Context, *CompactReq) (*CompactRsp, error)
+	SetLogLevel(context.Context, *SetLogLevelReq) (*SetLogLevelRsp, error)
+	SetMode(context.Context, *SetModeReq) (*SetModeRsp, error)
+	// build info, uptime, mode and log level of the replica
+	GetInfo(context.Context, *GetInfoReq) (*GetInfoRsp, error)
+	// store a value like a set phase writing it back, without the ACL checks of its key and client ID
+	RepairValue(context.Context, *RepairValueReq) (*RepairValueRsp, error)
+	mustEmbedUnimplementedAdminServer()
+}
+
+// UnimplementedAdminServer must be embedded to have forward compatible implementations.
+type UnimplementedAdminServer struct {
+}
+
+func (UnimplementedAdminServer) DumpValue(context.Context, *DumpValueReq) (*DumpValueRsp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DumpValue not implemented")
+}
+func (UnimplementedAdminServer) CountKeys(context.Context, *CountKeysReq) (*CountKeysRsp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CountKeys not implemented")
+}
+func (UnimplementedAdminServer) ListKeys(context.Context, *ListKeysReq) (*ListKeysRsp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListKeys not implemented")
+}
+func (UnimplementedAdminServer) Compact(context.Context, *CompactReq) (*CompactRsp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Compact not implemented")
+}
+func (UnimplementedAdminServer) SetLogLevel(context.Context, *SetLogLevelReq) (*SetLogLevelRsp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
+}
+func (UnimplementedAdminServer) SetMode(context.Context, *SetModeReq) (*SetModeRsp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetMode not implemented")
+}
+func (UnimplementedAdminServer) GetInfo(context.Context, *GetInfoReq) (*GetInfoRsp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInfo not implemented")
+}
+func (UnimplementedAdminServer) RepairValue(context.Context, *RepairValueReq) (*RepairValueRsp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RepairValue not implemented")
+}
+func (UnimplementedAdminServer) mustEmbedUnimplementedAdminServer() {}
+
+// UnsafeAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServer will
+// result in compilation errors.
+type UnsafeAdminServer interface {
+	mustEmbedUnimplementedAdminServer()
+}
+
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	s.RegisterService(&Admin_ServiceDesc, srv)
+}
+
+func _Admin_DumpValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DumpValueReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).DumpValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/DumpValue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).DumpValue(ctx, req.(*DumpValueReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_CountKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountKeysReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).CountKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/CountKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).CountKeys(ctx, req.(*CountKeysReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ListKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListKeysReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/ListKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListKeys(ctx, req.(*ListKeysReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Compact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompactReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Compact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/Compact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Compact(ctx, req.(*CompactReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLogLevelReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/SetLogLevel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetLogLevel(ctx, req.(*SetLogLevelReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetMode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetModeReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetMode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/SetMode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetMode(ctx, req.(*SetModeReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInfoReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/GetInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetInfo(ctx, req.(*GetInfoReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_RepairValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RepairValueReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).RepairValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/RepairValue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).RepairValue(ctx, req.(*RepairValueReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Admin_ServiceDesc is the grpc.ServiceDesc for Admin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Admin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DumpValue",
+			Handler:    _Admin_DumpValue_Handler,
+		},
+		{
+			MethodName: "CountKeys",
+			Handler:    _Admin_CountKeys_Handler,
+		},
+		{
+			MethodName: "ListKeys",
+			Handler:    _Admin_ListKeys_Handler,
+		},
+		{
+			MethodName: "Compact",
+			Handler:    _Admin_Compact_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _Admin_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "SetMode",
+			Handler:    _Admin_SetMode_Handler,
+		},
+		{
+			MethodName: "GetInfo",
+			Handler:    _Admin_GetInfo_Handler,
+		},
+		{
+			MethodName: "RepairValue",
+			Handler:    _Admin_RepairValue_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "request.proto",
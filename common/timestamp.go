@@ -5,29 +5,51 @@ import (
 )
 
 // FindLargestTimeStamp
-// find the largest timeStamp from the inputs, sort on request number and if there is a tie, choose
-// the one with largest ClientID
+// find the largest timeStamp from the inputs, sort on request number, then on the hybrid logical
+// clock if there is a tie, and finally choose the one with largest ClientID. The first of several
+// equal timestamps is returned.
 func FindLargestTimeStamp(stamps ...*proto.TimeStamp) *proto.TimeStamp {
-	maxReqNum := uint64(0)
 	var maxTs *proto.TimeStamp
-	// find the largest request number
 	for _, s := range stamps {
 		if s == nil {
 			continue
 		}
-		if s.GetRequestNumber() > maxReqNum {
-			maxReqNum = s.GetRequestNumber()
+		if maxTs == nil || CompareTimeStamps(s, maxTs) > 0 {
 			maxTs = s
 		}
 	}
-	// choose the one with largest clientID if there is an tie in request number
-	for _, s := range stamps {
-		if s == nil {
-			continue
-		}
-		if s.GetRequestNumber() == maxReqNum && s.ClientID > maxTs.GetClientID() {
-			maxTs = s
+	return maxTs
+}
+
+// CompareTimeStamps
+// returns -1, 0 or 1 when a is smaller than, equal to or larger than b, using the same order as
+// FindLargestTimeStamp, nil is smaller than any timestamp
+func CompareTimeStamps(a, b *proto.TimeStamp) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	case a.GetRequestNumber() != b.GetRequestNumber():
+		return compareUint(a.GetRequestNumber(), b.GetRequestNumber())
+	case a.GetWallTime() != b.GetWallTime():
+		return compareUint(a.GetWallTime(), b.GetWallTime())
+	case a.GetLogical() != b.GetLogical():
+		return compareUint(uint64(a.GetLogical()), uint64(b.GetLogical()))
+	case a.GetClientID() != b.GetClientID():
+		if a.GetClientID() < b.GetClientID() {
+			return -1
 		}
+		return 1
 	}
-	return maxTs
+	return 0
+}
+
+func compareUint(a, b uint64) int {
+	if a < b {
+		return -1
+	}
+	return 1
 }
@@ -0,0 +1,168 @@
+package common
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// tlsReloadInterval is how often at most the certificate files are checked for changes
+const tlsReloadInterval = time.Second
+
+// TLSFiles names the PEM files one side of a connection uses
+type TLSFiles struct {
+	CertFile string // own certificate chain, optional for clients that don't authenticate
+	KeyFile  string // private key of CertFile
+	CAFile   string // CA bundle the peer's certificate is verified against
+}
+
+// certReloader
+// keeps the certificate and CA pool of TLSFiles and loads them again whenever one of the files
+// changes, so certificates can be rotated without restarting. The files are checked during
+// handshakes; a reload that fails, e.g. because the key was replaced before the certificate, keeps
+// the previous certificates until the files are consistent again.
+type certReloader struct {
+	files TLSFiles
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	modTimes  [3]time.Time
+	lastCheck time.Time
+}
+
+func newCertReloader(files TLSFiles) (*certReloader, error) {
+	if (files.CertFile == "") != (files.KeyFile == "") {
+		return nil, errors.New("a TLS certificate needs both a certificate and a key file")
+	}
+	r := &certReloader{files: files}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) load() error {
+	var modTimes [3]time.Time
+	for i, name := range []string{r.files.CertFile, r.files.KeyFile, r.files.CAFile} {
+		if name == "" {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	var cert *tls.Certificate
+	if r.files.CertFile != "" {
+		c, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+		if err != nil {
+			return err
+		}
+		cert = &c
+	}
+	var pool *x509.CertPool
+	if r.files.CAFile != "" {
+		pem, err := os.ReadFile(r.files.CAFile)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("no certificates found in " + r.files.CAFile)
+		}
+	}
+	r.cert, r.pool, r.modTimes = cert, pool, modTimes
+	return nil
+}
+
+// current returns the certificate and CA pool, reloading them first if a file changed
+func (r *certReloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.lastCheck) >= tlsReloadInterval {
+		r.lastCheck = time.Now()
+		for i, name := range []string{r.files.CertFile, r.files.KeyFile, r.files.CAFile} {
+			if info, err := os.Stat(name); name != "" && err == nil && !info.ModTime().Equal(r.modTimes[i]) {
+				if err := r.load(); err != nil {
+					log.Printf("reloading TLS certificates: %v", err)
+				} else {
+					log.Printf("reloaded TLS certificates")
+				}
+				break
+			}
+		}
+	}
+	return r.cert, r.pool
+}
+
+// ServerTLSConfig
+// TLS configuration of a replica serving files.CertFile. If files.CAFile is set, clients have to
+// present a certificate signed by one of its CAs (mutual TLS), otherwise they aren't asked for one.
+// Both are reloaded when their files change, new connections use the new ones.
+func ServerTLSConfig(files TLSFiles) (*tls.Config, error) {
+	if files.CertFile == "" {
+		return nil, errors.New("a TLS server needs a certificate")
+	}
+	r, err := newCertReloader(files)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := r.current()
+			config := &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{*cert}}
+			if pool != nil {
+				config.ClientCAs, config.ClientAuth = pool, tls.RequireAndVerifyClientCert
+			}
+			return config, nil
+		},
+	}, nil
+}
+
+// ClientTLSConfig
+// TLS configuration of a client verifying the replicas against files.CAFile, or the system roots
+// if it is empty, and presenting files.CertFile to replicas that require client certificates.
+// The files are reloaded when they change.
+func ClientTLSConfig(files TLSFiles) (*tls.Config, error) {
+	r, err := newCertReloader(files)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			if cert, _ := r.current(); cert != nil {
+				return cert, nil
+			}
+			return &tls.Certificate{}, nil // no certificate, the replica rejects the handshake if it needs one
+		},
+	}
+	if files.CAFile != "" {
+		// RootCAs can't change after the handshake starts, so verify against the current pool ourselves
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = func(cs tls.ConnectionState) error {
+			_, pool := r.current()
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("replica presented no certificate")
+			}
+			intermediates := x509.NewCertPool()
+			for _, cert := range cs.PeerCertificates[1:] {
+				intermediates.AddCert(cert)
+			}
+			_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+				DNSName:       cs.ServerName,
+				Roots:         pool,
+				Intermediates: intermediates,
+			})
+			return err
+		}
+	}
+	return config, nil
+}
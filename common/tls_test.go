@@ -0,0 +1,173 @@
+package common
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue writes a certificate for name signed by the CA and its key to dir, returning their paths
+func (ca *testCA) issue(t *testing.T, dir, name string) (string, string) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+	certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	writeFile(t, certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	writeFile(t, keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+	return certFile, keyFile
+}
+
+// writeFile writes the file with a modification time that differs from the previous one
+func writeFile(t *testing.T, name string, data []byte) {
+	modTime := time.Now()
+	if info, err := os.Stat(name); err == nil && !modTime.After(info.ModTime()) {
+		modTime = info.ModTime().Add(time.Second)
+	}
+	if err := os.WriteFile(name, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(name, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// handshake connects a client to a server over a local connection and returns the server's
+// certificate as seen by the client, or the handshake error
+func handshake(server, client *tls.Config) (*x509.Certificate, error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	defer lis.Close()
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+		serverErr <- tls.Server(conn, server).Handshake()
+	}()
+	conn, err := net.Dial("tcp", lis.Addr().String())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	config := client.Clone()
+	config.ServerName = "replica"
+	c := tls.Client(conn, config)
+	err = c.Handshake()
+	if err != nil {
+		return nil, err
+	}
+	if err := <-serverErr; err != nil {
+		return nil, err
+	}
+	return c.ConnectionState().PeerCertificates[0], nil
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "ca")
+	caFile := filepath.Join(dir, "ca.pem")
+	writeFile(t, caFile, ca.pem)
+	serverCert, serverKey := ca.issue(t, dir, "replica")
+	clientCert, clientKey := ca.issue(t, dir, "client")
+
+	server, err := ServerTLSConfig(TLSFiles{CertFile: serverCert, KeyFile: serverKey, CAFile: caFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := ClientTLSConfig(TLSFiles{CertFile: clientCert, KeyFile: clientKey, CAFile: caFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert, err := handshake(server, client); err != nil || cert.Subject.CommonName != "replica" {
+		t.Fatalf("mutual TLS handshake failed: %v", err)
+	}
+
+	anonymous, _ := ClientTLSConfig(TLSFiles{CAFile: caFile})
+	if _, err := handshake(server, anonymous); err == nil {
+		t.Error("client without certificate accepted")
+	}
+	other := newTestCA(t, "other")
+	otherFile := filepath.Join(dir, "other.pem")
+	writeFile(t, otherFile, other.pem)
+	distrustful, _ := ClientTLSConfig(TLSFiles{CertFile: clientCert, KeyFile: clientKey, CAFile: otherFile})
+	if _, err := handshake(server, distrustful); err == nil {
+		t.Error("replica certificate from an unknown CA accepted")
+	}
+}
+
+func TestCertificateReload(t *testing.T) {
+	dir := t.TempDir()
+	oldCA, newCA := newTestCA(t, "old"), newTestCA(t, "new")
+	caFile := filepath.Join(dir, "ca.pem")
+	writeFile(t, caFile, oldCA.pem)
+	serverCert, serverKey := oldCA.issue(t, dir, "replica")
+	server, _ := ServerTLSConfig(TLSFiles{CertFile: serverCert, KeyFile: serverKey})
+	client, _ := ClientTLSConfig(TLSFiles{CAFile: caFile})
+	if _, err := handshake(server, client); err != nil {
+		t.Fatal(err)
+	}
+
+	// rotate both sides to the new CA, the next handshakes after the reload interval use it
+	newCA.issue(t, dir, "replica")
+	writeFile(t, caFile, newCA.pem)
+	time.Sleep(tlsReloadInterval + 100*time.Millisecond)
+	cert, err := handshake(server, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Issuer.CommonName != "new" {
+		t.Errorf("expected a certificate of the new CA, got one of %s", cert.Issuer.CommonName)
+	}
+}
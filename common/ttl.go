@@ -0,0 +1,41 @@
+package common
+
+import (
+	protobuf "google.golang.org/protobuf/proto"
+	"shared-registers/common/proto"
+	"time"
+)
+
+// Expired reports whether the TTL of the value ran out at now
+func Expired(value *proto.StoredValue, now time.Time) bool {
+	return value.GetExpiresAt() != 0 && uint64(now.UnixNano()) >= value.GetExpiresAt()
+}
+
+// Visible
+// return the value as readers may see it at now: an expired value turns into a tombstone that keeps
+// its timestamp, so older writes arriving late still lose against it
+func Visible(value *proto.StoredValue, now time.Time) *proto.StoredValue {
+	if !Expired(value, now) {
+		return value
+	}
+	return ExpiryTombstone(value)
+}
+
+// ExpiryTombstone is the tombstone the value turns into once it expires, encrypted values keep the seal of the tombstone
+func ExpiryTombstone(value *proto.StoredValue) *proto.StoredValue {
+	return &proto.StoredValue{Ts: value.GetTs(), Deleted: true, KeyID: value.GetKeyID(), Seal: value.GetSeal()}
+}
+
+// SameWrite
+// whether two values with the same timestamp come from the same write: they are equal, or one is
+// the tombstone an expired copy of the other turns into, e.g. swept by one replica while a client
+// writes back the copy another replica doesn't consider expired yet
+func SameWrite(a, b *proto.StoredValue) bool {
+	if protobuf.Equal(a, b) {
+		return true
+	}
+	isExpiryOf := func(tombstone, value *proto.StoredValue) bool {
+		return value.GetExpiresAt() != 0 && protobuf.Equal(tombstone, ExpiryTombstone(value))
+	}
+	return isExpiryOf(a, b) || isExpiryOf(b, a)
+}
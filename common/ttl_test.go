@@ -0,0 +1,56 @@
+package common
+
+import (
+	protobuf "google.golang.org/protobuf/proto"
+	"shared-registers/common/proto"
+	"testing"
+	"time"
+)
+
+func TestVisible(t *testing.T) {
+	now := time.Now()
+	ts := &proto.TimeStamp{RequestNumber: 1, ClientID: "c"}
+	live := &proto.StoredValue{Val: []byte("v"), Ts: ts, ExpiresAt: uint64(now.Add(time.Second).UnixNano())}
+	if Visible(live, now) != live {
+		t.Error("value hidden before it expired")
+	}
+	hidden := Visible(live, now.Add(2*time.Second))
+	if !hidden.GetDeleted() || hidden.GetVal() != nil || CompareTimeStamps(hidden.GetTs(), ts) != 0 {
+		t.Errorf("expected a tombstone with the same timestamp, got %v", hidden)
+	}
+	sealed := &proto.StoredValue{Val: []byte("v"), Ts: ts, ExpiresAt: live.GetExpiresAt(), KeyID: "k1", Seal: []byte("seal")}
+	if hidden := Visible(sealed, now.Add(2*time.Second)); hidden.GetKeyID() != "k1" || string(hidden.GetSeal()) != "seal" {
+		t.Errorf("expected the tombstone to keep the seal, got %v", hidden)
+	}
+	forever := &proto.StoredValue{Val: []byte("v"), Ts: ts}
+	if Expired(forever, now.Add(time.Hour)) {
+		t.Error("value without TTL expired")
+	}
+}
+
+func TestSameWrite(t *testing.T) {
+	value := func(val string, n uint64) *proto.StoredValue {
+		return &proto.StoredValue{Val: []byte(val), Ts: &proto.TimeStamp{RequestNumber: n, ClientID: "c"}}
+	}
+	expiring := value("v", 2)
+	expiring.ExpiresAt = 1
+	sealed := protobuf.Clone(expiring).(*proto.StoredValue)
+	sealed.KeyID, sealed.Seal = "k1", []byte("seal")
+	for _, tc := range []struct {
+		name string
+		a, b *proto.StoredValue
+		want bool
+	}{
+		{"equal", value("v", 2), value("v", 2), true},
+		{"different values", value("v", 2), value("w", 2), false},
+		{"swept expiry", expiring, ExpiryTombstone(expiring), true},
+		{"swept expiry reversed", ExpiryTombstone(expiring), expiring, true},
+		{"swept sealed expiry", sealed, ExpiryTombstone(sealed), true},
+		{"tombstone of a value without TTL", value("v", 2), ExpiryTombstone(value("v", 2)), false},
+		{"tombstone with another seal", sealed, ExpiryTombstone(expiring), false},
+	} {
+		if got := SameWrite(tc.a, tc.b); got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
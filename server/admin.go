@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log"
+	"runtime/debug"
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"shared-registers/server/audit"
+	"shared-registers/server/store"
+	"sync"
+	"time"
+)
+
+// replicaMode is the proto.ReplicaMode of the replica, its zero value is SERVING
+type replicaMode struct {
+	lock     sync.Mutex
+	mode     proto.ReplicaMode
+	draining chan struct{} // closed once the replica drains
+}
+
+// get returns the mode and a channel that is closed once the replica drains
+func (m *replicaMode) get() (proto.ReplicaMode, <-chan struct{}) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.draining == nil {
+		m.draining = make(chan struct{})
+	}
+	return m.mode, m.draining
+}
+
+// set switches to the mode and returns the previous one
+func (m *replicaMode) set(mode proto.ReplicaMode) proto.ReplicaMode {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.draining == nil {
+		m.draining = make(chan struct{})
+	}
+	previous := m.mode
+	if mode == proto.ReplicaMode_DRAINING && previous != mode {
+		close(m.draining)
+	} else if previous == proto.ReplicaMode_DRAINING && mode != previous {
+		m.draining = make(chan struct{})
+	}
+	m.mode = mode
+	return previous
+}
+
+// checkMode fails with Unavailable unless the replica serves the request in its mode, stores tells
+// whether the request stores anything
+func (s *server) checkMode(stores bool) error {
+	switch mode, _ := s.mode.get(); {
+	case mode == proto.ReplicaMode_DRAINING:
+		return status.Error(codes.Unavailable, "replica is draining")
+	case stores && mode == proto.ReplicaMode_READ_ONLY:
+		return status.Error(codes.Unavailable, "replica is read-only")
+	}
+	return nil
+}
+
+// admin serves the Admin service of a replica
+type admin struct {
+	proto.UnimplementedAdminServer
+	replica *server
+	name    string
+	started time.Time
+}
+
+// DumpValue returns the stored <value, ts> of the key, whatever it is
+func (a *admin) DumpValue(ctx context.Context, in *proto.DumpValueReq) (*proto.DumpValueRsp, error) {
+	if err := a.replica.checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	v, err := store.Get(in.GetKey())
+	if err != nil {
+		return nil, err
+	}
+	return &proto.DumpValueRsp{Value: v}, nil
+}
+
+func (a *admin) CountKeys(ctx context.Context, in *proto.CountKeysReq) (*proto.CountKeysRsp, error) {
+	if err := a.replica.checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	keys, deleted, internal := store.Count(in.GetPrefix(), time.Now())
+	return &proto.CountKeysRsp{Keys: uint64(keys), Deleted: uint64(deleted), Internal: uint64(internal)}, nil
+}
+
+func (a *admin) ListKeys(ctx context.Context, in *proto.ListKeysReq) (*proto.ListKeysRsp, error) {
+	if err := a.replica.checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	keys, more := store.Keys(in.GetPrefix(), in.GetStartAfter(), int(in.GetLimit()), in.GetInternal())
+	return &proto.ListKeysRsp{Keys: keys, More: more}, nil
+}
+
+func (a *admin) Compact(ctx context.Context, in *proto.CompactReq) (*proto.CompactRsp, error) {
+	if err := a.replica.checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	registers, err := store.Compact()
+	if errors.Is(err, store.ErrNotPersisted) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		log.Printf("compaction failed: %v", err)
+		return nil, status.Errorf(codes.Internal, "compaction failed: %v", err)
+	}
+	log.Printf("compacted the log into a snapshot of %d registers", registers)
+	return &proto.CompactRsp{Registers: registers}, nil
+}
+
+func (a *admin) SetLogLevel(ctx context.Context, in *proto.SetLogLevelReq) (*proto.SetLogLevelRsp, error) {
+	if err := a.replica.checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if _, ok := proto.LogLevel_name[int32(in.GetLevel())]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown log level %d", in.GetLevel())
+	}
+	previous := setLogLevel(in.GetLevel())
+	log.Printf("log level changed from %v to %v", previous, in.GetLevel())
+	return &proto.SetLogLevelRsp{Previous: previous}, nil
+}
+
+// SetMode
+// switch the replica to serving, read-only or draining. Draining ends the open watches, the
+// replica is back to serving once it restarts.
+func (a *admin) SetMode(ctx context.Context, in *proto.SetModeReq) (*proto.SetModeRsp, error) {
+	if err := a.replica.checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if _, ok := proto.ReplicaMode_name[int32(in.GetMode())]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown mode %d", in.GetMode())
+	}
+	previous := a.replica.mode.set(in.GetMode())
+	log.Printf("mode changed from %v to %v", previous, in.GetMode())
+	return &proto.SetModeRsp{Previous: previous}, nil
+}
+
+// RepairValue
+// store the value of the key like a SetPhase writing it back, without checking that the caller may
+// write the key or store values of the client ID, since operators repair the values of every client.
+// Stored repairs are audited as accepted.
+func (a *admin) RepairValue(ctx context.Context, in *proto.RepairValueReq) (*proto.RepairValueRsp, error) {
+	if err := a.replica.checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err := a.replica.checkMode(true); err != nil {
+		return nil, err
+	}
+	value := in.GetValue()
+	if value.GetTs().GetClientID() == "" {
+		return nil, status.Error(codes.InvalidArgument, "repairs need a value with a timestamp")
+	}
+	stored, err := store.SetIfNewer(in.GetKey(), value, func(current *proto.StoredValue, newer bool) error {
+		if current != nil && common.CompareTimeStamps(current.GetTs(), value.GetTs()) == 0 && !common.SameWrite(current, value) {
+			return status.Errorf(codes.FailedPrecondition, "conflicting value for timestamp <%d, %s> of key %s",
+				value.GetTs().GetRequestNumber(), value.GetTs().GetClientID(), in.GetKey())
+		}
+		if !newer {
+			return nil
+		}
+		return a.replica.audit(ctx, in.GetKey(), value, audit.Accepted)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if stored {
+		log.Printf("repaired key %q with the value of <%d, %s>", in.GetKey(), value.GetTs().GetRequestNumber(), value.GetTs().GetClientID())
+	}
+	return &proto.RepairValueRsp{Stored: stored}, nil
+}
+
+func (a *admin) GetInfo(ctx context.Context, in *proto.GetInfoReq) (*proto.GetInfoRsp, error) {
+	if err := a.replica.checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	mode, _ := a.replica.mode.get()
+	dir, snapshotRecords, logRecords := store.Persistence()
+	return &proto.GetInfoRsp{
+		Name:            a.name,
+		Build:           buildInfo(),
+		StartedAt:       uint64(a.started.UnixNano()),
+		UptimeMillis:    uint64(time.Since(a.started).Milliseconds()),
+		Mode:            mode,
+		LogLevel:        getLogLevel(),
+		DataDir:         dir,
+		SnapshotRecords: snapshotRecords,
+		LogRecords:      logRecords,
+	}, nil
+}
+
+// buildInfo returns what the binary knows about how it was built
+func buildInfo() *proto.BuildInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return &proto.BuildInfo{}
+	}
+	build := &proto.BuildInfo{GoVersion: info.GoVersion, Path: info.Path, Version: info.Main.Version}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			build.Revision = setting.Value
+		case "vcs.time":
+			build.RevisionTime = setting.Value
+		case "vcs.modified":
+			build.Modified = setting.Value == "true"
+		}
+	}
+	return build
+}
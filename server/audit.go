@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
+	"log"
+	"shared-registers/common/proto"
+	"shared-registers/server/audit"
+	"shared-registers/server/auth"
+)
+
+// audit
+// record the outcome of a SetPhase in the audit log, if there is one. The write fails if it can't be
+// recorded, so the log accounts for every value the replica stores.
+func (s *server) audit(ctx context.Context, key string, value *proto.StoredValue, outcome string) error {
+	if s.auditLog == nil {
+		return nil
+	}
+	e := audit.NewEntry(key, value)
+	e.Outcome = outcome
+	return s.appendAudit(ctx, e)
+}
+
+// auditCoded
+// record the outcome of a coded write phase of ts like audit. Pre-writes are described by the meta
+// of their fragment, which is the same on every replica, finalizations only by the timestamp.
+func (s *server) auditCoded(ctx context.Context, key string, ts *proto.TimeStamp, fragment *proto.CodedFragment, outcome string) error {
+	if s.auditLog == nil {
+		return nil
+	}
+	value := &proto.StoredValue{}
+	if fragment.GetMeta() != nil {
+		value = protobuf.Clone(fragment.GetMeta()).(*proto.StoredValue)
+	}
+	value.Ts = ts
+	e := audit.NewEntry(key, value)
+	if fragment == nil {
+		e.ValueHash = ""
+	}
+	e.Outcome = outcome
+	return s.appendAudit(ctx, e)
+}
+
+// auditExpiry records that the sweeper replaces an expired value with the tombstone, which it leaves alone if this fails
+func (s *server) auditExpiry(key string, tombstone *proto.StoredValue) error {
+	if s.auditLog == nil {
+		return nil
+	}
+	e := audit.NewEntry(key, tombstone)
+	e.Outcome = audit.Expired
+	return s.appendAudit(context.Background(), e)
+}
+
+// appendAudit appends the entry with the caller of ctx, if any
+func (s *server) appendAudit(ctx context.Context, e *audit.Entry) error {
+	if p := auth.FromContext(ctx); p != nil {
+		e.Principal = p.Name
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		e.Peer = p.Addr.String()
+	}
+	if err := s.auditLog.Append(e); err != nil {
+		log.Printf("failed to audit the write of %q (%s): %v", e.Key, e.Outcome, err)
+		return status.Error(codes.Unavailable, "audit log unavailable")
+	}
+	return nil
+}
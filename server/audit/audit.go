@@ -0,0 +1,188 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	protobuf "google.golang.org/protobuf/proto"
+	"os"
+	"path/filepath"
+	"shared-registers/common/proto"
+	"shared-registers/server/crypt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// outcomes of a SetPhase, of the pre-write and finalization of an erasure-coded write, and of sweeping
+const (
+	Accepted   = "accepted"   // the value replaced the stored one
+	Stale      = "stale"      // the replica already stored a newer value
+	Denied     = "denied"     // the ACL didn't allow the write
+	PreWritten = "prewritten" // the fragment of a coded value was stored, readers don't see it yet
+	Finalized  = "finalized"  // the timestamp of a coded value was finalized, readers see it
+	Expired    = "expired"    // the sweeper replaced the expired value with a tombstone
+)
+
+const segmentPrefix, segmentSuffix = "audit-", ".log"
+
+// Entry is one SetPhase, coded write phase or sweep a replica handled
+type Entry struct {
+	Time          time.Time `json:"time"`    // wall time of the replica
+	Replica       string    `json:"replica"` // name of the replica, its host and port by default
+	Key           string    `json:"key"`
+	ValueHash     string    `json:"valueHash,omitempty"` // sha256 of the value, identical on every replica
+	Deleted       bool      `json:"deleted,omitempty"`
+	RequestNumber uint64    `json:"requestNumber"`
+	ClientID      string    `json:"clientID"`
+	WallTime      uint64    `json:"wallTime,omitempty"`
+	Logical       uint32    `json:"logical,omitempty"`
+	Principal     string    `json:"principal,omitempty"` // authenticated caller, empty without an ACL or for sweeps
+	Peer          string    `json:"peer,omitempty"`      // address of the caller, empty for sweeps
+	Outcome       string    `json:"outcome"`
+}
+
+// NewEntry describes the write of value under key, the caller fills in who sent it and the outcome
+func NewEntry(key string, value *proto.StoredValue) *Entry {
+	ts := value.GetTs()
+	return &Entry{
+		Time:          time.Now(),
+		Key:           key,
+		ValueHash:     ValueHash(value),
+		Deleted:       value.GetDeleted(),
+		RequestNumber: ts.GetRequestNumber(),
+		ClientID:      ts.GetClientID(),
+		WallTime:      ts.GetWallTime(),
+		Logical:       ts.GetLogical(),
+	}
+}
+
+// ValueHash returns the hex sha256 of everything about the value but its timestamp
+func ValueHash(value *proto.StoredValue) string {
+	v := protobuf.Clone(value).(*proto.StoredValue)
+	v.Ts = nil
+	data, _ := protobuf.MarshalOptions{Deterministic: true}.Marshal(v)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Log
+// an append-only audit log in a directory of record files, one segment per start of the replica or
+// whenever the current segment reached MaxSize. Only the newest MaxFiles segments are kept, 0 keeps
+// all of them. The segments are encrypted like the data directory if a keyring is given, and with sync
+// every entry is flushed to disk before Append returns.
+type Log struct {
+	Replica  string // filled into every entry
+	MaxSize  int64
+	MaxFiles int
+
+	dir     string
+	keyring *crypt.Keyring
+	sync    bool
+	mu      sync.Mutex
+	w       *crypt.RecordWriter
+	started int64 // unix nanos the current segment is named after
+}
+
+// Open starts a new segment in dir
+func Open(dir string, keyring *crypt.Keyring, sync bool, replica string, maxSize int64, maxFiles int) (*Log, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	l := &Log{Replica: replica, MaxSize: maxSize, MaxFiles: maxFiles, dir: dir, keyring: keyring, sync: sync}
+	if err := l.rotate(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Append
+// write the entry to the log. Replicas append before they act on a write, and fail the write if
+// this fails, so every stored value is accounted for.
+func (l *Log) Append(e *Entry) error {
+	e.Replica = l.Replica
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.w.Append(data); err != nil {
+		return err
+	}
+	if l.MaxSize > 0 && l.w.Bytes() >= l.MaxSize {
+		return l.rotate()
+	}
+	return nil
+}
+
+// rotate closes the current segment, starts a new one and drops the segments beyond MaxFiles
+func (l *Log) rotate() error {
+	if l.w != nil {
+		if err := l.w.Close(); err != nil {
+			return err
+		}
+	}
+	// segments sort by the time they were started, even if the clock is too coarse to tell them apart
+	started := time.Now().UnixNano()
+	if started <= l.started {
+		started = l.started + 1
+	}
+	l.started = started
+	name := filepath.Join(l.dir, fmt.Sprintf("%s%020d%s", segmentPrefix, started, segmentSuffix))
+	w, err := crypt.CreateRecordFile(name, l.keyring, l.sync)
+	if err != nil {
+		return err
+	}
+	l.w = w
+	if l.MaxFiles <= 0 {
+		return nil
+	}
+	segments, err := Segments(l.dir)
+	if err != nil {
+		return err
+	}
+	for len(segments) > l.MaxFiles {
+		if err := os.Remove(segments[0]); err != nil {
+			return err
+		}
+		segments = segments[1:]
+	}
+	return nil
+}
+
+// Close closes the current segment
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Close()
+}
+
+// Segments returns the paths of the audit log segments in dir, oldest first
+func Segments(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var segments []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), segmentPrefix) && strings.HasSuffix(e.Name(), segmentSuffix) {
+			segments = append(segments, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(segments)
+	return segments, nil
+}
+
+// Read calls fn with every entry of the segment in order
+func Read(segment string, keyring *crypt.Keyring, fn func(*Entry) error) error {
+	return crypt.ReadRecordFile(segment, keyring, func(record []byte) error {
+		e := &Entry{}
+		if err := json.Unmarshal(record, e); err != nil {
+			return err
+		}
+		return fn(e)
+	})
+}
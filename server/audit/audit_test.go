@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"bytes"
+	"os"
+	"shared-registers/common/proto"
+	"shared-registers/server/crypt"
+	"testing"
+)
+
+func readAll(t *testing.T, dir string, keyring *crypt.Keyring) []*Entry {
+	segments, err := Segments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []*Entry
+	for _, segment := range segments {
+		if err := Read(segment, keyring, func(e *Entry) error {
+			entries = append(entries, e)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return entries
+}
+
+func TestLog(t *testing.T) {
+	dir := t.TempDir()
+	line, _ := crypt.GenerateKeyLine("k1")
+	keyring, _ := crypt.ParseKeyring([]byte(line))
+	l, err := Open(dir, keyring, true, "replica-1", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := &proto.TimeStamp{RequestNumber: 3, ClientID: "writer"}
+	for _, outcome := range []string{Accepted, Stale, Denied} {
+		e := NewEntry("audited", &proto.StoredValue{Val: []byte("secret"), Ts: ts})
+		e.Outcome, e.Principal, e.Peer = outcome, "writer", "127.0.0.1:1234"
+		if err := l.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Close()
+
+	entries := readAll(t, dir, keyring)
+	if len(entries) != 3 || entries[1].Outcome != Stale {
+		t.Fatalf("expected the 3 entries in order, got %v", entries)
+	}
+	e := entries[0]
+	if e.Replica != "replica-1" || e.Key != "audited" || e.RequestNumber != 3 || e.ClientID != "writer" || e.Peer != "127.0.0.1:1234" {
+		t.Errorf("entry not read back: %+v", e)
+	}
+	segments, _ := Segments(dir)
+	data, _ := os.ReadFile(segments[0])
+	if bytes.Contains(data, []byte("audited")) {
+		t.Error("segment isn't encrypted")
+	}
+	if err := Read(segments[0], nil, func(*Entry) error { return nil }); err == nil {
+		t.Error("encrypted segment read without keys")
+	}
+}
+
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, nil, false, "replica-1", 200, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(1); i <= 20; i++ {
+		e := NewEntry("rotated", &proto.StoredValue{Ts: &proto.TimeStamp{RequestNumber: i}})
+		e.Outcome = Accepted
+		if err := l.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Close()
+
+	segments, _ := Segments(dir)
+	if len(segments) != 3 {
+		t.Fatalf("expected 3 segments to be kept, got %d", len(segments))
+	}
+	// the newest entries are kept, in order
+	entries := readAll(t, dir, nil)
+	if len(entries) == 0 || entries[len(entries)-1].RequestNumber != 20 {
+		t.Fatalf("newest entry missing: %v", entries)
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].RequestNumber != entries[i-1].RequestNumber+1 {
+			t.Fatalf("entries out of order: %d after %d", entries[i].RequestNumber, entries[i-1].RequestNumber)
+		}
+	}
+}
+
+func TestValueHash(t *testing.T) {
+	a := &proto.StoredValue{Val: []byte("v"), ContentType: "text/plain", Ts: &proto.TimeStamp{RequestNumber: 1}}
+	b := &proto.StoredValue{Val: []byte("v"), ContentType: "text/plain", Ts: &proto.TimeStamp{RequestNumber: 2}}
+	if ValueHash(a) != ValueHash(b) {
+		t.Error("the hash depends on the timestamp")
+	}
+	if a.GetTs().GetRequestNumber() != 1 {
+		t.Error("hashing modified the value")
+	}
+	b.ContentType = "text/html"
+	if ValueHash(a) == ValueHash(b) {
+		t.Error("different values have the same hash")
+	}
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"shared-registers/common"
+	"strings"
+)
+
+// Principal
+// an authenticated caller and what it may do: the ClientIDs it may stamp writes with, and the key
+// prefixes it may read and write. A ClientID pattern ending in "*" matches every ClientID starting
+// with the rest, without patterns the principal may only use its own name. Writing implies reading,
+// the prefix "" covers every key. Only principals with WriteBack may store values stamped with the
+// ClientID of another principal, as reads do to complete a write, and only admins may call the
+// Admin service, which reaches every key.
+type Principal struct {
+	Name      string   `json:"-"`
+	Tokens    []string `json:"tokens"`
+	ClientIDs []string `json:"clientIDs"`
+	Read      []string `json:"read"`
+	Write     []string `json:"write"`
+	WriteBack bool     `json:"writeBack"`
+	Admin     bool     `json:"admin"`
+}
+
+// ACL maps the credentials of callers to principals
+type ACL struct {
+	principals map[string]*Principal
+	tokens     map[string]*Principal
+}
+
+// Load reads an ACL file, see Parse
+func Load(name string) (*ACL, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse
+// parse a JSON object that maps principal names to Principals, e.g.
+//
+//	{"alice": {"tokens": ["s3cret"], "clientIDs": ["alice-*"], "read": [""], "write": ["alice/"], "writeBack": true},
+//	 "ops": {"tokens": ["0ps"], "admin": true}}
+//
+// Callers presenting one of the tokens are the principal, as are callers with a verified client
+// certificate whose common name is the principal's name.
+func Parse(data []byte) (*ACL, error) {
+	var principals map[string]*Principal
+	if err := json.Unmarshal(data, &principals); err != nil {
+		return nil, err
+	}
+	a := &ACL{principals: principals, tokens: make(map[string]*Principal)}
+	for name, p := range principals {
+		if name == "" || p == nil {
+			return nil, errors.New("ACL: empty principal")
+		}
+		p.Name = name
+		for _, token := range p.Tokens {
+			if token == "" || a.tokens[token] != nil {
+				return nil, errors.New("ACL: empty or duplicate token of principal " + name)
+			}
+			a.tokens[token] = p
+		}
+	}
+	return a, nil
+}
+
+// Principal returns the principal with the name, nil if there is none
+func (a *ACL) Principal(name string) *Principal {
+	return a.principals[name]
+}
+
+// ClientIDWrites reports whether a principal that may use clientID may write the key, which
+// replicas check before accepting a value another client wrote
+func (a *ACL) ClientIDWrites(clientID, key string) bool {
+	for _, p := range a.principals {
+		if p.OwnsClientID(clientID) && p.CanWrite(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// MayStore
+// report whether the principal may store a value stamped with clientID under a key it may write: a
+// ClientID of its own, or with WriteBack one of a principal that may write the key too. Replicas
+// can't tell a write-back from a forged value, so WriteBack trusts the principal not to forge.
+func (a *ACL) MayStore(p *Principal, clientID, key string) bool {
+	return p.OwnsClientID(clientID) || p.WriteBack && a.ClientIDWrites(clientID, key)
+}
+
+// OwnsClientID reports whether the principal may stamp writes with clientID
+func (p *Principal) OwnsClientID(clientID string) bool {
+	if len(p.ClientIDs) == 0 {
+		return clientID == p.Name
+	}
+	for _, pattern := range p.ClientIDs {
+		if prefix := strings.TrimSuffix(pattern, "*"); clientID == pattern || (prefix != pattern && strings.HasPrefix(clientID, prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) CanRead(key string) bool {
+	return p.CanWrite(key) || hasPrefix(aclKey(key), p.Read)
+}
+
+func (p *Principal) CanWrite(key string) bool {
+	return hasPrefix(aclKey(key), p.Write)
+}
+
+// aclKey
+// the key whose permissions apply to key: the user key an internal key belongs to, e.g. the key of a
+// chunk, which comes after the internal prefix and the kind of the key
+func aclKey(key string) string {
+	if !common.IsInternalKey(key) {
+		return key
+	}
+	key = strings.TrimPrefix(key, common.InternalKeyPrefix)
+	if i := strings.Index(key, "/"); i >= 0 {
+		return key[i+1:]
+	}
+	return key
+}
+
+func hasPrefix(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the principal a request was authenticated as
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal the request was authenticated as, nil if it wasn't
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
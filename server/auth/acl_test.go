@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"shared-registers/common"
+	"testing"
+)
+
+const testACL = `{
+	"alice": {"tokens": ["alice-token"], "clientIDs": ["alice-*", "shared"], "write": ["alice/", "shared/"]},
+	"bob": {"tokens": ["bob-token"], "read": ["alice/public/"], "write": ["bob/", "shared/"], "writeBack": true},
+	"auditor": {"read": [""], "admin": true}
+}`
+
+func TestPermissions(t *testing.T) {
+	acl, err := Parse([]byte(testACL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, bob, auditor := acl.Principal("alice"), acl.Principal("bob"), acl.Principal("auditor")
+
+	for _, c := range []struct {
+		p           *Principal
+		key         string
+		read, write bool
+	}{
+		{alice, "alice/x", true, true},
+		{alice, "bob/x", false, false},
+		{bob, "alice/public/x", true, false},
+		{bob, "alice/private", false, false},
+		{bob, "shared/x", true, true},
+		{auditor, "bob/x", true, false},
+		{bob, common.InternalKeyPrefix + "chunk/bob/x/w/00", true, true},
+		{bob, common.InternalKeyPrefix + "chunk/alice/x/w/00", false, false},
+	} {
+		if c.p.CanRead(c.key) != c.read || c.p.CanWrite(c.key) != c.write {
+			t.Errorf("%s on %q: expected read %v, write %v", c.p.Name, c.key, c.read, c.write)
+		}
+	}
+
+	for _, c := range []struct {
+		p        *Principal
+		clientID string
+		owns     bool
+	}{
+		{alice, "alice-1", true},
+		{alice, "alice", false},
+		{alice, "shared", true},
+		{alice, "shared-1", false},
+		{bob, "bob", true},
+		{bob, "bob-1", false},
+	} {
+		if c.p.OwnsClientID(c.clientID) != c.owns {
+			t.Errorf("%s owning %q: expected %v", c.p.Name, c.clientID, c.owns)
+		}
+	}
+
+	if !auditor.Admin || alice.Admin {
+		t.Error("wrong admins")
+	}
+
+	if !acl.ClientIDWrites("bob", "shared/x") || acl.ClientIDWrites("bob", "alice/x") || acl.ClientIDWrites("mallory", "shared/x") {
+		t.Error("wrong writers of client IDs")
+	}
+	// alice may not forge values under bob's client ID, bob may write back hers where both may write
+	for _, c := range []struct {
+		p             *Principal
+		clientID, key string
+		may           bool
+	}{
+		{alice, "alice-1", "shared/x", true},
+		{alice, "bob", "shared/x", false},
+		{alice, "bob", "alice/x", false},
+		{bob, "alice-1", "shared/x", true},
+		{bob, "alice-1", "bob/x", false},
+		{bob, "mallory", "shared/x", false},
+	} {
+		if acl.MayStore(c.p, c.clientID, c.key) != c.may {
+			t.Errorf("%s storing a value of %q under %q: expected %v", c.p.Name, c.clientID, c.key, c.may)
+		}
+	}
+
+	for _, bad := range []string{`{"": {}}`, `{"a": {"tokens": ["t"]}, "b": {"tokens": ["t"]}}`, `[]`} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("invalid ACL %s accepted", bad)
+		}
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	acl, _ := Parse([]byte(testACL))
+	withToken := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(TokenMetadataKey, token))
+	}
+	withCert := func(name string) context.Context {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: name}}
+		info := credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}
+		return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: info})
+	}
+
+	for _, c := range []struct {
+		ctx       context.Context
+		principal string
+	}{
+		{withToken("Bearer alice-token"), "alice"},
+		{withToken("Bearer bob-token"), "bob"},
+		{withToken("alice-token"), ""},
+		{withToken("Bearer mallory-token"), ""},
+		{withCert("auditor"), "auditor"},
+		{withCert("mallory"), ""},
+		{context.Background(), ""},
+	} {
+		p, err := acl.Authenticate(c.ctx)
+		if c.principal == "" {
+			if status.Code(err) != codes.Unauthenticated {
+				t.Errorf("expected Unauthenticated, got %v, %v", p, err)
+			}
+		} else if err != nil || p.Name != c.principal {
+			t.Errorf("expected %s, got %v, %v", c.principal, p, err)
+		}
+	}
+}
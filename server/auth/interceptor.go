@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+// TokenMetadataKey is the gRPC metadata a bearer token is sent in, as "Bearer <token>"
+const TokenMetadataKey = "authorization"
+
+// Authenticate
+// find the principal of the caller: the owner of its bearer token if it sent one, otherwise the
+// principal named by the common name of its client certificate, if the TLS handshake verified it
+func (a *ACL) Authenticate(ctx context.Context) (*Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(TokenMetadataKey); len(values) > 0 {
+		token := strings.TrimPrefix(values[0], "Bearer ")
+		if p := a.tokens[token]; token != values[0] && p != nil {
+			return p, nil
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid bearer token")
+	}
+	if pr, ok := peer.FromContext(ctx); ok {
+		if info, ok := pr.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 {
+			name := info.State.VerifiedChains[0][0].Subject.CommonName
+			if p := a.principals[name]; p != nil {
+				return p, nil
+			}
+			return nil, status.Errorf(codes.Unauthenticated, "client certificate of unknown principal %q", name)
+		}
+	}
+	return nil, status.Error(codes.Unauthenticated, "no bearer token or client certificate")
+}
+
+// UnaryInterceptor authenticates every request, the handlers find the principal with FromContext
+func (a *ACL) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	p, err := a.Authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(WithPrincipal(ctx, p), req)
+}
+
+// StreamInterceptor is UnaryInterceptor for streaming calls
+func (a *ACL) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	p, err := a.Authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: WithPrincipal(ss.Context(), p)})
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
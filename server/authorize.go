@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"shared-registers/common/proto"
+	"shared-registers/server/auth"
+)
+
+// caller returns the authenticated principal, nil with a nil error if the replica has no ACL
+func (s *server) caller(ctx context.Context) (*auth.Principal, error) {
+	if s.acl == nil {
+		return nil, nil
+	}
+	if p := auth.FromContext(ctx); p != nil {
+		return p, nil
+	}
+	return nil, status.Error(codes.Unauthenticated, "request wasn't authenticated")
+}
+
+func (s *server) checkRead(ctx context.Context, key string) error {
+	p, err := s.caller(ctx)
+	if err != nil || p == nil {
+		return err
+	}
+	if !p.CanRead(key) {
+		return status.Errorf(codes.PermissionDenied, "%s may not read key %q", p.Name, key)
+	}
+	return nil
+}
+
+func (s *server) checkWrite(ctx context.Context, key string) error {
+	p, err := s.caller(ctx)
+	if err != nil || p == nil {
+		return err
+	}
+	if !p.CanWrite(key) {
+		return status.Errorf(codes.PermissionDenied, "%s may not write key %q", p.Name, key)
+	}
+	return nil
+}
+
+// checkAdmin fails unless the caller may call the Admin service
+func (s *server) checkAdmin(ctx context.Context) error {
+	p, err := s.caller(ctx)
+	if err != nil || p == nil {
+		return err
+	}
+	if !p.Admin {
+		return status.Errorf(codes.PermissionDenied, "%s isn't an admin", p.Name)
+	}
+	return nil
+}
+
+// checkClientID fails unless the caller may stamp its own writes with the ClientID of ts
+func (s *server) checkClientID(ctx context.Context, ts *proto.TimeStamp) error {
+	p, err := s.caller(ctx)
+	if err != nil || p == nil {
+		return err
+	}
+	if !p.OwnsClientID(ts.GetClientID()) {
+		return status.Errorf(codes.PermissionDenied, "%s may not use client ID %q", p.Name, ts.GetClientID())
+	}
+	return nil
+}
+
+// checkWrittenValue
+// fail unless the caller may store a value with timestamp ts under the key: it may write the key, and
+// the value is its own or, if the caller may write back, one written by a client whose principal may
+// write the key too, which the caller writes back to complete a read or adopts in a compare-and-swap
+func (s *server) checkWrittenValue(ctx context.Context, key string, ts *proto.TimeStamp) error {
+	if err := s.checkWrite(ctx, key); err != nil {
+		return err
+	}
+	p, _ := s.caller(ctx)
+	if p != nil && !s.acl.MayStore(p, ts.GetClientID(), key) {
+		return status.Errorf(codes.PermissionDenied, "%s may not store values of client ID %q", p.Name, ts.GetClientID())
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+// Command auditq
+// reconstructs the write history of keys from the audit logs of replicas: every write is listed once
+// in timestamp order, with the replicas that accepted it and those that already had a newer value,
+// who sent it and from where. Erasure-coded writes list the replicas that stored their fragment and
+// finalized them, and the tombstones of expired values the replicas that swept them. Writes the ACL
+// denied are listed separately.
+//
+//	auditq [-key KEY | -prefix PREFIX] [-key-file KEYS] [-json] [-denied=false] AUDIT_DIR...
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"shared-registers/server/audit"
+	"shared-registers/server/crypt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	key      = flag.String("key", "", "only show the writes of this key")
+	prefix   = flag.String("prefix", "", "only show the writes of keys with this prefix")
+	keyFile  = flag.String("key-file", "", "key file the audit logs are encrypted with")
+	jsonOut  = flag.Bool("json", false, "print the writes as JSON, one per line")
+	showDeny = flag.Bool("denied", true, "also show the writes the ACL denied")
+)
+
+// write is one value of a key, as the replicas saw it
+type write struct {
+	Key        string           `json:"key"`
+	Ts         *proto.TimeStamp `json:"ts"`
+	ValueHash  string           `json:"valueHash,omitempty"`
+	Deleted    bool             `json:"deleted,omitempty"`
+	FirstSeen  time.Time        `json:"firstSeen"`
+	Accepted   []string         `json:"accepted,omitempty"`   // replicas that stored the value
+	PreWritten []string         `json:"prewritten,omitempty"` // replicas that stored the fragment of a coded value
+	Finalized  []string         `json:"finalized,omitempty"`  // replicas that finalized a coded value
+	Expired    []string         `json:"expired,omitempty"`    // replicas that swept the expired value into the tombstone
+	Stale      []string         `json:"stale,omitempty"`      // replicas that already had a newer one
+	Denied     []string         `json:"denied,omitempty"`     // replicas whose ACL refused it
+	Callers    []string         `json:"callers,omitempty"`    // principals and peers that sent it
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("usage: auditq [-key KEY | -prefix PREFIX] [-key-file KEYS] [-json] [-denied=false] AUDIT_DIR...")
+	}
+	var keyring *crypt.Keyring
+	if *keyFile != "" {
+		var err error
+		if keyring, err = crypt.LoadKeyring(*keyFile); err != nil {
+			log.Fatalf("failed to load %s: %v", *keyFile, err)
+		}
+	}
+
+	writes := map[string]*write{}
+	for _, dir := range flag.Args() {
+		segments, err := audit.Segments(dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, segment := range segments {
+			err := audit.Read(segment, keyring, func(e *audit.Entry) error {
+				if (*key != "" && e.Key != *key) || !strings.HasPrefix(e.Key, *prefix) {
+					return nil
+				}
+				if e.Outcome == audit.Denied && !*showDeny {
+					return nil
+				}
+				add(writes, e)
+				return nil
+			})
+			if err != nil {
+				log.Fatalf("failed to read %s: %v", segment, err)
+			}
+		}
+	}
+
+	mergeFinalizations(writes)
+	history := make([]*write, 0, len(writes))
+	for _, w := range writes {
+		history = append(history, w)
+	}
+	sort.Slice(history, func(i, j int) bool {
+		if history[i].Key != history[j].Key {
+			return history[i].Key < history[j].Key
+		}
+		return common.CompareTimeStamps(history[i].Ts, history[j].Ts) < 0
+	})
+	encoder := json.NewEncoder(os.Stdout)
+	for _, w := range history {
+		if *jsonOut {
+			encoder.Encode(w)
+			continue
+		}
+		printWrite(w)
+	}
+}
+
+// add records the entry of a replica with the write of the same key, timestamp and value
+func add(writes map[string]*write, e *audit.Entry) {
+	ts := &proto.TimeStamp{RequestNumber: e.RequestNumber, ClientID: e.ClientID, WallTime: e.WallTime, Logical: e.Logical}
+	id := timestampID(e.Key, ts) + " " + e.ValueHash
+	w := writes[id]
+	if w == nil {
+		w = &write{Key: e.Key, ValueHash: e.ValueHash, Deleted: e.Deleted, FirstSeen: e.Time, Ts: ts}
+		writes[id] = w
+	}
+	if e.Time.Before(w.FirstSeen) {
+		w.FirstSeen = e.Time
+	}
+	switch e.Outcome {
+	case audit.Accepted:
+		w.Accepted = appendNew(w.Accepted, e.Replica)
+	case audit.PreWritten:
+		w.PreWritten = appendNew(w.PreWritten, e.Replica)
+	case audit.Finalized:
+		w.Finalized = appendNew(w.Finalized, e.Replica)
+	case audit.Expired:
+		w.Expired = appendNew(w.Expired, e.Replica)
+	case audit.Stale:
+		w.Stale = appendNew(w.Stale, e.Replica)
+	case audit.Denied:
+		w.Denied = appendNew(w.Denied, e.Replica)
+	}
+	caller := e.Peer
+	if e.Principal != "" {
+		caller = e.Principal + "@" + e.Peer
+	}
+	if caller != "" {
+		w.Callers = appendNew(w.Callers, caller)
+	}
+}
+
+// mergeFinalizations
+// move the outcomes of finalizing coded writes, which only know the timestamp, to the writes of
+// the same key and timestamp that replicas pre-wrote
+func mergeFinalizations(writes map[string]*write) {
+	byTs := map[string][]*write{}
+	for _, w := range writes {
+		if w.ValueHash != "" {
+			id := timestampID(w.Key, w.Ts)
+			byTs[id] = append(byTs[id], w)
+		}
+	}
+	for id, f := range writes {
+		if f.ValueHash != "" {
+			continue
+		}
+		for _, w := range byTs[timestampID(f.Key, f.Ts)] {
+			for _, r := range f.Finalized {
+				w.Finalized = appendNew(w.Finalized, r)
+			}
+			for _, r := range f.Stale {
+				w.Stale = appendNew(w.Stale, r)
+			}
+			for _, r := range f.Denied {
+				w.Denied = appendNew(w.Denied, r)
+			}
+			for _, c := range f.Callers {
+				w.Callers = appendNew(w.Callers, c)
+			}
+			if f.FirstSeen.Before(w.FirstSeen) {
+				w.FirstSeen = f.FirstSeen
+			}
+			delete(writes, id)
+		}
+	}
+}
+
+// timestampID identifies the key and timestamp
+func timestampID(key string, ts *proto.TimeStamp) string {
+	return fmt.Sprintf("%q %d %q %d %d", key, ts.GetRequestNumber(), ts.GetClientID(), ts.GetWallTime(), ts.GetLogical())
+}
+
+func appendNew(list []string, s string) []string {
+	for _, l := range list {
+		if l == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+func printWrite(w *write) {
+	value := w.ValueHash
+	if w.Deleted {
+		value = "deleted"
+	}
+	fmt.Printf("%s %s (%d, %s, %d.%d) %s\n", w.FirstSeen.Format(time.RFC3339Nano), w.Key,
+		w.Ts.GetRequestNumber(), w.Ts.GetClientID(), w.Ts.GetWallTime(), w.Ts.GetLogical(), value)
+	if len(w.Accepted) > 0 || len(w.PreWritten)+len(w.Finalized)+len(w.Expired) == 0 {
+		fmt.Printf("\taccepted by %d: %s\n", len(w.Accepted), strings.Join(w.Accepted, ", "))
+	}
+	if len(w.PreWritten) > 0 {
+		fmt.Printf("\tpre-written on %d: %s\n", len(w.PreWritten), strings.Join(w.PreWritten, ", "))
+	}
+	if len(w.Finalized) > 0 {
+		fmt.Printf("\tfinalized by %d: %s\n", len(w.Finalized), strings.Join(w.Finalized, ", "))
+	}
+	if len(w.Expired) > 0 {
+		fmt.Printf("\tswept on expiry by %d: %s\n", len(w.Expired), strings.Join(w.Expired, ", "))
+	}
+	if len(w.Stale) > 0 {
+		fmt.Printf("\tstale on %d: %s\n", len(w.Stale), strings.Join(w.Stale, ", "))
+	}
+	if len(w.Denied) > 0 {
+		fmt.Printf("\tdenied by %d: %s\n", len(w.Denied), strings.Join(w.Denied, ", "))
+	}
+	if len(w.Callers) > 0 {
+		fmt.Printf("\tsent by %s\n", strings.Join(w.Callers, ", "))
+	}
+}
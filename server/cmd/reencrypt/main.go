@@ -0,0 +1,97 @@
+// Command reencrypt
+// rewrites the record files in the data directory and the audit log segments of a stopped replica
+// with new data keys, wrapped by the primary key of the key file, e.g. after adding a new primary key
+// to retire the old one, to encrypt the files of a replica that ran without encryption, or to
+// decrypt them with -decrypt.
+//
+//	reencrypt [-data-dir DIR] [-audit-dir DIR] -key-file KEYS [-to-key-file NEW_KEYS | -decrypt]
+//	reencrypt -generate-key KEY_ID >> KEYS
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"shared-registers/server/crypt"
+	"strings"
+)
+
+var (
+	dataDir     = flag.String("data-dir", "", "data directory of the stopped replica")
+	auditDir    = flag.String("audit-dir", "", "audit log directory of the stopped replica")
+	keyFile     = flag.String("key-file", "", "key file the files are encrypted with, its primary key encrypts them again unless -to-key-file is set")
+	toKeyFile   = flag.String("to-key-file", "", "key file whose primary key encrypts the files")
+	decrypt     = flag.Bool("decrypt", false, "write the files in plaintext")
+	generateKey = flag.String("generate-key", "", "print a key file line with a new random key with this ID and exit")
+)
+
+func main() {
+	flag.Parse()
+	if *generateKey != "" {
+		line, err := crypt.GenerateKeyLine(*generateKey)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(line)
+		return
+	}
+	if *dataDir == "" && *auditDir == "" {
+		log.Fatal("-data-dir or -audit-dir is required")
+	}
+	var from, to *crypt.Keyring
+	var err error
+	if *keyFile != "" {
+		if from, err = crypt.LoadKeyring(*keyFile); err != nil {
+			log.Fatalf("failed to load %s: %v", *keyFile, err)
+		}
+		to = from
+	}
+	if *toKeyFile != "" {
+		if to, err = crypt.LoadKeyring(*toKeyFile); err != nil {
+			log.Fatalf("failed to load %s: %v", *toKeyFile, err)
+		}
+	}
+	if *decrypt {
+		to = nil
+	} else if to == nil {
+		log.Fatal("-key-file or -to-key-file is required unless decrypting")
+	}
+
+	for _, dir := range []string{*dataDir, *auditDir} {
+		if dir != "" {
+			rewriteDir(dir, from, to)
+		}
+	}
+}
+
+// rewriteDir rewrites every record file in dir, the files of a data directory and audit log segments alike
+func rewriteDir(dir string, from, to *crypt.Keyring) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, entry := range entries {
+		name := filepath.Join(dir, entry.Name())
+		if !entry.Type().IsRegular() || strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		keyID, err := crypt.KeyID(name)
+		if err != nil {
+			log.Printf("skipping %s, it isn't a record file", name)
+			continue
+		}
+		if err := crypt.RewriteRecordFile(name, from, to); err != nil {
+			log.Fatalf("failed to rewrite %s: %v", name, err)
+		}
+		newKeyID := "plaintext"
+		if to != nil {
+			newKeyID = to.PrimaryID()
+		}
+		if keyID == "" {
+			keyID = "plaintext"
+		}
+		log.Printf("rewrote %s: %s -> %s", name, keyID, newKeyID)
+	}
+}
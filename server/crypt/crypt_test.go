@@ -0,0 +1,141 @@
+package crypt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func testKeyring(t *testing.T, ids ...string) *Keyring {
+	var lines []byte
+	for _, id := range ids {
+		line, err := GenerateKeyLine(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, line+"\n"...)
+	}
+	k, err := ParseKeyring(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func writeRecords(t *testing.T, name string, keyring *Keyring, n int) {
+	w, err := CreateRecordFile(name, keyring, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if err := w.Append([]byte("secret value " + strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readRecords(name string, keyring *Keyring) ([]string, error) {
+	var records []string
+	err := ReadRecordFile(name, keyring, func(record []byte) error {
+		records = append(records, string(record))
+		return nil
+	})
+	return records, err
+}
+
+func TestEncryptedRecords(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "records")
+	keyring := testKeyring(t, "k1")
+	writeRecords(t, name, keyring, 3)
+
+	data, _ := os.ReadFile(name)
+	if bytes.Contains(data, []byte("secret")) {
+		t.Error("plaintext found in the encrypted file")
+	}
+	records, err := readRecords(name, keyring)
+	if err != nil || len(records) != 3 || records[2] != "secret value 2" {
+		t.Fatalf("read %v, %v", records, err)
+	}
+	if _, err := readRecords(name, nil); err == nil {
+		t.Error("encrypted file read without keys")
+	}
+	if _, err := readRecords(name, testKeyring(t, "k1")); err == nil {
+		t.Error("encrypted file read with a different key of the same ID")
+	}
+
+	// a modified record with a matching CRC fails authentication
+	offset := 0
+	for i := 0; i < 3; i++ {
+		offset += 8 + int(binary.BigEndian.Uint32(data[offset:]))
+	}
+	data[len(data)-1] ^= 1
+	binary.BigEndian.PutUint32(data[offset+4:], crc32.ChecksumIEEE(data[offset+8:]))
+	os.WriteFile(name, data, 0600)
+	if _, err := readRecords(name, keyring); err == nil {
+		t.Error("modified record accepted")
+	}
+}
+
+func TestTornRecord(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "records")
+	keyring := testKeyring(t, "k1")
+	writeRecords(t, name, keyring, 3)
+	info, _ := os.Stat(name)
+	for _, cut := range []int64{1, 5, 10} {
+		if err := os.Truncate(name, info.Size()-cut); err != nil {
+			t.Fatal(err)
+		}
+		if records, err := readRecords(name, keyring); err != nil || len(records) != 2 {
+			t.Errorf("cut by %d: read %v, %v", cut, records, err)
+		}
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "records")
+	k1, _ := GenerateKeyLine("k1")
+	k2, _ := GenerateKeyLine("k2")
+	old, _ := ParseKeyring([]byte(k1))
+	rotated, err := ParseKeyring([]byte("# k2 is the primary key now\n" + k2 + "\n" + k1 + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeRecords(t, name, nil, 2)
+
+	// encrypt the plaintext file, then rotate it to the new primary key, which the old keys can't read
+	for _, c := range []struct {
+		from, to *Keyring
+		keyID    string
+	}{{nil, old, "k1"}, {rotated, rotated, "k2"}, {rotated, nil, ""}} {
+		if err := RewriteRecordFile(name, c.from, c.to); err != nil {
+			t.Fatal(err)
+		}
+		if id, _ := KeyID(name); id != c.keyID {
+			t.Fatalf("expected key %q, got %q", c.keyID, id)
+		}
+		if records, err := readRecords(name, c.to); err != nil || len(records) != 2 {
+			t.Fatalf("read %v, %v", records, err)
+		}
+		if c.keyID == "k2" {
+			if _, err := readRecords(name, old); err == nil {
+				t.Error("file of the new key read with the old one")
+			}
+		}
+	}
+}
+
+func TestParseKeyring(t *testing.T) {
+	k1, _ := GenerateKeyLine("k1")
+	for _, bad := range []string{"", "# only a comment", "k1", "k1 c2hvcnQ=", k1 + "\n" + k1} {
+		if _, err := ParseKeyring([]byte(bad)); err == nil {
+			t.Errorf("invalid key file %q accepted", bad)
+		}
+	}
+}
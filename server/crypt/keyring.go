@@ -0,0 +1,110 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"shared-registers/common"
+)
+
+// Keyring
+// the key encryption keys of a replica by key ID. Files are encrypted with a random data key each,
+// which is stored wrapped by the primary key next to the key ID, so rotating the primary key only
+// takes adding a new key and re-encrypting the files; the old keys decrypt the files meanwhile.
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// LoadKeyring reads a key file, see ParseKeyring
+func LoadKeyring(name string) (*Keyring, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeyring(data)
+}
+
+// ParseKeyring parses a key file, see common.ParseKeyFile
+func ParseKeyring(data []byte) (*Keyring, error) {
+	primary, keys, err := common.ParseKeyFile(data)
+	if err != nil {
+		return nil, err
+	}
+	k := &Keyring{primary: primary, keys: make(map[string]cipher.AEAD)}
+	for id, key := range keys {
+		if k.keys[id], err = newAEAD(key); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// GenerateKeyLine returns a key file line with a new random key
+func GenerateKeyLine(keyID string) (string, error) {
+	key := make([]byte, common.KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return keyID + " " + base64.StdEncoding.EncodeToString(key), nil
+}
+
+// PrimaryID returns the ID of the key new data keys are wrapped with
+func (k *Keyring) PrimaryID() string {
+	return k.primary
+}
+
+// NewDataKey returns a new random data key, and the key as wrapped by the primary key
+func (k *Keyring) NewDataKey() (cipher.AEAD, []byte, error) {
+	key := make([]byte, common.KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aead, Seal(k.keys[k.primary], key, []byte(k.primary)), nil
+}
+
+// UnwrapDataKey returns the data key wrapped by the key with the ID
+func (k *Keyring) UnwrapDataKey(keyID string, wrapped []byte) (cipher.AEAD, error) {
+	kek := k.keys[keyID]
+	if kek == nil {
+		return nil, errors.New("unknown key ID " + keyID)
+	}
+	key, err := Open(kek, wrapped, []byte(keyID))
+	if err != nil {
+		return nil, err
+	}
+	return newAEAD(key)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Seal encrypts and authenticates plaintext and additionalData with a random nonce it prepends
+func Seal(aead cipher.AEAD, plaintext, additionalData []byte) []byte {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		panic(err) // crypto/rand doesn't fail on supported platforms
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData)
+}
+
+// Open decrypts what Seal returned, failing if it or additionalData was modified
+func Open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("sealed data too short")
+	}
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], additionalData)
+}
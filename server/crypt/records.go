@@ -0,0 +1,220 @@
+package crypt
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+)
+
+// recordFileVersion is the version of the record file format in the header of every file
+const recordFileVersion = 1
+
+// fileHeader is the first record of a record file, as JSON and never encrypted
+type fileHeader struct {
+	Version    int    `json:"version"`
+	KeyID      string `json:"keyID,omitempty"`      // key the data key is wrapped with, empty if records are plaintext
+	WrappedKey []byte `json:"wrappedKey,omitempty"` // data key the records are encrypted with
+}
+
+// RecordWriter
+// appends records to a record file: a sequence of records each preceded by its length and CRC-32,
+// whose first record is the header. If the file is encrypted, each record is sealed with the data key
+// of the file and its position, so records can't be swapped or moved between files unnoticed.
+type RecordWriter struct {
+	f    *os.File
+	w    *bufio.Writer
+	aead cipher.AEAD // nil for plaintext records
+	next uint64      // position of the next record
+	size int64       // bytes written
+	sync bool
+}
+
+// CreateRecordFile
+// create or truncate the file and write its header, records are encrypted with a new data key if
+// keyring isn't nil. With sync, Append returns only once the record is on disk.
+func CreateRecordFile(name string, keyring *Keyring, sync bool) (*RecordWriter, error) {
+	header := fileHeader{Version: recordFileVersion}
+	var aead cipher.AEAD
+	if keyring != nil {
+		var err error
+		if aead, header.WrappedKey, err = keyring.NewDataKey(); err != nil {
+			return nil, err
+		}
+		header.KeyID = keyring.PrimaryID()
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	w := &RecordWriter{f: f, w: bufio.NewWriter(f), sync: sync}
+	data, _ := json.Marshal(header)
+	if err := w.Append(data); err != nil {
+		f.Close()
+		return nil, err
+	}
+	w.aead = aead
+	return w, nil
+}
+
+// Append writes the record at the end of the file
+func (w *RecordWriter) Append(record []byte) error {
+	if w.aead != nil {
+		record = Seal(w.aead, record, positionData(w.next))
+	}
+	var prefix [8]byte
+	binary.BigEndian.PutUint32(prefix[:4], uint32(len(record)))
+	binary.BigEndian.PutUint32(prefix[4:], crc32.ChecksumIEEE(record))
+	w.w.Write(prefix[:])
+	w.w.Write(record)
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	w.next++
+	w.size += int64(len(prefix) + len(record))
+	if w.sync {
+		return w.f.Sync()
+	}
+	return nil
+}
+
+// Size returns the number of records after the header
+func (w *RecordWriter) Size() uint64 {
+	return w.next - 1
+}
+
+// Bytes returns the size of the file
+func (w *RecordWriter) Bytes() int64 {
+	return w.size
+}
+
+// Close flushes the file to disk and closes it
+func (w *RecordWriter) Close() error {
+	if err := w.f.Sync(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+func positionData(position uint64) []byte {
+	var data [8]byte
+	binary.BigEndian.PutUint64(data[:], position)
+	return data[:]
+}
+
+// ReadRecordFile
+// call fn with every record of the file in order, decrypted with a key of keyring if the file is
+// encrypted. A partially written last record, left by a crash while appending, is ignored.
+func ReadRecordFile(name string, keyring *Keyring, fn func(record []byte) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	r := bufio.NewReader(f)
+	var aead cipher.AEAD
+	offset := int64(0)
+	for position := uint64(0); ; position++ {
+		var prefix [8]byte
+		if _, err := io.ReadFull(r, prefix[:]); err == io.EOF {
+			return nil
+		} else if err == io.ErrUnexpectedEOF {
+			log.Printf("%s: ignoring the partially written last record", name)
+			return nil
+		} else if err != nil {
+			return err
+		}
+		size := int64(binary.BigEndian.Uint32(prefix[:4]))
+		offset += int64(len(prefix)) + size
+		if offset > info.Size() {
+			log.Printf("%s: ignoring the partially written last record", name)
+			return nil
+		}
+		record := make([]byte, size)
+		if _, err := io.ReadFull(r, record); err != nil {
+			return err
+		}
+		if crc32.ChecksumIEEE(record) != binary.BigEndian.Uint32(prefix[4:]) {
+			if _, err := r.Peek(1); err == io.EOF {
+				log.Printf("%s: ignoring the partially written last record", name)
+				return nil
+			}
+			return errors.New(name + ": corrupted record")
+		}
+
+		if position == 0 {
+			var header fileHeader
+			if err := json.Unmarshal(record, &header); err != nil || header.Version != recordFileVersion {
+				return errors.New(name + ": not a record file of a known version")
+			}
+			if header.KeyID != "" {
+				if keyring == nil {
+					return errors.New(name + ": encrypted, but no key file was given")
+				}
+				if aead, err = keyring.UnwrapDataKey(header.KeyID, header.WrappedKey); err != nil {
+					return errors.New(name + ": " + err.Error())
+				}
+			}
+			continue
+		}
+		if aead != nil {
+			if record, err = Open(aead, record, positionData(position)); err != nil {
+				return errors.New(name + ": record failed authentication: " + err.Error())
+			}
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+// KeyID returns the ID of the key the record file is encrypted with, "" if it is plaintext
+func KeyID(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	var prefix [8]byte
+	if _, err := io.ReadFull(f, prefix[:]); err != nil {
+		return "", err
+	}
+	record := make([]byte, binary.BigEndian.Uint32(prefix[:4]))
+	if _, err := io.ReadFull(f, record); err != nil {
+		return "", err
+	}
+	var header fileHeader
+	if err := json.Unmarshal(record, &header); err != nil {
+		return "", err
+	}
+	return header.KeyID, nil
+}
+
+// RewriteRecordFile
+// rewrite the records of the file, reading it with from and encrypting it with a new data key
+// wrapped by the primary key of to, or in plaintext if to is nil. The file is replaced atomically.
+func RewriteRecordFile(name string, from, to *Keyring) error {
+	w, err := CreateRecordFile(name+".tmp", to, false)
+	if err != nil {
+		return err
+	}
+	err = ReadRecordFile(name, from, w.Append)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(name + ".tmp")
+		return err
+	}
+	return os.Rename(name+".tmp", name)
+}
@@ -4,17 +4,14 @@ go 1.19
 
 replace shared-registers/common => ../../shared-registers/common
 
-require (
-	shared-registers/common v1.0.0
-)
+require shared-registers/common v1.0.0
 
 require (
-	google.golang.org/grpc v1.53.0
 	github.com/golang/protobuf v1.5.2 // indirect
 	golang.org/x/net v0.5.0 // indirect
 	golang.org/x/sys v0.4.0 // indirect
 	golang.org/x/text v0.6.0 // indirect
 	google.golang.org/genproto v0.0.0-20230110181048-76db0878b65f // indirect
-	google.golang.org/protobuf v1.28.1 // indirect
-
+	google.golang.org/grpc v1.53.0
+	google.golang.org/protobuf v1.28.1
 )
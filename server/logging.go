@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"log"
+	"shared-registers/common/proto"
+	"sync/atomic"
+	"time"
+)
+
+// logLevel is the proto.LogLevel of the replica
+var logLevel int32
+
+func getLogLevel() proto.LogLevel {
+	return proto.LogLevel(atomic.LoadInt32(&logLevel))
+}
+
+// setLogLevel switches to the level and returns the previous one
+func setLogLevel(level proto.LogLevel) proto.LogLevel {
+	return proto.LogLevel(atomic.SwapInt32(&logLevel, int32(level)))
+}
+
+// logRequest logs a finished request at the DEBUG level
+func logRequest(ctx context.Context, method string, start time.Time, err error) {
+	from := "unknown peer"
+	if p, ok := peer.FromContext(ctx); ok {
+		from = p.Addr.String()
+	}
+	log.Printf("%s from %s in %v: %v", method, from, time.Since(start), status.Code(err))
+}
+
+// logUnary logs every request at the DEBUG level, including those the ACL refuses
+func logUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	if getLogLevel() < proto.LogLevel_DEBUG {
+		return handler(ctx, req)
+	}
+	start := time.Now()
+	rsp, err := handler(ctx, req)
+	logRequest(ctx, info.FullMethod, start, err)
+	return rsp, err
+}
+
+// logStream is logUnary for streaming calls, they are logged once they end
+func logStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	if getLogLevel() < proto.LogLevel_DEBUG {
+		return handler(srv, ss)
+	}
+	start := time.Now()
+	err := handler(srv, ss)
+	logRequest(ss.Context(), info.FullMethod, start, err)
+	return err
+}
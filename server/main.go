@@ -4,28 +4,101 @@ import (
 	"flag"
 	"fmt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/encoding/gzip" // lets clients compress requests, responses use the same compressor
 	"log"
 	"net"
+	"os"
+	"shared-registers/common"
 	"shared-registers/common/proto"
+	"shared-registers/server/audit"
+	"shared-registers/server/auth"
+	"shared-registers/server/crypt"
+	"shared-registers/server/store"
+	"strings"
+	"time"
 )
 
 var (
-	port = 50001
+	port = flag.Int("port", 50051, "the port to start the service")
+
+	sweepInterval = flag.Duration("sweep-interval", time.Minute, "how often expired values are replaced by tombstones and unused compare-and-swap state is dropped")
+
+	tlsCert     = flag.String("tls-cert", "", "PEM certificate chain to serve TLS with, reloaded when it changes")
+	tlsKey      = flag.String("tls-key", "", "PEM private key of -tls-cert")
+	tlsClientCA = flag.String("tls-client-ca", "", "CA bundle client certificates must be signed by (mutual TLS), requires -tls-cert")
+
+	dataDir = flag.String("data-dir", "", "directory to persist the registers in, they are only kept in memory if empty")
+	keyFile = flag.String("encryption-key-file", "", "key file to encrypt the files in -data-dir with, see common.ParseKeyFile")
+	fsync   = flag.Bool("fsync", true, "flush every stored value and audit log entry to disk before acknowledging it")
+
+	aclFile = flag.String("acl", "", "JSON file of the principals allowed to call the replica and the keys they may access, everyone may access everything if empty")
+
+	auditDir      = flag.String("audit-dir", "", "directory to keep an audit log of the writes and expiries in, encrypted with -encryption-key-file if set")
+	auditMaxSize  = flag.Int64("audit-max-size", 64<<20, "start a new audit log segment once the current one reaches this many bytes")
+	auditMaxFiles = flag.Int("audit-max-files", 0, "number of audit log segments to keep, 0 keeps all")
+	replicaName   = flag.String("name", "", "name of the replica in the audit log and the Admin service, its hostname and port by default")
+
+	level = flag.String("log-level", "info", "info, or debug to also log every request, changed with the Admin service")
 )
 
-func parseArgs() {
-	defer flag.Parse()
-	port = *flag.Int("port", 50051, "the port to start the service")
-	flag.Parse()
-}
 func main() {
-	parseArgs()
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	started := time.Now()
+	flag.Parse()
+	if l, ok := proto.LogLevel_value[strings.ToUpper(*level)]; ok {
+		setLogLevel(proto.LogLevel(l))
+	} else {
+		log.Fatalf("unknown log level %q", *level)
+	}
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
-	s := grpc.NewServer()
-	proto.RegisterSharedRegistersServer(s, &server{})
+	var keyring *crypt.Keyring
+	if *keyFile != "" {
+		if *dataDir == "" && *auditDir == "" {
+			log.Fatal("-encryption-key-file requires -data-dir or -audit-dir")
+		}
+		if keyring, err = crypt.LoadKeyring(*keyFile); err != nil {
+			log.Fatalf("failed to load the encryption keys: %v", err)
+		}
+	}
+	if *dataDir != "" {
+		if err := store.Open(*dataDir, keyring, *fsync); err != nil {
+			log.Fatalf("failed to open the data directory: %v", err)
+		}
+	}
+	var opts []grpc.ServerOption
+	if *tlsCert != "" || *tlsClientCA != "" {
+		config, err := common.ServerTLSConfig(common.TLSFiles{CertFile: *tlsCert, KeyFile: *tlsKey, CAFile: *tlsClientCA})
+		if err != nil {
+			log.Fatalf("failed to load TLS certificates: %v", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(config)))
+	}
+	handler := &server{}
+	name := *replicaName
+	if name == "" {
+		hostname, _ := os.Hostname()
+		name = fmt.Sprintf("%s:%d", hostname, *port)
+	}
+	if *auditDir != "" {
+		if handler.auditLog, err = audit.Open(*auditDir, keyring, *fsync, name, *auditMaxSize, *auditMaxFiles); err != nil {
+			log.Fatalf("failed to open the audit log: %v", err)
+		}
+	}
+	store.StartSweeper(*sweepInterval, handler.auditExpiry)
+	unary, stream := []grpc.UnaryServerInterceptor{logUnary}, []grpc.StreamServerInterceptor{logStream}
+	if *aclFile != "" {
+		if handler.acl, err = auth.Load(*aclFile); err != nil {
+			log.Fatalf("failed to load the ACL: %v", err)
+		}
+		unary, stream = append(unary, handler.acl.UnaryInterceptor), append(stream, handler.acl.StreamInterceptor)
+	}
+	opts = append(opts, grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
+	s := grpc.NewServer(opts...)
+	proto.RegisterSharedRegistersServer(s, handler)
+	proto.RegisterAdminServer(s, &admin{replica: handler, name: name, started: started})
 	log.Printf("server listening at %v", lis.Addr())
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v", err)
@@ -2,26 +2,44 @@ package main
 
 import (
 	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"log"
 	"shared-registers/common"
 	"shared-registers/common/proto"
+	"shared-registers/server/audit"
+	"shared-registers/server/auth"
 	"shared-registers/server/store"
+	"time"
 )
 
 type server struct {
 	proto.UnimplementedSharedRegistersServer
+	acl      *auth.ACL  // nil if callers aren't authenticated
+	auditLog *audit.Log // nil if SetPhases aren't audited
+	mode     replicaMode
 }
 
 // GetPhase
 // return to client with the <value, ts> associated with the key to let client decide whether which
 // replica has the updated value
 func (s *server) GetPhase(ctx context.Context, in *proto.GetPhaseReq) (*proto.GetPhaseRsp, error) {
+	if err := s.checkMode(false); err != nil {
+		return nil, err
+	}
 	//log.Printf("GetPhase Received: %v", in)
+	if err := s.checkRead(ctx, in.GetKey()); err != nil {
+		return nil, err
+	}
 	v, err := store.Get(in.GetKey())
 	if err != nil {
 		log.Printf("GetPhase err: %v", err)
 		return nil, err
 	}
+	if v != nil {
+		v = common.Visible(v, time.Now())
+	}
 	return &proto.GetPhaseRsp{Value: v}, nil
 }
 
@@ -30,14 +48,264 @@ func (s *server) GetPhase(ctx context.Context, in *proto.GetPhaseReq) (*proto.Ge
 // If yes, replica stores v, ts-new.
 // In either case, the storage nodes sends an acknowledgement to the client.
 func (s *server) SetPhase(ctx context.Context, in *proto.SetPhaseReq) (*proto.SetPhaseRsp, error) {
+	if err := s.checkMode(true); err != nil {
+		return nil, err
+	}
 	//log.Printf("SetPhase Received: %v", in)
 	newTs := in.GetValue().GetTs()
-	currValue, err := store.Get(in.GetKey())
+	if err := s.checkWrittenValue(ctx, in.GetKey(), newTs); err != nil {
+		// readers that may not write still write back what the replica already has, only acknowledge
+		currValue, getErr := store.Get(in.GetKey())
+		if getErr != nil {
+			return nil, getErr
+		}
+		if currValue == nil || common.CompareTimeStamps(currValue.Ts, newTs) < 0 || s.checkRead(ctx, in.GetKey()) != nil {
+			s.audit(ctx, in.GetKey(), in.GetValue(), audit.Denied)
+			return nil, err
+		}
+		if common.CompareTimeStamps(currValue.Ts, newTs) > 0 {
+			if err := s.audit(ctx, in.GetKey(), in.GetValue(), audit.Stale); err != nil {
+				return nil, err
+			}
+		}
+		return &proto.SetPhaseRsp{}, nil
+	}
+	// the checks see the value the write replaces, a concurrent write can't slip in between
+	_, err := store.SetIfNewer(in.GetKey(), in.GetValue(), func(currValue *proto.StoredValue, newer bool) error {
+		// a client that never leased its ClientID or lost the lease may not write under it while another
+		// client holds it, write-backs of values of other clients or of the stored value aren't affected
+		if in.GetClientID() == newTs.GetClientID() && common.CompareTimeStamps(currValue.GetTs(), newTs) != 0 {
+			if err := store.CheckClientLease(newTs.GetClientID(), in.GetToken()); err != nil {
+				s.audit(ctx, in.GetKey(), in.GetValue(), audit.Denied)
+				return status.Error(codes.AlreadyExists, err.Error())
+			}
+		}
+		// two clients sharing a clientID may produce the same timestamp for different values, storing
+		// either of them silently lets the replicas diverge
+		if currValue != nil && common.CompareTimeStamps(currValue.Ts, newTs) == 0 && !common.SameWrite(currValue, in.GetValue()) {
+			return status.Errorf(codes.FailedPrecondition, "conflicting value for timestamp <%d, %s> of key %s",
+				newTs.GetRequestNumber(), newTs.GetClientID(), in.GetKey())
+		}
+		// a write back of the stored value itself changes nothing and isn't audited
+		if newer {
+			return s.audit(ctx, in.GetKey(), in.GetValue(), audit.Accepted)
+		}
+		if common.CompareTimeStamps(currValue.Ts, newTs) > 0 {
+			return s.audit(ctx, in.GetKey(), in.GetValue(), audit.Stale)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	if currValue == nil || common.FindLargestTimeStamp(currValue.Ts, newTs) == newTs {
-		store.Set(in.GetKey(), in.GetValue())
-	}
 	return &proto.SetPhaseRsp{}, nil
 }
+
+// RegisterClient
+// grant, renew or release the lease on a clientID, a clientID leased to another client is rejected
+func (s *server) RegisterClient(ctx context.Context, in *proto.RegisterClientReq) (*proto.RegisterClientRsp, error) {
+	if err := s.checkMode(true); err != nil {
+		return nil, err
+	}
+	if in.GetClientID() == "" || in.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "RegisterClient: missing client ID or token")
+	}
+	if err := s.checkClientID(ctx, &proto.TimeStamp{ClientID: in.GetClientID()}); err != nil {
+		return nil, err
+	}
+	lease := time.Duration(in.GetLeaseMillis()) * time.Millisecond
+	if err := store.RegisterClient(in.GetClientID(), in.GetToken(), lease); err != nil {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	return &proto.RegisterClientRsp{}, nil
+}
+
+// Scan
+// return the <value, ts> pairs of a key range in key order, the client merges the answers of a quorum
+func (s *server) Scan(ctx context.Context, in *proto.ScanReq) (*proto.ScanRsp, error) {
+	if err := s.checkMode(false); err != nil {
+		return nil, err
+	}
+	// keys the caller may not read are left out, as if they didn't exist
+	readable := func(key string) bool {
+		return s.checkRead(ctx, key) == nil
+	}
+	entries, more := store.Scan(in.GetStart(), in.GetEnd(), int(in.GetLimit()), readable)
+	now := time.Now()
+	for i, e := range entries {
+		if common.Expired(e.GetValue(), now) {
+			entries[i] = &proto.KeyValue{Key: e.GetKey(), Value: common.Visible(e.GetValue(), now)}
+		}
+	}
+	return &proto.ScanRsp{Entries: entries, More: more}, nil
+}
+
+// Watch
+// send the current values of the key (or prefix) that are newer than fromTs, then every newer value
+// this replica stores until the client cancels. The subscription starts before the current values
+// are read, so no write in between is missed, the client drops the duplicates.
+func (s *server) Watch(in *proto.WatchReq, stream proto.SharedRegisters_WatchServer) error {
+	if err := s.checkMode(false); err != nil {
+		return err
+	}
+	_, draining := s.mode.get()
+	if !in.GetPrefix() {
+		if err := s.checkRead(stream.Context(), in.GetKey()); err != nil {
+			return err
+		}
+	} else if _, err := s.caller(stream.Context()); err != nil {
+		return err
+	}
+	updates, dropped, cancel := store.Subscribe(in.GetKey(), in.GetPrefix())
+	defer cancel()
+	send := func(kv *proto.KeyValue) error {
+		if in.GetFromTs() != nil && common.CompareTimeStamps(kv.GetValue().GetTs(), in.GetFromTs()) <= 0 ||
+			s.checkRead(stream.Context(), kv.GetKey()) != nil {
+			return nil
+		}
+		return stream.Send(&proto.KeyValue{Key: kv.GetKey(), Value: common.Visible(kv.GetValue(), time.Now())})
+	}
+
+	if in.GetPrefix() {
+		start, end := in.GetKey(), common.PrefixEnd(in.GetKey())
+		for more := true; more; {
+			var entries []*proto.KeyValue
+			entries, more = store.Scan(start, end, store.MaxScanLimit, nil)
+			for _, kv := range entries {
+				if err := send(kv); err != nil {
+					return err
+				}
+			}
+			if len(entries) > 0 {
+				start = entries[len(entries)-1].GetKey() + "\x00"
+			}
+		}
+	} else if v, _ := store.Get(in.GetKey()); v != nil {
+		if err := send(&proto.KeyValue{Key: in.GetKey(), Value: v}); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case kv := <-updates:
+			if err := send(kv); err != nil {
+				return err
+			}
+		case <-dropped:
+			return status.Error(codes.ResourceExhausted, "Watch: the watcher fell behind, reconnect to catch up")
+		case <-draining:
+			return status.Error(codes.Unavailable, "Watch: replica is draining")
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// PaxosPrepare
+// phase 1 of the compare-and-swap instance replacing in.Version, see store.PaxosPrepare
+func (s *server) PaxosPrepare(ctx context.Context, in *proto.PaxosPrepareReq) (*proto.PaxosPrepareRsp, error) {
+	if err := s.checkMode(true); err != nil {
+		return nil, err
+	}
+	if err := s.checkWrite(ctx, in.GetKey()); err != nil {
+		return nil, err
+	}
+	if err := s.checkClientID(ctx, in.GetBallot()); err != nil {
+		return nil, err
+	}
+	return store.PaxosPrepare(in.GetKey(), in.GetVersion(), in.GetBallot()), nil
+}
+
+// PaxosAccept
+// phase 2 of the compare-and-swap instance replacing in.Version, see store.PaxosAccept
+func (s *server) PaxosAccept(ctx context.Context, in *proto.PaxosAcceptReq) (*proto.PaxosAcceptRsp, error) {
+	if err := s.checkMode(true); err != nil {
+		return nil, err
+	}
+	if err := s.checkClientID(ctx, in.GetBallot()); err != nil {
+		return nil, err
+	}
+	if err := s.checkWrittenValue(ctx, in.GetKey(), in.GetValue().GetTs()); err != nil {
+		return nil, err
+	}
+	return store.PaxosAccept(in.GetKey(), in.GetVersion(), in.GetBallot(), in.GetValue()), nil
+}
+
+// CodedQuery
+// return the largest finalized timestamp of the erasure-coded register
+func (s *server) CodedQuery(ctx context.Context, in *proto.CodedQueryReq) (*proto.CodedQueryRsp, error) {
+	if err := s.checkMode(false); err != nil {
+		return nil, err
+	}
+	if err := s.checkRead(ctx, in.GetKey()); err != nil {
+		return nil, err
+	}
+	ts, meta := store.CodedQuery(in.GetKey())
+	return &proto.CodedQueryRsp{Ts: ts, Meta: meta}, nil
+}
+
+// CodedPreWrite
+// store the coded fragment of the writer with the pre label, it is not visible to readers until
+// the writer finalizes its timestamp
+func (s *server) CodedPreWrite(ctx context.Context, in *proto.CodedPreWriteReq) (*proto.CodedPreWriteRsp, error) {
+	if err := s.checkMode(true); err != nil {
+		return nil, err
+	}
+	if in.GetTs() == nil || in.GetFragment() == nil {
+		return nil, errors.New("CodedPreWrite: missing timestamp or fragment")
+	}
+	err := s.checkWrite(ctx, in.GetKey())
+	if err == nil {
+		err = s.checkClientID(ctx, in.GetTs())
+	}
+	if err != nil {
+		s.auditCoded(ctx, in.GetKey(), in.GetTs(), in.GetFragment(), audit.Denied)
+		return nil, err
+	}
+	outcome := audit.PreWritten
+	if stale, _ := store.CodedState(in.GetKey(), in.GetTs()); stale {
+		outcome = audit.Stale
+	}
+	if err := s.auditCoded(ctx, in.GetKey(), in.GetTs(), in.GetFragment(), outcome); err != nil {
+		return nil, err
+	}
+	store.CodedPreWrite(in.GetKey(), in.GetTs(), in.GetFragment())
+	return &proto.CodedPreWriteRsp{}, nil
+}
+
+// CodedFinalize
+// label the timestamp as finalized, readers additionally get the fragment of the timestamp back
+// if the replica holds it
+func (s *server) CodedFinalize(ctx context.Context, in *proto.CodedFinalizeReq) (*proto.CodedFinalizeRsp, error) {
+	if err := s.checkMode(true); err != nil {
+		return nil, err
+	}
+	if in.GetTs() == nil {
+		return nil, errors.New("CodedFinalize: missing timestamp")
+	}
+	// readers finalize the timestamps they read like writers do, but only of clients that may write
+	err := s.checkRead(ctx, in.GetKey())
+	if err == nil && s.acl != nil && !s.acl.ClientIDWrites(in.GetTs().GetClientID(), in.GetKey()) {
+		err = status.Errorf(codes.PermissionDenied, "client ID %q may not write key %q", in.GetTs().GetClientID(), in.GetKey())
+	}
+	if err != nil {
+		s.auditCoded(ctx, in.GetKey(), in.GetTs(), nil, audit.Denied)
+		return nil, err
+	}
+	// finalizing a finalized timestamp again, as readers do, changes nothing and isn't audited
+	if stale, finalized := store.CodedState(in.GetKey(), in.GetTs()); !finalized {
+		outcome := audit.Finalized
+		if stale {
+			outcome = audit.Stale
+		}
+		if err := s.auditCoded(ctx, in.GetKey(), in.GetTs(), nil, outcome); err != nil {
+			return nil, err
+		}
+	}
+	fragment := store.CodedFinalize(in.GetKey(), in.GetTs())
+	if !in.GetWantFragment() {
+		return &proto.CodedFinalizeRsp{}, nil
+	}
+	return &proto.CodedFinalizeRsp{Fragment: fragment}, nil
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrClientIDInUse = errors.New("client ID is registered by another client")
+
+type clientLease struct {
+	token   string
+	expires time.Time
+}
+
+var (
+	clientsLock sync.Mutex
+	clients     = map[string]*clientLease{}
+)
+
+// RegisterClient
+// grant or renew the lease of clientID to the client presenting token, fails with ErrClientIDInUse
+// while another token holds an unexpired lease, a lease of 0 releases the registration
+func RegisterClient(clientID, token string, lease time.Duration) error {
+	clientsLock.Lock()
+	defer clientsLock.Unlock()
+	now := time.Now()
+	if curr, ok := clients[clientID]; ok && curr.token != token && now.Before(curr.expires) {
+		return ErrClientIDInUse
+	}
+	if lease == 0 {
+		delete(clients, clientID)
+		return nil
+	}
+	clients[clientID] = &clientLease{token: token, expires: now.Add(lease)}
+	// drop expired leases so the map doesn't grow with every client that ever registered
+	for id, l := range clients {
+		if now.After(l.expires) {
+			delete(clients, id)
+		}
+	}
+	return nil
+}
+
+// CheckClientLease fails with ErrClientIDInUse while a client not presenting token holds an unexpired lease of clientID
+func CheckClientLease(clientID, token string) error {
+	clientsLock.Lock()
+	defer clientsLock.Unlock()
+	if curr, ok := clients[clientID]; ok && curr.token != token && time.Now().Before(curr.expires) {
+		return ErrClientIDInUse
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package store
+
+import (
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"sync"
+)
+
+// codedHistory is the number of finalized versions whose fragments are kept per key, older
+// fragments are dropped (CASGC) and readers still looking for them retry with a newer timestamp
+const codedHistory = 2
+
+// codedEntry is one <ts, fragment, label> triple of the CAS algorithm, the fragment may be nil
+// when the replica learned about the finalized ts before receiving the pre-write or after gc
+type codedEntry struct {
+	ts        *proto.TimeStamp
+	fragment  *proto.CodedFragment
+	finalized bool
+}
+
+type codedRegister struct {
+	mu      sync.Mutex
+	entries []*codedEntry // sorted by ts ascending
+}
+
+var coded = sync.Map{} // key -> *codedRegister
+
+func getCodedRegister(key string) *codedRegister {
+	r, _ := coded.LoadOrStore(key, &codedRegister{})
+	return r.(*codedRegister)
+}
+
+// find returns the entry of ts, inserting a new one in order if the replica hasn't seen ts yet
+func (r *codedRegister) find(ts *proto.TimeStamp) *codedEntry {
+	i := len(r.entries)
+	for i > 0 && common.CompareTimeStamps(r.entries[i-1].ts, ts) >= 0 {
+		i--
+		if common.CompareTimeStamps(r.entries[i].ts, ts) == 0 {
+			return r.entries[i]
+		}
+	}
+	e := &codedEntry{ts: ts}
+	r.entries = append(r.entries, nil)
+	copy(r.entries[i+1:], r.entries[i:])
+	r.entries[i] = e
+	return e
+}
+
+// gc drops every entry older than the codedHistory-th largest finalized ts
+func (r *codedRegister) gc() {
+	finalized := 0
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		if !r.entries[i].finalized {
+			continue
+		}
+		finalized++
+		if finalized == codedHistory {
+			r.entries = r.entries[i:]
+			return
+		}
+	}
+}
+
+// CodedQuery
+// return the largest finalized timestamp of the key, nil if there is none, and the meta of its
+// fragment if the replica holds it, which tells writers e.g. the chunk manifest they replace
+func CodedQuery(key string) (*proto.TimeStamp, *proto.StoredValue) {
+	v, ok := coded.Load(key)
+	if !ok {
+		return nil, nil
+	}
+	r := v.(*codedRegister)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		if r.entries[i].finalized {
+			return r.entries[i].ts, r.entries[i].fragment.GetMeta()
+		}
+	}
+	return nil, nil
+}
+
+// CodedState
+// tell whether ts is stale for the key, older than every timestamp the replica keeps, and whether
+// the replica finalized it already
+func CodedState(key string, ts *proto.TimeStamp) (stale, finalized bool) {
+	v, ok := coded.Load(key)
+	if !ok {
+		return false, false
+	}
+	r := v.(*codedRegister)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) > 0 && common.CompareTimeStamps(ts, r.entries[0].ts) < 0 {
+		return true, false
+	}
+	for _, e := range r.entries {
+		if common.CompareTimeStamps(e.ts, ts) == 0 {
+			return false, e.finalized
+		}
+	}
+	return false, false
+}
+
+// CodedPreWrite stores the fragment of ts with the pre label if the replica doesn't know ts yet
+func CodedPreWrite(key string, ts *proto.TimeStamp, fragment *proto.CodedFragment) {
+	r := getCodedRegister(key)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// the fragment is useless if it is already older than what gc keeps
+	if len(r.entries) > 0 && common.CompareTimeStamps(ts, r.entries[0].ts) < 0 {
+		return
+	}
+	e := r.find(ts)
+	if e.fragment == nil {
+		e.fragment = fragment
+	}
+}
+
+// CodedFinalize labels ts as finalized and returns the fragment of ts held by the replica, if any
+func CodedFinalize(key string, ts *proto.TimeStamp) *proto.CodedFragment {
+	r := getCodedRegister(key)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) > 0 && common.CompareTimeStamps(ts, r.entries[0].ts) < 0 {
+		return nil
+	}
+	e := r.find(ts)
+	e.finalized = true
+	r.gc()
+	return e.fragment
+}
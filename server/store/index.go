@@ -0,0 +1,107 @@
+package store
+
+import (
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxScanLimit caps the number of entries a single Scan returns
+const MaxScanLimit = 1000
+
+// keys of s in sorted order, keys are never removed since a delete is a tombstone value. Inserting
+// a new key is O(n), but only the first write of a key inserts.
+var (
+	indexLock sync.RWMutex
+	index     []string
+)
+
+func addToIndex(key string) {
+	indexLock.Lock()
+	defer indexLock.Unlock()
+	i := sort.SearchStrings(index, key)
+	if i < len(index) && index[i] == key {
+		return
+	}
+	index = append(index, "")
+	copy(index[i+1:], index[i:])
+	index[i] = key
+}
+
+// Scan
+// return up to limit entries with start <= key < end in key order, end "" means no upper bound, and
+// whether the range has more keys. Tombstones are included so that clients can tell a deleted key
+// from one this replica missed, internal keys and keys visible rejects (if not nil) are skipped
+// before the limit applies, so a page is only short at the end of the range.
+func Scan(start, end string, limit int, visible func(key string) bool) ([]*proto.KeyValue, bool) {
+	if limit <= 0 || limit > MaxScanLimit {
+		limit = MaxScanLimit
+	}
+	indexLock.RLock()
+	defer indexLock.RUnlock()
+	entries := make([]*proto.KeyValue, 0)
+	for i := sort.SearchStrings(index, start); i < len(index); i++ {
+		key := index[i]
+		if end != "" && key >= end {
+			break
+		}
+		if common.IsInternalKey(key) || visible != nil && !visible(key) {
+			continue
+		}
+		if len(entries) == limit {
+			return entries, true
+		}
+		v, ok := s.Load(key)
+		if !ok {
+			continue
+		}
+		entries = append(entries, &proto.KeyValue{Key: key, Value: v.(*proto.StoredValue)})
+	}
+	return entries, false
+}
+
+// Keys
+// return up to limit keys starting with prefix and coming after startAfter in key order, and whether
+// the prefix has more keys. Internal keys are only listed with internal.
+func Keys(prefix, startAfter string, limit int, internal bool) ([]string, bool) {
+	if limit <= 0 || limit > MaxScanLimit {
+		limit = MaxScanLimit
+	}
+	indexLock.RLock()
+	defer indexLock.RUnlock()
+	keys := make([]string, 0)
+	i := sort.SearchStrings(index, prefix)
+	if startAfter >= prefix {
+		i = sort.SearchStrings(index, startAfter+"\x00")
+	}
+	for ; i < len(index) && strings.HasPrefix(index[i], prefix); i++ {
+		if !internal && common.IsInternalKey(index[i]) {
+			continue
+		}
+		if len(keys) == limit {
+			return keys, true
+		}
+		keys = append(keys, index[i])
+	}
+	return keys, false
+}
+
+// Count returns the number of keys starting with prefix, how many of them are tombstones or expired
+// at now, and how many are internal keys
+func Count(prefix string, now time.Time) (keys, deleted, internal int) {
+	indexLock.RLock()
+	defer indexLock.RUnlock()
+	for i := sort.SearchStrings(index, prefix); i < len(index) && strings.HasPrefix(index[i], prefix); i++ {
+		keys++
+		if common.IsInternalKey(index[i]) {
+			internal++
+		}
+		if v, ok := s.Load(index[i]); ok && common.Visible(v.(*proto.StoredValue), now).GetDeleted() {
+			deleted++
+		}
+	}
+	return keys, deleted, internal
+}
@@ -1,12 +1,16 @@
 package store
 
 import (
+	"shared-registers/common"
 	"shared-registers/common/proto"
 	"sync"
 )
 
 var s = sync.Map{} // use concurrentMap for simplicity first
 
+// writeLock orders Set against SetIfNewer and the sweeper, reads don't take it
+var writeLock sync.Mutex
+
 func Get(key string) (*proto.StoredValue, error) {
 	v, ok := s.Load(key)
 	if !ok {
@@ -17,6 +21,40 @@ func Get(key string) (*proto.StoredValue, error) {
 }
 
 func Set(key string, value *proto.StoredValue) {
-	s.Store(key, value)
+	writeLock.Lock()
+	defer writeLock.Unlock()
+	set(key, value)
 	//log.Printf("Stored %s %v\n", key, value)
 }
+
+// SetIfNewer
+// replace the value of the key only if value has a newer timestamp, so a write can't roll the key
+// back to an older value. check is called first under the lock with the stored value (nil if there
+// is none) and whether value replaces it; an error keeps the key as is and is returned. Returns
+// whether value was stored.
+func SetIfNewer(key string, value *proto.StoredValue, check func(current *proto.StoredValue, newer bool) error) (bool, error) {
+	writeLock.Lock()
+	defer writeLock.Unlock()
+	current, _ := Get(key)
+	newer := current == nil || common.CompareTimeStamps(current.GetTs(), value.GetTs()) < 0
+	if check != nil {
+		if err := check(current, newer); err != nil {
+			return false, err
+		}
+	}
+	if !newer {
+		return false, nil
+	}
+	set(key, value)
+	return true, nil
+}
+
+func set(key string, value *proto.StoredValue) {
+	persist(key, value)
+	if _, loaded := s.LoadOrStore(key, value); loaded {
+		s.Store(key, value)
+	} else {
+		addToIndex(key)
+	}
+	notify(key, value)
+}
@@ -0,0 +1,175 @@
+package store
+
+import (
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"time"
+)
+
+// paxosHistory is the number of compare-and-swap instances whose acceptor state is kept per key, so
+// that a proposer preempted in an instance can still learn its outcome after newer ones started
+const paxosHistory = 8
+
+// PaxosRetention is how long the acceptor state of a key outlives its last use once the key stores a
+// newer value than every instance, so that preempted proposers still learn the outcome
+const PaxosRetention = time.Minute
+
+// paxosInstance is the acceptor state of one compare-and-swap instance, identified by the version
+// (timestamp) of the value it replaces
+type paxosInstance struct {
+	version        *proto.TimeStamp
+	promised       *proto.TimeStamp
+	acceptedBallot *proto.TimeStamp
+	acceptedValue  *proto.StoredValue
+}
+
+type paxosLog struct {
+	instances []*paxosInstance // sorted by version ascending
+	floor     *proto.TimeStamp // largest version whose state was dropped
+	used      time.Time        // of the last prepare or accept, or of loading the log
+}
+
+// paxos is the acceptor state of every key, guarded by writeLock since it is logged like the values
+var paxos = make(map[string]*paxosLog)
+
+// find returns the instance of version, -1 and the position to insert it at if there is none
+func (l *paxosLog) find(version *proto.TimeStamp) (*paxosInstance, int) {
+	i := len(l.instances)
+	for i > 0 && common.CompareTimeStamps(l.instances[i-1].version, version) >= 0 {
+		i--
+		if common.CompareTimeStamps(l.instances[i].version, version) == 0 {
+			return l.instances[i], -1
+		}
+	}
+	return nil, i
+}
+
+// insert adds the instance at position i, dropping the oldest one beyond paxosHistory
+func (l *paxosLog) insert(in *paxosInstance, i int) {
+	l.instances = append(l.instances, nil)
+	copy(l.instances[i+1:], l.instances[i:])
+	l.instances[i] = in
+	if len(l.instances) > paxosHistory {
+		l.floor = l.instances[0].version
+		l.instances = l.instances[1:]
+	}
+}
+
+// instance
+// return the acceptor state of the key's instance for version, nil if the instance is stale: its
+// state was dropped, or the replica never took part in it and already stores a newer value, so it
+// would start an instance nobody can apply anymore. The caller holds writeLock.
+func instance(key string, version *proto.TimeStamp) (*paxosLog, *paxosInstance) {
+	l := paxos[key]
+	if l == nil {
+		l = &paxosLog{}
+	}
+	l.used = time.Now()
+	if l.floor != nil && common.CompareTimeStamps(version, l.floor) <= 0 {
+		return l, nil
+	}
+	in, i := l.find(version)
+	if in != nil {
+		return l, in
+	}
+	if current, _ := Get(key); current != nil && common.CompareTimeStamps(current.GetTs(), version) > 0 {
+		return l, nil
+	}
+	paxos[key] = l
+	in = &paxosInstance{version: version}
+	l.insert(in, i)
+	return l, in
+}
+
+// state is the record logging the instance
+func (in *paxosInstance) state(l *paxosLog) *proto.PaxosState {
+	return &proto.PaxosState{Version: in.version, Promised: in.promised, AcceptedBallot: in.acceptedBallot,
+		AcceptedValue: in.acceptedValue, Floor: l.floor}
+}
+
+// restorePaxos applies the logged acceptor state of an instance of the key, the caller holds writeLock
+func restorePaxos(key string, state *proto.PaxosState) {
+	l := paxos[key]
+	if l == nil {
+		l = &paxosLog{}
+		paxos[key] = l
+	}
+	l.used = time.Now()
+	if common.CompareTimeStamps(state.GetFloor(), l.floor) > 0 {
+		l.floor = state.GetFloor()
+		for len(l.instances) > 0 && common.CompareTimeStamps(l.instances[0].version, l.floor) <= 0 {
+			l.instances = l.instances[1:]
+		}
+	}
+	if l.floor != nil && common.CompareTimeStamps(state.GetVersion(), l.floor) <= 0 {
+		return
+	}
+	in, i := l.find(state.GetVersion())
+	if in == nil {
+		in = &paxosInstance{version: state.GetVersion()}
+		l.insert(in, i)
+	}
+	in.promised, in.acceptedBallot, in.acceptedValue = state.GetPromised(), state.GetAcceptedBallot(), state.GetAcceptedValue()
+}
+
+// PrunePaxos
+// drop the acceptor state of the keys that weren't used since before and store a value newer than
+// every instance and every value accepted in them: no instance can be applied anymore, and a
+// proposer still taking part in one learns that it is stale and reads the stored value. Dropped
+// state leaves the data directory with the next compaction. Returns the number of keys dropped.
+func PrunePaxos(before time.Time) int {
+	writeLock.Lock()
+	defer writeLock.Unlock()
+	pruned := 0
+	for key, l := range paxos {
+		current, _ := Get(key)
+		if current == nil || l.used.After(before) {
+			continue
+		}
+		newer := true
+		for _, in := range l.instances {
+			newer = newer && common.CompareTimeStamps(in.version, current.GetTs()) < 0 &&
+				common.CompareTimeStamps(in.acceptedValue.GetTs(), current.GetTs()) <= 0
+		}
+		if newer {
+			delete(paxos, key)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// PaxosPrepare
+// promise not to accept ballots below ballot in the instance and return what it accepted. The
+// promise is logged before it is returned, so a restarted replica keeps it.
+func PaxosPrepare(key string, version, ballot *proto.TimeStamp) *proto.PaxosPrepareRsp {
+	writeLock.Lock()
+	defer writeLock.Unlock()
+	l, in := instance(key, version)
+	if in == nil {
+		return &proto.PaxosPrepareRsp{Stale: true}
+	}
+	if common.CompareTimeStamps(ballot, in.promised) <= 0 {
+		return &proto.PaxosPrepareRsp{Promised: in.promised}
+	}
+	in.promised = ballot
+	logRecord(&proto.LogRecord{Key: key, Paxos: in.state(l)})
+	return &proto.PaxosPrepareRsp{Ok: true, Promised: in.promised, AcceptedBallot: in.acceptedBallot,
+		AcceptedValue: in.acceptedValue}
+}
+
+// PaxosAccept accepts the value unless a larger ballot was promised in the instance, logging it first
+func PaxosAccept(key string, version, ballot *proto.TimeStamp, value *proto.StoredValue) *proto.PaxosAcceptRsp {
+	writeLock.Lock()
+	defer writeLock.Unlock()
+	l, in := instance(key, version)
+	if in == nil {
+		return &proto.PaxosAcceptRsp{Stale: true}
+	}
+	if common.CompareTimeStamps(ballot, in.promised) < 0 {
+		return &proto.PaxosAcceptRsp{Promised: in.promised}
+	}
+	in.promised, in.acceptedBallot, in.acceptedValue = ballot, ballot, value
+	logRecord(&proto.LogRecord{Key: key, Paxos: in.state(l)})
+	return &proto.PaxosAcceptRsp{Ok: true, Promised: in.promised}
+}
@@ -0,0 +1,204 @@
+package store
+
+import (
+	"errors"
+	protobuf "google.golang.org/protobuf/proto"
+	"log"
+	"os"
+	"path/filepath"
+	"shared-registers/common/proto"
+	"shared-registers/server/crypt"
+)
+
+// names of the record files in the data directory, the snapshot holds every register and the
+// compare-and-swap acceptor state as of the start of the log, which holds every change since
+const (
+	SnapshotFile = "registers.snapshot"
+	LogFile      = "registers.log"
+)
+
+// minCompaction is the least number of log records compaction waits for, it also waits until the log
+// is twice as long as the snapshot so it takes amortized constant time per write
+const minCompaction = 10000
+
+var ErrNotPersisted = errors.New("the registers aren't persisted")
+
+var (
+	// persistence, guarded by writeLock
+	dataDir   string
+	keyring   *crypt.Keyring
+	syncLog   bool
+	walLog    *crypt.RecordWriter
+	snapshot  uint64 // number of records in the snapshot
+	registers uint64 // number of registers in the snapshot
+)
+
+// Open
+// persist the registers in dir: load the values stored there, and log every value stored from now
+// on before Set returns. Records are encrypted with data keys wrapped by the primary key of keys, or
+// stored in plaintext if keys is nil; a replica with keys refuses plaintext files, which can be
+// encrypted with the reencrypt tool. With sync, each record is flushed to disk before it counts as
+// stored. The compare-and-swap acceptor state is logged the same way, erasure-coded fragments
+// aren't persisted.
+func Open(dir string, keys *crypt.Keyring, sync bool) error {
+	writeLock.Lock()
+	defer writeLock.Unlock()
+	if walLog != nil {
+		return errors.New("store already persisted")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	for _, name := range []string{SnapshotFile, LogFile} {
+		path := filepath.Join(dir, name)
+		if keys != nil {
+			if keyID, err := crypt.KeyID(path); err == nil && keyID == "" {
+				return errors.New(path + " isn't encrypted, encrypt it with the reencrypt tool first")
+			}
+		}
+		err := crypt.ReadRecordFile(path, keys, func(record []byte) error {
+			r := &proto.LogRecord{}
+			if err := protobuf.Unmarshal(record, r); err != nil {
+				return err
+			}
+			if r.GetPaxos() != nil {
+				restorePaxos(r.GetKey(), r.GetPaxos())
+			} else {
+				set(r.GetKey(), r.GetValue())
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	dataDir, keyring, syncLog = dir, keys, sync
+	// start over with the current key and without the records replaced since
+	return compact()
+}
+
+// Close stops persisting the registers and closes the log
+func Close() error {
+	writeLock.Lock()
+	defer writeLock.Unlock()
+	if walLog == nil {
+		return nil
+	}
+	err := walLog.Close()
+	walLog = nil
+	return err
+}
+
+// Compact writes a snapshot of the registers and starts a new log right away, returning the number of
+// registers in the snapshot
+func Compact() (uint64, error) {
+	writeLock.Lock()
+	defer writeLock.Unlock()
+	if walLog == nil {
+		return 0, ErrNotPersisted
+	}
+	if err := compact(); err != nil {
+		if walLog == nil {
+			// the values stored from now on couldn't be logged
+			log.Fatalf("failed to compact the log: %v", err)
+		}
+		return 0, err
+	}
+	return registers, nil
+}
+
+// Persistence returns the data directory, empty if the registers aren't persisted, and the number of
+// records in the snapshot and in the log
+func Persistence() (dir string, snapshotRecords, logRecords uint64) {
+	writeLock.Lock()
+	defer writeLock.Unlock()
+	if walLog == nil {
+		return "", 0, 0
+	}
+	return dataDir, snapshot, walLog.Size()
+}
+
+// persist logs the value stored under the key, the caller holds writeLock
+func persist(key string, value *proto.StoredValue) {
+	logRecord(&proto.LogRecord{Key: key, Value: value})
+}
+
+// logRecord appends the record to the log, the caller holds writeLock
+func logRecord(r *proto.LogRecord) {
+	if walLog == nil {
+		return
+	}
+	record, err := protobuf.Marshal(r)
+	if err == nil {
+		err = walLog.Append(record)
+	}
+	if err != nil {
+		// acknowledging writes or promises that aren't durable would break the quorums, stop instead
+		log.Fatalf("failed to log a record of %q: %v", r.GetKey(), err)
+	}
+	if walLog.Size() >= minCompaction && walLog.Size() >= 2*snapshot {
+		if err := compact(); err != nil {
+			log.Fatalf("failed to compact the log: %v", err)
+		}
+	}
+}
+
+// compact
+// write a snapshot of the registers and start a new log. The snapshot replaces the old one
+// atomically before the log is truncated, so a crash in between replays values the snapshot
+// already has. The caller holds writeLock.
+func compact() error {
+	path := filepath.Join(dataDir, SnapshotFile)
+	w, err := crypt.CreateRecordFile(path+".tmp", keyring, false)
+	if err != nil {
+		return err
+	}
+	appendRecord := func(r *proto.LogRecord) {
+		var record []byte
+		record, err = protobuf.Marshal(r)
+		if err == nil {
+			err = w.Append(record)
+		}
+	}
+	s.Range(func(k, v interface{}) bool {
+		appendRecord(&proto.LogRecord{Key: k.(string), Value: v.(*proto.StoredValue)})
+		return err == nil
+	})
+	stored := w.Size()
+	// the acceptor state follows the values, so loading the values doesn't prune it
+	for key, l := range paxos {
+		for _, in := range l.instances {
+			if err == nil {
+				appendRecord(&proto.LogRecord{Key: key, Paxos: in.state(l)})
+			}
+		}
+	}
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	if err := syncDir(dataDir); err != nil {
+		return err
+	}
+	snapshot, registers = w.Size(), stored
+	if walLog != nil {
+		walLog.Close()
+	}
+	walLog, err = crypt.CreateRecordFile(filepath.Join(dataDir, LogFile), keyring, syncLog)
+	return err
+}
+
+// syncDir flushes the directory to disk, making renames in it durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
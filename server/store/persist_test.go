@@ -0,0 +1,112 @@
+package store
+
+import (
+	"errors"
+	protobuf "google.golang.org/protobuf/proto"
+	"path/filepath"
+	"shared-registers/common/proto"
+	"shared-registers/server/crypt"
+	"testing"
+	"time"
+)
+
+func TestPersistence(t *testing.T) {
+	dir := t.TempDir()
+	line, _ := crypt.GenerateKeyLine("k1")
+	keyring, _ := crypt.ParseKeyring([]byte(line))
+
+	// a value stored by an earlier run is loaded
+	w, err := crypt.CreateRecordFile(filepath.Join(dir, LogFile), keyring, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, _ := protobuf.Marshal(&proto.KeyValue{Key: "persist/old", Value: &proto.StoredValue{Val: []byte("old")}})
+	w.Append(record)
+	w.Close()
+	if err := Open(dir, nil, false); err == nil {
+		t.Fatal("encrypted files opened without keys")
+	}
+	if err := Open(dir, keyring, false); err != nil {
+		t.Fatal(err)
+	}
+	defer Close()
+	if v, _ := Get("persist/old"); string(v.GetVal()) != "old" {
+		t.Fatalf("expected the logged value, got %v", v)
+	}
+
+	for i := uint64(1); i <= 3; i++ {
+		Set("persist/new", &proto.StoredValue{Val: []byte{byte(i)}, Ts: &proto.TimeStamp{RequestNumber: i}})
+	}
+	// the snapshot and the log hold every value in order
+	stored := map[string]*proto.StoredValue{}
+	for _, name := range []string{SnapshotFile, LogFile} {
+		err := crypt.ReadRecordFile(filepath.Join(dir, name), keyring, func(record []byte) error {
+			kv := &proto.KeyValue{}
+			if err := protobuf.Unmarshal(record, kv); err != nil {
+				return err
+			}
+			stored[kv.GetKey()] = kv.GetValue()
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if string(stored["persist/old"].GetVal()) != "old" || stored["persist/new"].GetTs().GetRequestNumber() != 3 {
+		t.Errorf("unexpected persisted values %v", stored)
+	}
+
+	// compaction moves the logged values into the snapshot
+	if _, _, logRecords := Persistence(); logRecords != 3 {
+		t.Errorf("expected 3 logged records, got %d", logRecords)
+	}
+	registers, err := Compact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, snapshotRecords, logRecords := Persistence(); d != dir || snapshotRecords < registers || registers < 2 || logRecords != 0 {
+		t.Errorf("unexpected persistence %s, %d in the snapshot, %d logged after compacting %d", d, snapshotRecords, logRecords, registers)
+	}
+
+	// the acceptor state of compare-and-swap survives restarts, from the log and from the snapshot
+	version, ballot := &proto.TimeStamp{RequestNumber: 3}, &proto.TimeStamp{RequestNumber: 1, ClientID: "p"}
+	swapped := &proto.StoredValue{Val: []byte("swapped"), Ts: &proto.TimeStamp{RequestNumber: 4}}
+	PaxosPrepare("persist/new", version, ballot)
+	PaxosAccept("persist/new", version, ballot, swapped)
+	restart := func() {
+		Close()
+		writeLock.Lock()
+		delete(paxos, "persist/new")
+		writeLock.Unlock()
+		if err := Open(dir, keyring, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	restart()
+	higher := &proto.TimeStamp{RequestNumber: 2, ClientID: "q"}
+	if rsp := PaxosPrepare("persist/new", version, higher); !rsp.GetOk() || !protobuf.Equal(rsp.GetAcceptedValue(), swapped) {
+		t.Fatalf("expected the accepted value after a restart, got %v", rsp)
+	}
+	if _, err := Compact(); err != nil {
+		t.Fatal(err)
+	}
+	restart()
+	if rsp := PaxosAccept("persist/new", version, ballot, swapped); rsp.GetOk() || !protobuf.Equal(rsp.GetPromised(), higher) {
+		t.Fatalf("expected the promise to survive compaction, got %v", rsp)
+	}
+	// pruning drops the state for good once the log is compacted
+	Set("persist/new", swapped)
+	PrunePaxos(time.Now())
+	if _, err := Compact(); err != nil {
+		t.Fatal(err)
+	}
+	restart()
+	if rsp := PaxosPrepare("persist/new", version, &proto.TimeStamp{RequestNumber: 5}); !rsp.GetStale() {
+		t.Fatalf("expected a stale instance, got %v", rsp)
+	}
+
+	Close()
+	if _, err := Compact(); !errors.Is(err, ErrNotPersisted) {
+		t.Errorf("expected ErrNotPersisted once closed, got %v", err)
+	}
+}
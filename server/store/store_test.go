@@ -1,10 +1,14 @@
 package store
 
 import (
+	"errors"
 	"math/rand"
+	"shared-registers/common"
 	"shared-registers/common/proto"
 	"strconv"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestConcurrentSet(t *testing.T) {
@@ -16,7 +20,7 @@ func TestConcurrentSet(t *testing.T) {
 			idx := rand.Intn(n / collideChance)
 			s := strconv.Itoa(idx)
 			Set(s, &proto.StoredValue{
-				Val: s,
+				Val: []byte(s),
 				Ts: &proto.TimeStamp{
 					ClientID:      "cid",
 					RequestNumber: uint64(i),
@@ -36,7 +40,7 @@ func TestSet(t *testing.T) {
 		values[idx] = s
 
 		Set(s, &proto.StoredValue{
-			Val: s,
+			Val: []byte(s),
 			Ts: &proto.TimeStamp{
 				ClientID:      "cid",
 				RequestNumber: uint64(i),
@@ -46,9 +50,298 @@ func TestSet(t *testing.T) {
 	for i, v := range values {
 		if v != "" {
 			val, err := Get(strconv.Itoa(i))
-			if err != nil || v != val.GetVal() {
+			if err != nil || v != string(val.GetVal()) {
 				t.Errorf("value not match for key %d, %s %s", i, v, val)
 			}
 		}
 	}
 }
+
+func TestSetIfNewer(t *testing.T) {
+	value := func(n uint64) *proto.StoredValue {
+		return &proto.StoredValue{Val: []byte(strconv.FormatUint(n, 10)), Ts: &proto.TimeStamp{ClientID: "cid", RequestNumber: n}}
+	}
+	if stored, err := SetIfNewer("newer", value(2), nil); !stored || err != nil {
+		t.Fatalf("expected the first value to be stored, got %v, %v", stored, err)
+	}
+	if stored, _ := SetIfNewer("newer", value(1), nil); stored {
+		t.Error("older value stored")
+	}
+	refused := errors.New("refused")
+	if stored, err := SetIfNewer("newer", value(3), func(current *proto.StoredValue, newer bool) error {
+		if current.GetTs().GetRequestNumber() != 2 || !newer {
+			t.Errorf("unexpected check of %v, newer %v", current, newer)
+		}
+		return refused
+	}); stored || err != refused {
+		t.Errorf("expected the check to refuse the value, got %v, %v", stored, err)
+	}
+	if v, _ := Get("newer"); v.GetTs().GetRequestNumber() != 2 {
+		t.Errorf("expected the value of 2 to stay, got %v", v)
+	}
+
+	// concurrent writes end with the newest value, whatever order they land in
+	var wg sync.WaitGroup
+	for i := uint64(3); i < 1000; i++ {
+		wg.Add(1)
+		go func(n uint64) {
+			defer wg.Done()
+			SetIfNewer("newer", value(n), nil)
+		}(i)
+	}
+	wg.Wait()
+	if v, _ := Get("newer"); v.GetTs().GetRequestNumber() != 999 {
+		t.Errorf("expected the newest value, got %v", v)
+	}
+}
+
+func TestRegisterClient(t *testing.T) {
+	if err := RegisterClient("c1", "token1", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	// renewals by the owner succeed, other tokens are rejected while the lease is valid
+	if err := RegisterClient("c1", "token1", time.Minute); err != nil {
+		t.Error(err)
+	}
+	if err := RegisterClient("c1", "token2", time.Minute); err != ErrClientIDInUse {
+		t.Errorf("expected ErrClientIDInUse, got %v", err)
+	}
+	// only the owner may write under the ID meanwhile, unregistered IDs are free to use
+	if CheckClientLease("c1", "token1") != nil || CheckClientLease("c1", "") != ErrClientIDInUse ||
+		CheckClientLease("c1", "token2") != ErrClientIDInUse || CheckClientLease("c2", "") != nil {
+		t.Error("wrong lease holders")
+	}
+	// after the owner releases the ID another client may take it
+	if err := RegisterClient("c1", "token1", 0); err != nil {
+		t.Error(err)
+	}
+	if err := RegisterClient("c1", "token2", time.Millisecond); err != nil {
+		t.Error(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if err := RegisterClient("c1", "token3", time.Minute); err != nil {
+		t.Errorf("expired lease should not block the registration: %v", err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	for _, key := range []string{"scan/b", "scan/a", "scan/c", "scan/a", "scan0", "\x00scan/internal"} {
+		Set(key, &proto.StoredValue{Val: []byte(key), Ts: &proto.TimeStamp{ClientID: "cid", RequestNumber: 1}})
+	}
+	entries, more := Scan("scan/", "scan0", 2, nil)
+	if len(entries) != 2 || !more || entries[0].GetKey() != "scan/a" || entries[1].GetKey() != "scan/b" {
+		t.Fatalf("unexpected first page %v %v", entries, more)
+	}
+	entries, more = Scan("scan/b\x00", "scan0", 2, nil)
+	if len(entries) != 1 || more || entries[0].GetKey() != "scan/c" {
+		t.Fatalf("unexpected second page %v %v", entries, more)
+	}
+	// keys hidden from the caller, e.g. by an ACL, don't take up the page, even a whole page of them
+	hidden := func(key string) bool {
+		return key != "scan/a" && key != "scan/b"
+	}
+	entries, more = Scan("scan/", "scan0", 2, hidden)
+	if len(entries) != 1 || more || entries[0].GetKey() != "scan/c" {
+		t.Fatalf("unexpected filtered page %v %v", entries, more)
+	}
+	entries, _ = Scan("", "scan/", 0, nil)
+	for _, e := range entries {
+		if e.GetKey() == "\x00scan/internal" {
+			t.Fatal("internal key listed")
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	// expired by then, but not by the time TestSweep sweeps
+	later := time.Now().Add(time.Hour)
+	for _, key := range []string{"keys/a", "keys/b", "\x00keys/internal"} {
+		Set(key, &proto.StoredValue{Val: []byte(key), Ts: &proto.TimeStamp{ClientID: "cid", RequestNumber: 1}})
+	}
+	Set("keys/c", &proto.StoredValue{Deleted: true, Ts: &proto.TimeStamp{ClientID: "cid", RequestNumber: 1}})
+	Set("keys/d", &proto.StoredValue{Val: []byte("d"), ExpiresAt: uint64(later.Add(-time.Second).UnixNano()),
+		Ts: &proto.TimeStamp{ClientID: "cid", RequestNumber: 1}})
+
+	keys, more := Keys("keys/", "", 2, false)
+	if len(keys) != 2 || !more || keys[0] != "keys/a" || keys[1] != "keys/b" {
+		t.Fatalf("unexpected first page %v %v", keys, more)
+	}
+	keys, more = Keys("keys/", "keys/b", 2, false)
+	if len(keys) != 2 || more || keys[0] != "keys/c" || keys[1] != "keys/d" {
+		t.Fatalf("unexpected second page %v %v", keys, more)
+	}
+	if keys, _ = Keys("\x00keys/", "", 0, false); len(keys) != 0 {
+		t.Fatalf("internal keys listed %v", keys)
+	}
+	if keys, _ = Keys("\x00keys/", "", 0, true); len(keys) != 1 {
+		t.Fatalf("expected the internal key, got %v", keys)
+	}
+
+	if keys, deleted, internal := Count("keys/", later); keys != 4 || deleted != 2 || internal != 0 {
+		t.Errorf("unexpected count %d, %d deleted, %d internal", keys, deleted, internal)
+	}
+	if keys, deleted, internal := Count("\x00keys/", later); keys != 1 || deleted != 0 || internal != 1 {
+		t.Errorf("unexpected internal count %d, %d deleted, %d internal", keys, deleted, internal)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	updates, dropped, cancel := Subscribe("watch/", true)
+	defer cancel()
+	Set("watch/a", &proto.StoredValue{Val: []byte("a"), Ts: &proto.TimeStamp{ClientID: "cid", RequestNumber: 1}})
+	Set("other", &proto.StoredValue{Val: []byte("o"), Ts: &proto.TimeStamp{ClientID: "cid", RequestNumber: 1}})
+	Set("watch/b", &proto.StoredValue{Val: []byte("b"), Ts: &proto.TimeStamp{ClientID: "cid", RequestNumber: 1}})
+	for _, want := range []string{"watch/a", "watch/b"} {
+		if kv := <-updates; kv.GetKey() != want {
+			t.Fatalf("expected update of %s, got %s", want, kv.GetKey())
+		}
+	}
+
+	// a watcher that doesn't read loses its subscription
+	for i := 0; i <= watchBuffer; i++ {
+		Set("watch/a", &proto.StoredValue{Val: []byte("a"), Ts: &proto.TimeStamp{ClientID: "cid", RequestNumber: uint64(i + 2)}})
+	}
+	select {
+	case <-dropped:
+	default:
+		t.Fatal("expected the subscription to be dropped")
+	}
+}
+
+func TestSweep(t *testing.T) {
+	now := time.Now()
+	ts := &proto.TimeStamp{ClientID: "cid", RequestNumber: 1}
+	Set("ttl/expired", &proto.StoredValue{Val: []byte("v"), Ts: ts, ExpiresAt: uint64(now.UnixNano())})
+	Set("ttl/live", &proto.StoredValue{Val: []byte("v"), Ts: ts, ExpiresAt: uint64(now.Add(time.Hour).UnixNano())})
+	if swept := Sweep(now, func(string, *proto.StoredValue) error { return errors.New("audit failed") }); swept != 0 {
+		t.Fatalf("expected the value to stay when the sweep can't be audited, got %d swept", swept)
+	}
+	var audited []string
+	if swept := Sweep(now, func(key string, tombstone *proto.StoredValue) error {
+		audited = append(audited, key)
+		return nil
+	}); swept != 1 || len(audited) != 1 || audited[0] != "ttl/expired" {
+		t.Fatalf("expected 1 swept and audited value, got %d swept, %v audited", swept, audited)
+	}
+	if v, _ := Get("ttl/expired"); !v.GetDeleted() || v.GetVal() != nil || v.GetTs().GetRequestNumber() != 1 {
+		t.Errorf("expected a tombstone keeping the timestamp, got %v", v)
+	}
+	if v, _ := Get("ttl/live"); string(v.GetVal()) != "v" {
+		t.Errorf("live value was swept: %v", v)
+	}
+
+	// a write that replaced the expired value before the sweeper got to it isn't audited as expired
+	expired := &proto.StoredValue{Val: []byte("v"), Ts: ts, ExpiresAt: uint64(now.UnixNano())}
+	Set("ttl/rewritten", expired)
+	Set("ttl/rewritten", &proto.StoredValue{Val: []byte("w"), Ts: &proto.TimeStamp{ClientID: "cid", RequestNumber: 2}})
+	if expire("ttl/rewritten", expired, common.Visible(expired, now), func(string, *proto.StoredValue) error {
+		t.Error("expiry of a replaced value audited")
+		return nil
+	}) {
+		t.Error("replaced value expired")
+	}
+}
+
+func TestPaxosAcceptor(t *testing.T) {
+	v1 := &proto.TimeStamp{RequestNumber: 1, ClientID: "a"}
+	low, high := &proto.TimeStamp{RequestNumber: 1, ClientID: "p"}, &proto.TimeStamp{RequestNumber: 2, ClientID: "q"}
+	value := &proto.StoredValue{Val: []byte("new"), Ts: &proto.TimeStamp{RequestNumber: 2, ClientID: "p"}}
+	Set("paxos", &proto.StoredValue{Val: []byte("old"), Ts: v1})
+
+	if rsp := PaxosPrepare("paxos", v1, low); !rsp.GetOk() || rsp.GetAcceptedValue() != nil {
+		t.Fatalf("expected a promise, got %v", rsp)
+	}
+	if rsp := PaxosAccept("paxos", v1, low, value); !rsp.GetOk() {
+		t.Fatalf("expected the value to be accepted, got %v", rsp)
+	}
+	// a higher ballot learns the accepted value, the lower one can't be accepted anymore
+	if rsp := PaxosPrepare("paxos", v1, high); !rsp.GetOk() || rsp.GetAcceptedValue() != value {
+		t.Fatalf("expected the accepted value, got %v", rsp)
+	}
+	if rsp := PaxosAccept("paxos", v1, low, value); rsp.GetOk() || rsp.GetPromised() != high {
+		t.Fatalf("expected a rejection, got %v", rsp)
+	}
+	if rsp := PaxosPrepare("paxos", v1, low); rsp.GetOk() {
+		t.Fatalf("expected a rejection, got %v", rsp)
+	}
+
+	// a newer stored value than the accepted one keeps the instance, its proposer may still apply it
+	Set("paxos", &proto.StoredValue{Val: []byte("older"), Ts: &proto.TimeStamp{RequestNumber: 1, ClientID: "b"}})
+	if rsp := PaxosPrepare("paxos", v1, &proto.TimeStamp{RequestNumber: 3}); !rsp.GetOk() || rsp.GetAcceptedValue() != value {
+		t.Fatalf("expected the accepted value, got %v", rsp)
+	}
+	// the instance outlives the chosen value being stored, so preempted proposers learn the outcome
+	Set("paxos", value)
+	if rsp := PaxosPrepare("paxos", v1, &proto.TimeStamp{RequestNumber: 4}); !rsp.GetOk() || rsp.GetAcceptedValue() != value {
+		t.Fatalf("expected the accepted value, got %v", rsp)
+	}
+	// until it is pruned after its last use, then proposers learn the outcome by reading
+	if PrunePaxos(time.Now().Add(-time.Hour)) != 0 {
+		t.Error("pruned an instance in use")
+	}
+	PrunePaxos(time.Now())
+	writeLock.Lock()
+	_, kept := paxos["paxos"]
+	writeLock.Unlock()
+	if kept {
+		t.Error("the acceptor state outlived the stored value")
+	}
+	if rsp := PaxosPrepare("paxos", v1, &proto.TimeStamp{RequestNumber: 5}); !rsp.GetStale() {
+		t.Fatalf("expected a stale instance, got %v", rsp)
+	}
+	// a replica that never took part in an instance doesn't start one for an outdated version
+	Set("paxos/late", value)
+	if rsp := PaxosPrepare("paxos/late", v1, low); !rsp.GetStale() {
+		t.Fatalf("expected a stale instance, got %v", rsp)
+	}
+	// only the latest paxosHistory instances are kept
+	v2 := value.GetTs()
+	PaxosPrepare("paxos", v2, low)
+	PaxosAccept("paxos", v2, low, value)
+	for i := uint64(3); i < 3+paxosHistory; i++ {
+		PaxosPrepare("paxos", &proto.TimeStamp{RequestNumber: i, ClientID: "p"}, low)
+	}
+	if rsp := PaxosAccept("paxos", v2, &proto.TimeStamp{RequestNumber: 5}, value); !rsp.GetStale() {
+		t.Fatalf("expected a dropped instance, got %v", rsp)
+	}
+}
+
+func TestCodedQuery(t *testing.T) {
+	ts1, ts2 := &proto.TimeStamp{RequestNumber: 1, ClientID: "a"}, &proto.TimeStamp{RequestNumber: 2, ClientID: "a"}
+	meta := &proto.StoredValue{Manifest: &proto.ChunkManifest{WriteID: "w"}}
+	CodedPreWrite("coded/query", ts1, &proto.CodedFragment{Meta: meta})
+	if ts, m := CodedQuery("coded/query"); ts != nil || m != nil {
+		t.Fatalf("pre-written timestamp was returned: %v %v", ts, m)
+	}
+	// writers learn what they replace from the meta of the fragment, without reading the value
+	CodedFinalize("coded/query", ts1)
+	if ts, m := CodedQuery("coded/query"); ts != ts1 || m != meta {
+		t.Fatalf("expected the finalized timestamp and its meta, got %v %v", ts, m)
+	}
+	// a replica that learned about a timestamp before its fragment doesn't know the meta
+	CodedFinalize("coded/query", ts2)
+	if ts, m := CodedQuery("coded/query"); ts != ts2 || m != nil {
+		t.Fatalf("expected the finalized timestamp without meta, got %v %v", ts, m)
+	}
+}
+
+func TestCodedState(t *testing.T) {
+	ts := func(n uint64) *proto.TimeStamp { return &proto.TimeStamp{RequestNumber: n, ClientID: "a"} }
+	if stale, finalized := CodedState("coded/state", ts(1)); stale || finalized {
+		t.Fatalf("unknown key: expected neither stale nor finalized, got %v %v", stale, finalized)
+	}
+	CodedPreWrite("coded/state", ts(1), &proto.CodedFragment{})
+	if stale, finalized := CodedState("coded/state", ts(1)); stale || finalized {
+		t.Fatalf("pre-written: expected neither stale nor finalized, got %v %v", stale, finalized)
+	}
+	for n := uint64(1); n <= codedHistory+1; n++ {
+		CodedFinalize("coded/state", ts(n))
+	}
+	if stale, finalized := CodedState("coded/state", ts(codedHistory+1)); stale || !finalized {
+		t.Errorf("expected the latest timestamp to be finalized, got %v %v", stale, finalized)
+	}
+	// gc dropped the oldest timestamp
+	if stale, _ := CodedState("coded/state", ts(1)); !stale {
+		t.Error("expected the collected timestamp to be stale")
+	}
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"time"
+)
+
+// Sweep
+// replace the expired values with tombstones that only keep the timestamp, so their memory is
+// reclaimed while late writes with older timestamps still lose against them. If audit isn't nil, it
+// is called with every tombstone right before it is stored, and the value is left alone if it fails.
+// Returns the number of values swept.
+func Sweep(now time.Time, audit func(key string, tombstone *proto.StoredValue) error) int {
+	swept := 0
+	s.Range(func(k, v interface{}) bool {
+		value := v.(*proto.StoredValue)
+		if value.GetDeleted() || !common.Expired(value, now) {
+			return true
+		}
+		if expire(k.(string), value, common.Visible(value, now), audit) {
+			swept++
+		}
+		return true
+	})
+	return swept
+}
+
+// expire
+// replace the value of the key with its tombstone unless a newer write replaced it meanwhile, the
+// expiry is audited under the lock so the log only holds the expiries that happen
+func expire(key string, value, tombstone *proto.StoredValue, audit func(key string, tombstone *proto.StoredValue) error) bool {
+	writeLock.Lock()
+	defer writeLock.Unlock()
+	if v, ok := s.Load(key); !ok || v.(*proto.StoredValue) != value {
+		return false
+	}
+	if audit != nil && audit(key, tombstone) != nil {
+		return false
+	}
+	set(key, tombstone)
+	return true
+}
+
+// StartSweeper runs Sweep with audit and PrunePaxos every interval in the background
+func StartSweeper(interval time.Duration, audit func(key string, tombstone *proto.StoredValue) error) {
+	go func() {
+		for now := range time.Tick(interval) {
+			Sweep(now, audit)
+			PrunePaxos(now.Add(-PaxosRetention))
+		}
+	}()
+}
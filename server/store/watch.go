@@ -0,0 +1,67 @@
+package store
+
+import (
+	"shared-registers/common"
+	"shared-registers/common/proto"
+	"strings"
+	"sync"
+)
+
+// watchBuffer is how many updates a watcher may fall behind before its subscription is dropped
+const watchBuffer = 1024
+
+type subscription struct {
+	key     string
+	prefix  bool
+	updates chan *proto.KeyValue
+	dropped chan struct{} // closed once the watcher fell behind
+	once    sync.Once
+}
+
+var (
+	subsLock sync.RWMutex
+	subs     = make(map[*subscription]struct{})
+)
+
+// Subscribe
+// receive every value Set stores for the key, or for the keys starting with it if prefix is set,
+// internal keys only match an exact subscription. A watcher that doesn't keep up loses its
+// subscription, which closes dropped. cancel ends the subscription.
+func Subscribe(key string, prefix bool) (updates <-chan *proto.KeyValue, dropped <-chan struct{}, cancel func()) {
+	sub := &subscription{
+		key:     key,
+		prefix:  prefix,
+		updates: make(chan *proto.KeyValue, watchBuffer),
+		dropped: make(chan struct{}),
+	}
+	subsLock.Lock()
+	subs[sub] = struct{}{}
+	subsLock.Unlock()
+	return sub.updates, sub.dropped, func() {
+		subsLock.Lock()
+		delete(subs, sub)
+		subsLock.Unlock()
+	}
+}
+
+func (sub *subscription) matches(key string) bool {
+	if !sub.prefix {
+		return key == sub.key
+	}
+	return strings.HasPrefix(key, sub.key) && !common.IsInternalKey(key)
+}
+
+func notify(key string, value *proto.StoredValue) {
+	subsLock.RLock()
+	defer subsLock.RUnlock()
+	for sub := range subs {
+		if !sub.matches(key) {
+			continue
+		}
+		select {
+		case sub.updates <- &proto.KeyValue{Key: key, Value: value}:
+		default:
+			sub.once.Do(func() { close(sub.dropped) })
+		}
+	}
+}